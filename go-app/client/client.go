@@ -0,0 +1,287 @@
+// Package client is a typed HTTP client for the users API. Requests carry the
+// caller's trace context in W3C traceparent headers, and error responses are
+// returned as DomainErrors.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/propagation"
+
+	"go-app/internal/application/dto"
+	domainErrors "go-app/internal/domain/errors"
+)
+
+// defaultTimeout bounds a whole request, including reading the response body
+const defaultTimeout = 10 * time.Second
+
+// maxErrorBodySize caps how much of an error response body is read
+const maxErrorBodySize = 64 << 10
+
+// UserClient calls the users API
+type UserClient struct {
+	baseURL    string
+	httpClient *http.Client
+	token      string
+}
+
+// NewUserClient creates a client for the API at baseURL, e.g.
+// "http://go-app:8080". The transport creates a client span per request and
+// injects its W3C trace context, using the tracer provider set globally.
+func NewUserClient(baseURL string) *UserClient {
+	return &UserClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{
+			Timeout: defaultTimeout,
+			Transport: otelhttp.NewTransport(http.DefaultTransport,
+				otelhttp.WithPropagators(propagation.TraceContext{}),
+			),
+		},
+	}
+}
+
+// WithTimeout sets the timeout of each request; zero means no timeout
+func (c *UserClient) WithTimeout(timeout time.Duration) *UserClient {
+	c.httpClient.Timeout = timeout
+	return c
+}
+
+// WithBearerToken sends token, an API key or JWT, in the Authorization header
+func (c *UserClient) WithBearerToken(token string) *UserClient {
+	c.token = token
+	return c
+}
+
+// userEnvelope is the dto.SuccessResponse the API wraps created and updated
+// users in
+type userEnvelope struct {
+	Data dto.UserResponse `json:"data"`
+}
+
+// CreateUser creates a user
+func (c *UserClient) CreateUser(ctx context.Context, req dto.CreateUserRequest) (*dto.UserResponse, error) {
+	var env userEnvelope
+	if _, err := c.do(ctx, http.MethodPost, "/users", nil, nil, req, &env); err != nil {
+		return nil, err
+	}
+	return &env.Data, nil
+}
+
+// CreateUsers creates several users in one request. mode is
+// dto.BulkModeBestEffort or dto.BulkModeAllOrNothing; empty uses the server default.
+func (c *UserClient) CreateUsers(ctx context.Context, reqs []dto.CreateUserRequest, mode string) (*dto.BulkCreateUsersResponse, error) {
+	query := url.Values{}
+	if mode != "" {
+		query.Set("mode", mode)
+	}
+	var result dto.BulkCreateUsersResponse
+	if _, err := c.do(ctx, http.MethodPost, "/users/bulk", query, nil, reqs, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetUser returns the user with the given ID, with its ETag set
+func (c *UserClient) GetUser(ctx context.Context, id string) (*dto.UserResponse, error) {
+	var user dto.UserResponse
+	header, err := c.do(ctx, http.MethodGet, "/users/"+url.PathEscape(id), nil, nil, nil, &user)
+	if err != nil {
+		return nil, err
+	}
+	user.ETag = header.Get("ETag")
+	return &user, nil
+}
+
+// ListUsers returns a page of users
+func (c *UserClient) ListUsers(ctx context.Context, req dto.ListUsersRequest) (*dto.ListUsersResponse, error) {
+	var page dto.ListUsersResponse
+	if _, err := c.do(ctx, http.MethodGet, "/users", pagination(req.Limit, req.Offset), nil, nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// SearchUsers returns a page of users matching req.Query
+func (c *UserClient) SearchUsers(ctx context.Context, req dto.SearchUsersRequest) (*dto.ListUsersResponse, error) {
+	query := pagination(req.Limit, req.Offset)
+	query.Set("q", req.Query)
+	if req.Field != "" {
+		query.Set("field", req.Field)
+	}
+	var page dto.ListUsersResponse
+	if _, err := c.do(ctx, http.MethodGet, "/users/search", query, nil, nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// GetUserEvents returns the user's latest activity, newest first
+func (c *UserClient) GetUserEvents(ctx context.Context, id string, req dto.ListUserEventsRequest) (*dto.UserEventsResponse, error) {
+	var events dto.UserEventsResponse
+	if _, err := c.do(ctx, http.MethodGet, "/users/"+url.PathEscape(id)+"/events", pagination(req.Limit, 0), nil, nil, &events); err != nil {
+		return nil, err
+	}
+	return &events, nil
+}
+
+// UpdateUser replaces a user. When req.IfMatch is set, the update only
+// applies to the version with that ETag.
+func (c *UserClient) UpdateUser(ctx context.Context, id string, req dto.UpdateUserRequest) (*dto.UserResponse, error) {
+	return c.writeUser(ctx, http.MethodPut, id, req.IfMatch, req)
+}
+
+// PatchUser updates the fields set in req. When req.IfMatch is set, the patch
+// only applies to the version with that ETag.
+func (c *UserClient) PatchUser(ctx context.Context, id string, req dto.PatchUserRequest) (*dto.UserResponse, error) {
+	return c.writeUser(ctx, http.MethodPatch, id, req.IfMatch, req)
+}
+
+// DeleteUser deletes the user with the given ID
+func (c *UserClient) DeleteUser(ctx context.Context, id string) error {
+	_, err := c.do(ctx, http.MethodDelete, "/users/"+url.PathEscape(id), nil, nil, nil, nil)
+	return err
+}
+
+// DeleteUsers deletes every user matching req and returns how many were
+// deleted. The request carries the confirmation header the API requires.
+func (c *UserClient) DeleteUsers(ctx context.Context, req dto.DeleteUsersRequest) (*dto.DeleteUsersResponse, error) {
+	query := url.Values{}
+	for _, id := range req.IDs {
+		query.Add("ids", id)
+	}
+	if req.EmailDomain != "" {
+		query.Set("email_domain", req.EmailDomain)
+	}
+	header := http.Header{}
+	header.Set("X-Confirm-Delete", "true")
+	var result dto.DeleteUsersResponse
+	if _, err := c.do(ctx, http.MethodDelete, "/users", query, header, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// writeUser sends a PUT or PATCH for a user, conditional on ifMatch when set
+func (c *UserClient) writeUser(ctx context.Context, method, id, ifMatch string, body interface{}) (*dto.UserResponse, error) {
+	header := http.Header{}
+	if ifMatch != "" {
+		header.Set("If-Match", ifMatch)
+	}
+	var env userEnvelope
+	respHeader, err := c.do(ctx, method, "/users/"+url.PathEscape(id), nil, header, body, &env)
+	if err != nil {
+		return nil, err
+	}
+	env.Data.ETag = respHeader.Get("ETag")
+	return &env.Data, nil
+}
+
+// do sends a request with the given extra headers and an optional JSON body,
+// and decodes a 2xx JSON response into out, returning the response headers.
+// Other statuses are returned as DomainErrors.
+func (c *UserClient) do(ctx context.Context, method, path string, query url.Values, header http.Header, body, out interface{}) (http.Header, error) {
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("client: encode %s %s request: %w", method, path, err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return nil, fmt.Errorf("client: build %s %s request: %w", method, path, err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, domainErrors.NewDomainErrorWithCause(domainErrors.ErrCodeServiceError,
+			fmt.Sprintf("%s %s failed", method, path), err).WithRetryable(true)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.Header, responseError(resp)
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return resp.Header, fmt.Errorf("client: decode %s %s response: %w", method, path, err)
+		}
+	}
+	return resp.Header, nil
+}
+
+// responseError converts a non-2xx response into a DomainError. The API
+// writes either a DomainError or an ErrorResponse; both carry code, message
+// and context. Bodies without a code, such as plain-text 429s, become
+// SERVICE_ERRORs. The HTTP status is added to the context either way.
+func responseError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+
+	var parsed dto.ErrorResponse
+	if err := json.Unmarshal(body, &parsed); err != nil || parsed.Code == "" {
+		message := strings.TrimSpace(string(body))
+		if message == "" {
+			message = http.StatusText(resp.StatusCode)
+		}
+		return domainErrors.NewDomainError(domainErrors.ErrCodeServiceError, message).
+			WithContext("http.status", resp.StatusCode).
+			WithRetryable(retryableStatus(resp.StatusCode))
+	}
+
+	code := domainErrors.ErrorCode(parsed.Code)
+	domainErr := domainErrors.NewDomainError(code, parsed.Message)
+	for key, value := range parsed.Context {
+		domainErr = domainErr.WithContext(key, value)
+	}
+	domainErr = domainErr.WithContext("http.status", resp.StatusCode)
+	if !code.IsKnown() {
+		// Codes outside the domain set, such as UNAUTHORIZED, carry no
+		// retry policy of their own
+		domainErr = domainErr.WithRetryable(retryableStatus(resp.StatusCode))
+	}
+	return domainErr
+}
+
+// retryableStatus reports whether a request that got status may succeed if repeated
+func retryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
+
+// pagination builds the limit and offset query parameters, omitting unset values
+func pagination(limit, offset int) url.Values {
+	query := url.Values{}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	if offset > 0 {
+		query.Set("offset", strconv.Itoa(offset))
+	}
+	return query
+}
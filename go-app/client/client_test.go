@@ -0,0 +1,69 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"go-app/internal/application/dto"
+	"go-app/internal/application/service"
+	"go-app/internal/infrastructure/repository/memory"
+	"go-app/internal/infrastructure/telemetry"
+	"go-app/internal/interface/http/handler"
+)
+
+// newTestClient starts the users handler over an in-memory repository and
+// returns a client for it
+func newTestClient(t *testing.T) *UserClient {
+	t.Helper()
+	svc := service.NewUserService(memory.NewUserRepository(), &telemetry.Telemetry{Tracer: noop.NewTracerProvider().Tracer("test")})
+	users := handler.NewUsersHandler(svc)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/users", users.Handle)
+	mux.HandleFunc("/users/{id}", users.Handle)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return NewUserClient(server.URL)
+}
+
+func TestWriteUserDecodesEnvelope(t *testing.T) {
+	c := newTestClient(t)
+	ctx := context.Background()
+
+	created, err := c.CreateUser(ctx, dto.CreateUserRequest{Name: "Jane Doe", Email: "jane@example.com"})
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	if created.ID == 0 || created.Email != "jane@example.com" {
+		t.Fatalf("CreateUser() = %+v, want an ID and email jane@example.com", created)
+	}
+	id := strconv.Itoa(created.ID)
+
+	updated, err := c.UpdateUser(ctx, id, dto.UpdateUserRequest{Name: "Jane Doe", Email: "jane.doe@example.com"})
+	if err != nil {
+		t.Fatalf("UpdateUser() error = %v", err)
+	}
+	if updated.ID != created.ID || updated.Email != "jane.doe@example.com" {
+		t.Errorf("UpdateUser() = %+v, want ID %d and email jane.doe@example.com", updated, created.ID)
+	}
+	if updated.ETag == "" {
+		t.Error("UpdateUser() ETag is empty")
+	}
+
+	email := "jane.smith@example.com"
+	patched, err := c.PatchUser(ctx, id, dto.PatchUserRequest{Email: &email, IfMatch: updated.ETag})
+	if err != nil {
+		t.Fatalf("PatchUser() error = %v", err)
+	}
+	if patched.ID != created.ID || patched.Email != email {
+		t.Errorf("PatchUser() = %+v, want ID %d and email %s", patched, created.ID, email)
+	}
+	if patched.ETag == "" || patched.ETag == updated.ETag {
+		t.Errorf("PatchUser() ETag = %q, want a new one", patched.ETag)
+	}
+}
@@ -1,43 +1,154 @@
 package dto
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"go-app/internal/domain/entity"
+	domainErrors "go-app/internal/domain/errors"
+	"go-app/internal/domain/repository"
 )
 
 // CreateUserRequest represents the request to create a user
 type CreateUserRequest struct {
-	Name  string `json:"name"`
-	Email string `json:"email"`
+	Name  string `json:"name" validate:"required,min=2,max=100"`
+	Email string `json:"email" validate:"required,max=100,email"`
+	Phone string `json:"phone,omitempty" validate:"omitempty,e164"`
+	// Role defaults to "user" when omitted
+	Role string `json:"role,omitempty" validate:"omitempty,oneof=admin user"`
 }
 
 // Validate validates the CreateUserRequest
 func (r *CreateUserRequest) Validate() error {
-	return validateUserPayload(r.Name, r.Email)
+	verr := &ValidationError{}
+	verr.validateStruct(r)
+	return verr.errOrNil()
+}
+
+// MaxBulkCreateUsers caps the number of users accepted by one bulk request
+const MaxBulkCreateUsers = 100
+
+// Bulk create modes
+const (
+	// BulkModeBestEffort creates every valid user and reports failures per item
+	BulkModeBestEffort = "best_effort"
+	// BulkModeAllOrNothing creates no users unless every item succeeds
+	BulkModeAllOrNothing = "all_or_nothing"
+)
+
+// BulkCreateUserResult reports the outcome of one item of a bulk create
+type BulkCreateUserResult struct {
+	Index int            `json:"index"`
+	User  *UserResponse  `json:"user,omitempty"`
+	Error *ErrorResponse `json:"error,omitempty"`
+}
+
+// BulkCreateUsersResponse represents the response of a bulk create
+type BulkCreateUsersResponse struct {
+	Results []BulkCreateUserResult `json:"results"`
+	Created int                    `json:"created"`
+	Failed  int                    `json:"failed"`
 }
 
 // UpdateUserRequest represents the request to update a user
 type UpdateUserRequest struct {
-	Name  string `json:"name"`
-	Email string `json:"email"`
+	Name  string `json:"name" validate:"required,min=2,max=100"`
+	Email string `json:"email" validate:"required,max=100,email"`
+	// Phone replaces the stored phone; omitting it removes the phone
+	Phone string `json:"phone,omitempty" validate:"omitempty,e164"`
+	// Role replaces the stored role; omitting it keeps the current role
+	Role string `json:"role,omitempty" validate:"omitempty,oneof=admin user"`
+	// IfMatch is the If-Match header; when set, the update only applies to
+	// the user version with that ETag
+	IfMatch string `json:"-"`
 }
 
 // Validate validates the UpdateUserRequest
 func (r *UpdateUserRequest) Validate() error {
-	return validateUserPayload(r.Name, r.Email)
+	verr := &ValidationError{}
+	verr.validateStruct(r)
+	return verr.errOrNil()
+}
+
+// PatchUserRequest represents the request to partially update a user.
+// Nil fields keep their current value; an empty phone removes it.
+type PatchUserRequest struct {
+	Name  *string `json:"name" validate:"omitempty,min=2,max=100"`
+	Email *string `json:"email" validate:"omitempty,max=100,email"`
+	Phone *string `json:"phone"`
+	Role  *string `json:"role" validate:"omitempty,oneof=admin user"`
+	// IfMatch is the If-Match header; when set, the patch only applies to
+	// the user version with that ETag
+	IfMatch string `json:"-"`
+}
+
+// Validate validates the PatchUserRequest
+func (r *PatchUserRequest) Validate() error {
+	verr := &ValidationError{}
+	if r.Name == nil && r.Email == nil && r.Phone == nil && r.Role == nil {
+		const message = "at least one of name, email, phone or role is required"
+		verr.add("name", RuleRequired, message)
+		verr.add("email", RuleRequired, message)
+		verr.add("phone", RuleRequired, message)
+		verr.add("role", RuleRequired, message)
+	}
+	// An empty phone clears it, so only validate the format when one is given
+	if r.Phone != nil && *r.Phone != "" {
+		if err := validate.Var(*r.Phone, "e164"); err != nil {
+			verr.add("phone", RulePhone, "phone must be in E.164 format, e.g. +14155552671")
+		}
+	}
+	verr.validateStruct(r)
+	return verr.errOrNil()
+}
+
+// MaxBulkDeleteIDs caps the number of IDs accepted by one bulk delete
+const MaxBulkDeleteIDs = 1000
+
+// DeleteUsersRequest selects the users removed by a bulk delete. At least one
+// filter is required, and the filters that are set must all match.
+type DeleteUsersRequest struct {
+	IDs         []string `json:"ids"`
+	EmailDomain string   `json:"email_domain" validate:"omitempty,fqdn"`
 }
 
-// validateUserPayload provides shared validation for user requests
-func validateUserPayload(name, email string) error {
-	if strings.TrimSpace(name) == "" {
-		return errors.New("name is required")
+// Validate validates the DeleteUsersRequest
+func (r *DeleteUsersRequest) Validate() error {
+	verr := &ValidationError{}
+	verr.validateStruct(r)
+	if len(r.IDs) == 0 && r.EmailDomain == "" {
+		verr.add("filter", RuleRequired, "at least one of ids or email_domain is required")
 	}
-	if strings.TrimSpace(email) == "" {
-		return errors.New("email is required")
+	if len(r.IDs) > MaxBulkDeleteIDs {
+		verr.add("ids", RuleMax, fmt.Sprintf("ids cannot contain more than %d entries", MaxBulkDeleteIDs))
 	}
-	return nil
+	for _, id := range r.IDs {
+		if n, err := strconv.Atoi(id); err != nil || n <= 0 {
+			verr.add("ids", RuleMin, fmt.Sprintf("ids must be positive integers, got %q", id))
+			break
+		}
+	}
+	return verr.errOrNil()
+}
+
+// Filter converts a validated request into a repository filter
+func (r *DeleteUsersRequest) Filter() repository.UserFilter {
+	filter := repository.UserFilter{EmailDomain: r.EmailDomain}
+	for _, id := range r.IDs {
+		n, _ := strconv.Atoi(id)
+		filter.IDs = append(filter.IDs, entity.UserID(n))
+	}
+	return filter
+}
+
+// DeleteUsersResponse reports how many users a bulk delete removed
+type DeleteUsersResponse struct {
+	Deleted int `json:"deleted"`
 }
 
 // ListUsersRequest represents the request to list users with pagination
@@ -48,34 +159,82 @@ type ListUsersRequest struct {
 
 // Validate validates the ListUsersRequest
 func (r *ListUsersRequest) Validate() error {
-	if r.Limit <= 0 {
-		r.Limit = 10 // Default limit
+	verr := &ValidationError{}
+	verr.validatePagination(&r.Limit, r.Offset)
+	return verr.errOrNil()
+}
+
+// SearchUsersRequest represents the request to search users by name or email
+type SearchUsersRequest struct {
+	Query  string `json:"q"`
+	Field  string `json:"field"`
+	Limit  int    `json:"limit"`
+	Offset int    `json:"offset"`
+}
+
+// Validate validates the SearchUsersRequest
+func (r *SearchUsersRequest) Validate() error {
+	verr := &ValidationError{}
+	if strings.TrimSpace(r.Query) == "" {
+		verr.add("q", RuleRequired, "q is required")
 	}
-	if r.Limit > 100 {
-		return errors.New("limit cannot exceed 100")
+	if r.Field == "" {
+		r.Field = repository.SearchFieldName // Default field
 	}
-	if r.Offset < 0 {
-		return errors.New("offset cannot be negative")
+	if r.Field != repository.SearchFieldName && r.Field != repository.SearchFieldEmail {
+		verr.add("field", RuleOneOf, "field must be one of: name, email")
 	}
-	return nil
+	verr.validatePagination(&r.Limit, r.Offset)
+	return verr.errOrNil()
 }
 
 // UserResponse represents the response when returning user data
 type UserResponse struct {
-	ID    int    `json:"id"`
-	Name  string `json:"name"`
-	Email string `json:"email"`
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	Email     string `json:"email"`
+	Phone     string `json:"phone,omitempty"`
+	Role      string `json:"role"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+	// ETag identifies this version of the user for conditional requests
+	ETag string `json:"-"`
 }
 
 // NewUserResponse creates a UserResponse from a domain entity
 func NewUserResponse(user *entity.User) *UserResponse {
 	return &UserResponse{
-		ID:    int(user.ID()),
-		Name:  user.Name().String(),
-		Email: user.Email().String(),
+		ID:        int(user.ID()),
+		Name:      user.Name().String(),
+		Email:     user.Email().String(),
+		Phone:     user.Phone().String(),
+		Role:      user.Role().String(),
+		CreatedAt: user.CreatedAt().UTC().Format(time.RFC3339),
+		UpdatedAt: user.UpdatedAt().UTC().Format(time.RFC3339),
+		ETag:      UserETag(user),
 	}
 }
 
+// UserETag returns a strong ETag derived from the user's ID, fields and
+// last update time
+func UserETag(user *entity.User) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d|%s|%s|%s|%s|%d",
+		user.ID(), user.Name(), user.Email(), user.Phone(), user.Role(), user.UpdatedAt().UnixNano())))
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// ETagMatches reports whether an If-Match or If-None-Match header value
+// matches etag. The header may list several tags or be "*".
+func ETagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
 // ListUsersResponse represents the response when listing users
 type ListUsersResponse struct {
 	Users      []*UserResponse `json:"users"`
@@ -110,6 +269,49 @@ func NewListUsersResponse(users []*entity.User, total, limit, offset int) *ListU
 	}
 }
 
+// ListUserEventsRequest represents the request for a user's latest activity
+type ListUserEventsRequest struct {
+	Limit int `json:"limit"`
+}
+
+// Validate validates the ListUserEventsRequest
+func (r *ListUserEventsRequest) Validate() error {
+	verr := &ValidationError{}
+	verr.validatePagination(&r.Limit, 0)
+	return verr.errOrNil()
+}
+
+// UserEventResponse is one entry of a user's activity history
+type UserEventResponse struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	TraceID    string    `json:"trace_id,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// UserEventsResponse lists a user's latest activity, newest first
+type UserEventsResponse struct {
+	UserID int                  `json:"user_id"`
+	Events []*UserEventResponse `json:"events"`
+}
+
+// NewUserEventsResponse creates a UserEventsResponse from activity entries
+func NewUserEventsResponse(userID entity.UserID, activities []*repository.UserActivity) *UserEventsResponse {
+	events := make([]*UserEventResponse, len(activities))
+	for i, activity := range activities {
+		events[i] = &UserEventResponse{
+			ID:         activity.ID,
+			Type:       activity.Type,
+			TraceID:    activity.TraceID,
+			OccurredAt: activity.OccurredAt,
+		}
+	}
+	return &UserEventsResponse{
+		UserID: int(userID),
+		Events: events,
+	}
+}
+
 // ErrorResponse represents an error response
 type ErrorResponse struct {
 	Error   string                 `json:"error"`
@@ -118,6 +320,50 @@ type ErrorResponse struct {
 	Context map[string]interface{} `json:"context,omitempty"`
 }
 
+// NewErrorResponse creates an ErrorResponse from an error, using the code,
+// message and context of a domain error when available
+func NewErrorResponse(err error) *ErrorResponse {
+	var domainErr *domainErrors.DomainError
+	if !errors.As(err, &domainErr) {
+		return &ErrorResponse{
+			Error:   err.Error(),
+			Code:    "INTERNAL_ERROR",
+			Message: "An internal error occurred",
+		}
+	}
+
+	domainErr = withValidationFields(domainErr, err)
+	return &ErrorResponse{
+		Error:   domainErr.Error(),
+		Code:    string(domainErr.Code),
+		Message: domainErr.Message,
+		Context: domainErr.SafeContext(),
+	}
+}
+
+// ErrorBody returns the JSON body for an error response: the domain error
+// itself, which serializes as {code, message, context, cause}, or an
+// ErrorResponse for errors from outside the domain
+func ErrorBody(err error) interface{} {
+	var domainErr *domainErrors.DomainError
+	if !errors.As(err, &domainErr) {
+		return NewErrorResponse(err)
+	}
+	return withValidationFields(domainErr, err)
+}
+
+// withValidationFields returns domainErr with the per-field validation
+// failures in err's chain added to its context, so clients can map them to
+// inputs. domainErr itself is not modified.
+func withValidationFields(domainErr *domainErrors.DomainError, err error) *domainErrors.DomainError {
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		return domainErr
+	}
+
+	return domainErr.WithContext("fields", validationErr.Fields)
+}
+
 // SuccessResponse represents a generic success response
 type SuccessResponse struct {
 	Message string      `json:"message"`
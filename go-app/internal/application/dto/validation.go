@@ -0,0 +1,138 @@
+package dto
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+
+	"go-app/internal/domain/entity"
+	"go-app/internal/domain/pagination"
+)
+
+// Validation rules reported in FieldError.Rule
+const (
+	RuleRequired  = "required"
+	RuleMinLength = "min_length"
+	RuleMaxLength = "max_length"
+	RuleEmail     = "email"
+	RulePhone     = "phone"
+	RuleOneOf     = "one_of"
+	RuleMin       = "min"
+	RuleMax       = "max"
+)
+
+// validate checks the `validate` struct tags of request DTOs. Field names in
+// its errors come from the json tags, so they match the request body. The
+// entity constructors still validate the same values afterwards.
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	// Replace the built-in e164 rule, which accepts a leading 0 in the
+	// country code, with the one the entity enforces
+	_ = v.RegisterValidation("e164", func(fl validator.FieldLevel) bool {
+		return entity.IsE164(fl.Field().String())
+	})
+	return v
+}
+
+// FieldError describes a single failed validation rule for a request field
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+	Max     int    `json:"max,omitempty"` // largest accepted value, for numeric RuleMax failures
+}
+
+// ValidationError collects every field that failed validation
+type ValidationError struct {
+	Fields []FieldError
+}
+
+// Error implements the error interface
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		messages[i] = f.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// add records a failed rule for a field
+func (e *ValidationError) add(field, rule, message string) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Rule: rule, Message: message})
+}
+
+// errOrNil returns the error when any field failed, or nil otherwise
+func (e *ValidationError) errOrNil() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+// validateStruct runs the tag validation on req, collecting every failed
+// field into verr
+func (e *ValidationError) validateStruct(req interface{}) {
+	err := validate.Struct(req)
+	if err == nil {
+		return
+	}
+
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		// Only returned for invalid arguments, which is a programming error
+		panic(fmt.Sprintf("dto: cannot validate %T: %v", req, err))
+	}
+	for _, fe := range fieldErrs {
+		rule, message := describeFieldError(fe)
+		e.add(fe.Field(), rule, message)
+	}
+}
+
+// describeFieldError maps a validator tag failure to a rule and message
+func describeFieldError(fe validator.FieldError) (string, string) {
+	field := fe.Field()
+	switch fe.Tag() {
+	case "required":
+		return RuleRequired, field + " is required"
+	case "min":
+		return RuleMinLength, fmt.Sprintf("%s must be at least %s characters long", field, fe.Param())
+	case "max":
+		return RuleMaxLength, fmt.Sprintf("%s cannot exceed %s characters", field, fe.Param())
+	case "email":
+		return RuleEmail, field + " must be a valid email address"
+	case "e164":
+		return RulePhone, field + " must be in E.164 format, e.g. +14155552671"
+	case "oneof":
+		return RuleOneOf, fmt.Sprintf("%s must be one of: %s", field, strings.ReplaceAll(fe.Param(), " ", ", "))
+	default:
+		return fe.Tag(), field + " is invalid"
+	}
+}
+
+// validatePagination checks limit and offset, defaulting an unset limit
+func (e *ValidationError) validatePagination(limit *int, offset int) {
+	if maxLimit := pagination.MaxLimit(); *limit > maxLimit {
+		e.Fields = append(e.Fields, FieldError{
+			Field:   "limit",
+			Rule:    RuleMax,
+			Message: fmt.Sprintf("limit cannot exceed %d", maxLimit),
+			Max:     maxLimit,
+		})
+	}
+	if offset < 0 {
+		e.add("offset", RuleMin, "offset cannot be negative")
+	}
+	*limit, _ = pagination.Normalize(*limit, offset)
+}
@@ -0,0 +1,21 @@
+package dto
+
+import (
+	"testing"
+
+	"go-app/internal/domain/entity"
+)
+
+func TestPhoneRuleMatchesEntity(t *testing.T) {
+	phones := []string{"+14155552671", "+442071838750", "+0123456789", "+1", "14155552671", "+1415555267112345"}
+	for _, phone := range phones {
+		t.Run(phone, func(t *testing.T) {
+			req := CreateUserRequest{Name: "Jane Doe", Email: "jane@example.com", Phone: phone}
+			dtoValid := req.Validate() == nil
+			_, err := entity.NewPhone(phone)
+			if entityValid := err == nil; dtoValid != entityValid {
+				t.Errorf("DTO accepts %q: %v, entity accepts it: %v", phone, dtoValid, entityValid)
+			}
+		})
+	}
+}
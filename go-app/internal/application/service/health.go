@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"go-app/internal/infrastructure/telemetry"
+)
+
+// Dependency health states reported by HealthService
+const (
+	HealthStatusUnknown   = "unknown"
+	HealthStatusHealthy   = "ok"
+	HealthStatusUnhealthy = "error"
+)
+
+// HealthChecker is implemented by dependency clients that can report reachability
+type HealthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
+// DependencyHealth is the cached result of the last check of one dependency
+type DependencyHealth struct {
+	Status      string    `json:"status"`
+	Error       string    `json:"error,omitempty"`
+	LastChecked time.Time `json:"last_checked,omitempty"`
+	LatencyMs   float64   `json:"latency_ms"`
+}
+
+// HealthService checks dependencies in the background and caches the
+// results, so probe requests read the cache instead of hitting every
+// dependency on each call
+type HealthService struct {
+	checks   map[string]HealthChecker
+	interval time.Duration
+	timeout  time.Duration
+	tracer   trace.Tracer
+
+	mu      sync.RWMutex
+	results map[string]DependencyHealth
+}
+
+// NewHealthService creates a HealthService that re-checks every dependency
+// each interval, bounding each check by timeout. Results are reported as
+// unknown until the first check completes.
+func NewHealthService(checks map[string]HealthChecker, tel *telemetry.Telemetry, interval, timeout time.Duration) *HealthService {
+	results := make(map[string]DependencyHealth, len(checks))
+	for name := range checks {
+		results[name] = DependencyHealth{Status: HealthStatusUnknown}
+	}
+	return &HealthService{
+		checks:   checks,
+		interval: interval,
+		timeout:  timeout,
+		tracer:   tel.Tracer,
+		results:  results,
+	}
+}
+
+// Start checks every dependency immediately and then each interval until
+// ctx is cancelled
+func (s *HealthService) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			s.refresh(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Status returns a snapshot of the cached results and whether every
+// dependency passed its last check
+func (s *HealthService) Status() (map[string]DependencyHealth, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ready := true
+	statuses := make(map[string]DependencyHealth, len(s.results))
+	for name, result := range s.results {
+		statuses[name] = result
+		if result.Status != HealthStatusHealthy {
+			ready = false
+		}
+	}
+	return statuses, ready
+}
+
+// refresh checks every dependency concurrently and waits for all of them
+func (s *HealthService) refresh(ctx context.Context) {
+	var wg sync.WaitGroup
+	for name, checker := range s.checks {
+		wg.Add(1)
+		go func(name string, checker HealthChecker) {
+			defer wg.Done()
+			s.check(ctx, name, checker)
+		}(name, checker)
+	}
+	wg.Wait()
+}
+
+// check runs a single dependency check in its own span and caches the result
+func (s *HealthService) check(ctx context.Context, name string, checker HealthChecker) {
+	ctx, span := s.tracer.Start(ctx, "HealthService.check", trace.WithNewRoot())
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("operation", "health_check"),
+		attribute.String("dependency", name),
+	)
+
+	checkCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := checker.HealthCheck(checkCtx)
+	latency := time.Since(start)
+
+	result := DependencyHealth{
+		Status:      HealthStatusHealthy,
+		LastChecked: start.UTC(),
+		LatencyMs:   float64(latency.Microseconds()) / 1000,
+	}
+	span.SetAttributes(attribute.Float64("health.latency_ms", result.LatencyMs))
+	if err != nil {
+		// Shutdown cancels in-flight checks; keep the last real result
+		if ctx.Err() != nil {
+			return
+		}
+		result.Status = HealthStatusUnhealthy
+		result.Error = err.Error()
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "health check failed")
+	}
+
+	s.mu.Lock()
+	previous := s.results[name]
+	s.results[name] = result
+	s.mu.Unlock()
+
+	// Log transitions only, since checks run every interval
+	if previous.Status != result.Status {
+		level := telemetry.LevelInfo
+		if err != nil {
+			level = telemetry.LevelWarn
+		}
+		telemetry.Log(ctx, level, "Dependency health changed", err,
+			attribute.String("dependency", name),
+			attribute.String("status", result.Status),
+			attribute.String("previous_status", previous.Status),
+		)
+	}
+}
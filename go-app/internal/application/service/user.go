@@ -2,41 +2,140 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"strconv"
 	"time"
 
+	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
 	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/singleflight"
 
 	"go-app/internal/application/dto"
 	"go-app/internal/domain/entity"
 	"go-app/internal/domain/errors"
+	"go-app/internal/domain/event"
 	"go-app/internal/domain/repository"
 	"go-app/internal/infrastructure/telemetry"
 )
 
+// errorCodeOther labels errors without a known domain error code
+const errorCodeOther = "OTHER"
+
+// EventPublisher publishes domain events to downstream consumers
+type EventPublisher interface {
+	Publish(ctx context.Context, key string, event interface{}) error
+}
+
 // UserService handles user-related business operations
 type UserService struct {
 	repo      repository.UserRepository
+	txManager repository.TxManager
+	publisher EventPublisher
+	outbox    repository.OutboxRepository       // nil publishes events after commit instead
+	activity  repository.UserActivityRepository // nil keeps no activity history
 	telemetry *telemetry.Telemetry
 	tracer    trace.Tracer
+	listGroup *singleflight.Group // nil disables list query sharing
+}
+
+// noopTxManager runs work without a transaction
+type noopTxManager struct{}
+
+func (noopTxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
 }
 
 // NewUserService creates a new UserService
 func NewUserService(repo repository.UserRepository, tel *telemetry.Telemetry) *UserService {
-	return &UserService{
+	s := &UserService{
 		repo:      repo,
+		txManager: noopTxManager{},
 		telemetry: tel,
 		tracer:    tel.Tracer,
 	}
+	if tel.Meter != nil {
+		s.registerUserCountGauge(tel.Meter)
+	}
+	return s
+}
+
+// registerUserCountGauge registers the users.total gauge, observed from the
+// repository on each collection. Failures are logged, not fatal.
+func (s *UserService) registerUserCountGauge(meter metric.Meter) {
+	gauge, err := meter.Int64ObservableGauge("users.total",
+		metric.WithDescription("Current number of users"),
+		metric.WithUnit("{user}"))
+	if err != nil {
+		telemetry.Log(context.Background(), telemetry.LevelWarn, "Failed to create users.total gauge", err)
+		return
+	}
+
+	_, err = meter.RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+		count, err := s.repo.Count(ctx)
+		if err != nil {
+			// Skip this observation rather than report a wrong value
+			return err
+		}
+		o.ObserveInt64(gauge, int64(count))
+		return nil
+	}, gauge)
+	if err != nil {
+		telemetry.Log(context.Background(), telemetry.LevelWarn, "Failed to register users.total callback", err)
+	}
+}
+
+// WithTxManager sets the transaction manager used for read-then-write operations
+func (s *UserService) WithTxManager(txManager repository.TxManager) *UserService {
+	s.txManager = txManager
+	return s
+}
+
+// WithListSingleflight toggles sharing one list query between concurrent
+// identical ListUsers calls
+func (s *UserService) WithListSingleflight(enabled bool) *UserService {
+	if enabled {
+		s.listGroup = &singleflight.Group{}
+	} else {
+		s.listGroup = nil
+	}
+	return s
+}
+
+// WithEventPublisher sets the publisher used to emit user events
+func (s *UserService) WithEventPublisher(publisher EventPublisher) *UserService {
+	s.publisher = publisher
+	return s
+}
+
+// WithOutbox stores user events in outbox within the transaction that creates
+// the user, for a relay to publish, instead of publishing them after commit.
+// It needs a TxManager whose transactions outbox joins.
+func (s *UserService) WithOutbox(outbox repository.OutboxRepository) *UserService {
+	s.outbox = outbox
+	return s
+}
+
+// WithActivity records each user's creation, updates and deletion in
+// activity, served by GetUserEvents
+func (s *UserService) WithActivity(activity repository.UserActivityRepository) *UserService {
+	s.activity = activity
+	return s
 }
 
 // CreateUser creates a new user
-func (s *UserService) CreateUser(ctx context.Context, req dto.CreateUserRequest) (*dto.UserResponse, error) {
+func (s *UserService) CreateUser(ctx context.Context, req dto.CreateUserRequest) (_ *dto.UserResponse, err error) {
 	ctx, span := s.tracer.Start(ctx, "UserService.CreateUser")
 	defer span.End()
+	defer func() { s.recordError(ctx, "create", err) }()
+	defer s.recordDuration(ctx, "create", time.Now(), &err)
+
+	if err := checkContext(ctx, span); err != nil {
+		return nil, err
+	}
 
 	span.SetAttributes(
 		attribute.String("operation", "create_user"),
@@ -61,7 +160,7 @@ func (s *UserService) CreateUser(ctx context.Context, req dto.CreateUserRequest)
 	}
 
 	// Create domain entity
-	user, err := entity.NewUser(req.Name, req.Email)
+	user, err := newUserFromRequest(req)
 	if err != nil {
 		span.SetAttributes(attribute.String("error", "invalid_user_data"))
 		s.recordMetric(ctx, "create", "validation_error")
@@ -82,8 +181,21 @@ func (s *UserService) CreateUser(ctx context.Context, req dto.CreateUserRequest)
 		return nil, errors.ErrUserAlreadyExists.WithContext("email", email.String())
 	}
 
-	// Save user
-	if err := s.repo.Create(ctx, user); err != nil {
+	// Save user, together with its event when the outbox is enabled
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, user); err != nil {
+			return err
+		}
+		return s.enqueueUserCreated(ctx, user)
+	})
+	if err != nil {
+		// The existence check races with concurrent creates and differs
+		// from the unique index in case, so the insert can still conflict
+		if errors.IsUserAlreadyExists(err) {
+			span.SetAttributes(attribute.String("error", "user_already_exists"))
+			s.recordMetric(ctx, "create", "conflict")
+			return nil, err
+		}
 		span.SetAttributes(attribute.String("error", "repository_error"))
 		s.recordMetric(ctx, "create", "error")
 		telemetry.Log(ctx, telemetry.LevelError, "Failed to create user", err,
@@ -100,14 +212,250 @@ func (s *UserService) CreateUser(ctx context.Context, req dto.CreateUserRequest)
 		attribute.String("user.id", user.ID().String()),
 	)
 
+	s.publishUserCreated(ctx, user)
+	s.recordActivity(ctx, user.ID(), repository.ActivityUserCreated)
+
 	s.recordMetric(ctx, "create", "success")
 	return dto.NewUserResponse(user), nil
 }
 
+// CreateUsers creates several users in one batch. Invalid items and emails
+// repeated within the batch are rejected individually; the rest are inserted
+// together and the outcome of each item is reported in order.
+//
+// In best-effort mode, valid users are created even when others fail. In
+// all-or-nothing mode, any failure rejects the whole batch and the insert is
+// rolled back by the configured TxManager.
+func (s *UserService) CreateUsers(ctx context.Context, reqs []dto.CreateUserRequest, mode string) (_ *dto.BulkCreateUsersResponse, err error) {
+	ctx, span := s.tracer.Start(ctx, "UserService.CreateUsers")
+	defer span.End()
+	defer func() { s.recordError(ctx, "bulk_create", err) }()
+	defer s.recordDuration(ctx, "bulk_create", time.Now(), &err)
+
+	if err := checkContext(ctx, span); err != nil {
+		return nil, err
+	}
+
+	if mode == "" {
+		mode = dto.BulkModeBestEffort
+	}
+	span.SetAttributes(
+		attribute.String("operation", "create_users"),
+		attribute.String("bulk.mode", mode),
+		attribute.Int("users.requested", len(reqs)),
+	)
+
+	telemetry.Log(ctx, telemetry.LevelInfo, "Creating users in bulk",
+		nil,
+		semconv.HTTPRoute("/users/bulk"),
+		attribute.String("handler", "create_users"),
+		attribute.String("operation", "create"),
+		attribute.String("bulk.mode", mode),
+		attribute.Int("users.requested", len(reqs)),
+	)
+
+	if mode != dto.BulkModeBestEffort && mode != dto.BulkModeAllOrNothing {
+		span.SetAttributes(attribute.String("error", "validation_failed"))
+		s.recordMetric(ctx, "bulk_create", "validation_error")
+		return nil, errors.NewDomainError(errors.ErrCodeValidationFailed, "mode must be one of: all_or_nothing, best_effort").
+			WithContext("mode", mode)
+	}
+	if len(reqs) == 0 || len(reqs) > dto.MaxBulkCreateUsers {
+		span.SetAttributes(attribute.String("error", "validation_failed"))
+		s.recordMetric(ctx, "bulk_create", "validation_error")
+		return nil, errors.NewDomainError(errors.ErrCodeValidationFailed, "request must contain between 1 and 100 users").
+			WithContext("count", len(reqs))
+	}
+
+	itemErrs, users, indexes := prepareBatch(reqs)
+	allOrNothing := mode == dto.BulkModeAllOrNothing
+
+	// In all-or-nothing mode, reject the batch before touching the database
+	if allOrNothing && hasErrors(itemErrs) {
+		code, message := errors.ErrCodeValidationFailed, "batch contains invalid users"
+		if hasConflict(itemErrs) {
+			code, message = errors.ErrCodeUserAlreadyExists, "batch contains duplicate emails"
+		}
+		span.SetAttributes(attribute.String("error", "validation_failed"))
+		s.recordMetric(ctx, "bulk_create", "validation_error")
+		return nil, errors.NewDomainError(code, message).
+			WithContext("results", newBulkResults(itemErrs, nil))
+	}
+
+	// Insert the valid users with a single statement
+	var opErr error
+	if len(users) > 0 {
+		txErr := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+			var results []error
+			results, opErr = s.repo.CreateBatch(ctx, users)
+			if opErr != nil {
+				return opErr
+			}
+			for j, err := range results {
+				itemErrs[indexes[j]] = err
+			}
+			if allOrNothing && hasErrors(itemErrs) {
+				opErr = errors.NewDomainError(errors.ErrCodeUserAlreadyExists, "batch contains existing users").
+					WithContext("results", newBulkResults(itemErrs, nil))
+				return opErr
+			}
+			for j, user := range users {
+				if itemErrs[indexes[j]] == nil {
+					if opErr = s.enqueueUserCreated(ctx, user); opErr != nil {
+						return opErr
+					}
+				}
+			}
+			return nil
+		})
+		if opErr != nil {
+			if errors.IsUserAlreadyExists(opErr) {
+				span.SetAttributes(attribute.String("error", "user_already_exists"))
+				s.recordMetric(ctx, "bulk_create", "conflict")
+				return nil, opErr
+			}
+			span.SetAttributes(attribute.String("error", "repository_error"))
+			s.recordMetric(ctx, "bulk_create", "error")
+			return nil, errors.NewDomainErrorWithCause(errors.ErrCodeRepositoryError, "failed to save users", opErr)
+		}
+		if txErr != nil {
+			span.SetAttributes(attribute.String("error", "transaction_error"))
+			s.recordMetric(ctx, "bulk_create", "error")
+			return nil, errors.NewDomainErrorWithCause(errors.ErrCodeRepositoryError, "failed to commit users", txErr)
+		}
+	}
+
+	// Publish events only once the users are committed
+	created := make(map[int]*entity.User, len(users))
+	for j, user := range users {
+		if i := indexes[j]; itemErrs[i] == nil {
+			created[i] = user
+			s.publishUserCreated(ctx, user)
+			s.recordActivity(ctx, user.ID(), repository.ActivityUserCreated)
+		}
+	}
+
+	response := &dto.BulkCreateUsersResponse{Results: newBulkResults(itemErrs, created)}
+	for _, result := range response.Results {
+		if result.Error != nil {
+			response.Failed++
+		} else {
+			response.Created++
+		}
+	}
+
+	span.SetAttributes(
+		attribute.Int("users.created", response.Created),
+		attribute.Int("users.failed", response.Failed),
+	)
+
+	telemetry.Log(ctx, telemetry.LevelInfo, "Users created in bulk",
+		nil,
+		semconv.HTTPRoute("/users/bulk"),
+		attribute.String("handler", "create_users"),
+		attribute.String("operation", "create"),
+		attribute.Int("users.created", response.Created),
+		attribute.Int("users.failed", response.Failed),
+	)
+
+	status := "success"
+	if response.Failed > 0 {
+		status = "partial"
+	}
+	s.recordMetric(ctx, "bulk_create", status)
+	return response, nil
+}
+
+// newUserFromRequest builds a user entity from a create request, defaulting
+// the role to user when the request omits it
+func newUserFromRequest(req dto.CreateUserRequest) (*entity.User, error) {
+	user, err := entity.NewUser(req.Name, req.Email, req.Phone)
+	if err != nil {
+		return nil, err
+	}
+	if req.Role != "" {
+		if err := user.UpdateRole(req.Role); err != nil {
+			return nil, err
+		}
+	}
+	return user, nil
+}
+
+// prepareBatch validates each request and drops emails already seen earlier in
+// the batch. It returns one error slot per request, plus the valid users and
+// the request index each of them came from.
+func prepareBatch(reqs []dto.CreateUserRequest) ([]error, []*entity.User, []int) {
+	itemErrs := make([]error, len(reqs))
+	var users []*entity.User
+	var indexes []int
+	seen := make(map[entity.Email]int, len(reqs))
+	for i, req := range reqs {
+		if err := req.Validate(); err != nil {
+			itemErrs[i] = errors.NewDomainErrorWithCause(errors.ErrCodeValidationFailed, "request validation failed", err)
+			continue
+		}
+		user, err := newUserFromRequest(req)
+		if err != nil {
+			itemErrs[i] = errors.NewDomainErrorWithCause(errors.ErrCodeInvalidUserData, "failed to create user entity", err)
+			continue
+		}
+		if first, ok := seen[user.Email()]; ok {
+			itemErrs[i] = errors.NewDomainError(errors.ErrCodeUserAlreadyExists, "duplicate email in batch").
+				WithContext("email", user.Email().String()).
+				WithContext("first_index", first)
+			continue
+		}
+		seen[user.Email()] = i
+		users = append(users, user)
+		indexes = append(indexes, i)
+	}
+	return itemErrs, users, indexes
+}
+
+// newBulkResults builds the per-item results from item errors and created users
+func newBulkResults(itemErrs []error, created map[int]*entity.User) []dto.BulkCreateUserResult {
+	results := make([]dto.BulkCreateUserResult, len(itemErrs))
+	for i, err := range itemErrs {
+		results[i].Index = i
+		if err != nil {
+			results[i].Error = dto.NewErrorResponse(err)
+		} else if user, ok := created[i]; ok {
+			results[i].User = dto.NewUserResponse(user)
+		}
+	}
+	return results
+}
+
+// hasConflict reports whether any item failed because its email is taken
+func hasConflict(itemErrs []error) bool {
+	for _, err := range itemErrs {
+		if errors.IsUserAlreadyExists(err) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasErrors reports whether any item failed
+func hasErrors(itemErrs []error) bool {
+	for _, err := range itemErrs {
+		if err != nil {
+			return true
+		}
+	}
+	return false
+}
+
 // GetUserByID retrieves a user by ID
-func (s *UserService) GetUserByID(ctx context.Context, idStr string) (*dto.UserResponse, error) {
+func (s *UserService) GetUserByID(ctx context.Context, idStr string) (_ *dto.UserResponse, err error) {
 	ctx, span := s.tracer.Start(ctx, "UserService.GetUserByID")
 	defer span.End()
+	defer func() { s.recordError(ctx, "get_by_id", err) }()
+	defer s.recordDuration(ctx, "get_by_id", time.Now(), &err)
+
+	if err := checkContext(ctx, span); err != nil {
+		return nil, err
+	}
 
 	span.SetAttributes(
 		attribute.String("operation", "get_user_by_id"),
@@ -158,9 +506,15 @@ func (s *UserService) GetUserByID(ctx context.Context, idStr string) (*dto.UserR
 }
 
 // GetUserByEmail retrieves a user by email
-func (s *UserService) GetUserByEmail(ctx context.Context, emailStr string) (*dto.UserResponse, error) {
+func (s *UserService) GetUserByEmail(ctx context.Context, emailStr string) (_ *dto.UserResponse, err error) {
 	ctx, span := s.tracer.Start(ctx, "UserService.GetUserByEmail")
 	defer span.End()
+	defer func() { s.recordError(ctx, "get_by_email", err) }()
+	defer s.recordDuration(ctx, "get_by_email", time.Now(), &err)
+
+	if err := checkContext(ctx, span); err != nil {
+		return nil, err
+	}
 
 	span.SetAttributes(
 		attribute.String("operation", "get_user_by_email"),
@@ -209,9 +563,15 @@ func (s *UserService) GetUserByEmail(ctx context.Context, emailStr string) (*dto
 }
 
 // ListUsers returns a list of all users with pagination
-func (s *UserService) ListUsers(ctx context.Context, req dto.ListUsersRequest) (*dto.ListUsersResponse, error) {
+func (s *UserService) ListUsers(ctx context.Context, req dto.ListUsersRequest) (_ *dto.ListUsersResponse, err error) {
 	ctx, span := s.tracer.Start(ctx, "UserService.ListUsers")
 	defer span.End()
+	defer func() { s.recordError(ctx, "list", err) }()
+	defer s.recordDuration(ctx, "list", time.Now(), &err)
+
+	if err := checkContext(ctx, span); err != nil {
+		return nil, err
+	}
 
 	span.SetAttributes(
 		attribute.String("operation", "list_users"),
@@ -235,47 +595,155 @@ func (s *UserService) ListUsers(ctx context.Context, req dto.ListUsersRequest) (
 		return nil, errors.NewDomainErrorWithCause(errors.ErrCodeValidationFailed, "request validation failed", err)
 	}
 
+	page, shared, err := s.fetchUserPage(ctx, req.Limit, req.Offset)
+	span.SetAttributes(attribute.Bool("query.shared", shared))
+	if ctxErr := ctx.Err(); err != nil && ctxErr != nil {
+		s.recordMetric(ctx, "list", "cancelled")
+		return nil, cancelledError(span, ctxErr)
+	}
+	if err != nil {
+		span.SetAttributes(attribute.String("error", "repository_error"))
+		s.recordMetric(ctx, "list", "error")
+		return nil, err
+	}
+	users, total := page.users, page.total
+
+	telemetry.Log(ctx, telemetry.LevelInfo, "Users fetched successfully",
+		nil,
+		semconv.HTTPRoute("/users"),
+		attribute.String("handler", "list_users"),
+		attribute.String("operation", "read"),
+		attribute.Int("users.count", len(users)),
+		attribute.Int("total.count", total),
+	)
+
+	s.recordMetric(ctx, "list", "success")
+	return dto.NewListUsersResponse(users, total, req.Limit, req.Offset), nil
+}
+
+// userPage is one page of users with the total user count
+type userPage struct {
+	users []*entity.User
+	total int
+}
+
+// fetchUserPage loads a page of users and the total count. With the list
+// singleflight enabled, concurrent calls for the same page share one query;
+// shared reports whether this call joined a query started by another. The
+// shared query runs detached from the caller's cancellation, so one caller
+// giving up does not fail the others; a cancelled caller still returns early.
+func (s *UserService) fetchUserPage(ctx context.Context, limit, offset int) (*userPage, bool, error) {
+	if s.listGroup == nil {
+		page, err := s.queryUserPage(ctx, limit, offset)
+		return page, false, err
+	}
+
+	// leader is only written by the query this call started, which happens
+	// before its result is received
+	leader := false
+	key := fmt.Sprintf("%d:%d", limit, offset)
+	resultCh := s.listGroup.DoChan(key, func() (interface{}, error) {
+		leader = true
+		return s.queryUserPage(context.WithoutCancel(ctx), limit, offset)
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, false, ctx.Err()
+	case result := <-resultCh:
+		shared := result.Shared && !leader
+		if result.Err != nil {
+			return nil, shared, result.Err
+		}
+		return result.Val.(*userPage), shared, nil
+	}
+}
+
+// queryUserPage runs the list and count queries for a page of users
+func (s *UserService) queryUserPage(ctx context.Context, limit, offset int) (*userPage, error) {
 	// Simulate some work with a child span
-	_, childSpan := s.tracer.Start(ctx, "fetch-users")
+	ctx, childSpan := s.tracer.Start(ctx, "fetch-users")
 	childSpan.SetAttributes(attribute.String("db.operation", "SELECT"))
 	defer childSpan.End()
 
-	// Simulate database operation
-	time.Sleep(50 * time.Millisecond)
+	// Simulate database operation, giving up early if the caller goes away
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(50 * time.Millisecond):
+	}
 
 	// Get users from repository
-	users, err := s.repo.List(ctx, req.Limit, req.Offset)
+	users, err := s.repo.List(ctx, limit, offset)
 	if err != nil {
-		span.SetAttributes(attribute.String("error", "repository_error"))
-		s.recordMetric(ctx, "list", "error")
 		return nil, errors.NewDomainErrorWithCause(errors.ErrCodeRepositoryError, "failed to list users", err)
 	}
 
 	// Get total count
 	total, err := s.repo.Count(ctx)
 	if err != nil {
-		span.SetAttributes(attribute.String("error", "repository_error"))
-		s.recordMetric(ctx, "list", "error")
 		return nil, errors.NewDomainErrorWithCause(errors.ErrCodeRepositoryError, "failed to count users", err)
 	}
 
-	telemetry.Log(ctx, telemetry.LevelInfo, "Users fetched successfully",
+	return &userPage{users: users, total: total}, nil
+}
+
+// SearchUsers returns users whose name or email contains the query, with pagination
+func (s *UserService) SearchUsers(ctx context.Context, req dto.SearchUsersRequest) (_ *dto.ListUsersResponse, err error) {
+	ctx, span := s.tracer.Start(ctx, "UserService.SearchUsers")
+	defer span.End()
+	defer func() { s.recordError(ctx, "search", err) }()
+	defer s.recordDuration(ctx, "search", time.Now(), &err)
+
+	if err := checkContext(ctx, span); err != nil {
+		return nil, err
+	}
+
+	span.SetAttributes(
+		attribute.String("operation", "search_users"),
+		attribute.String("search.field", req.Field),
+		attribute.Int("limit", req.Limit),
+		attribute.Int("offset", req.Offset),
+	)
+
+	// Validate request
+	if err := req.Validate(); err != nil {
+		span.SetAttributes(attribute.String("error", "validation_failed"))
+		s.recordMetric(ctx, "search", "validation_error")
+		return nil, errors.NewDomainErrorWithCause(errors.ErrCodeValidationFailed, "request validation failed", err)
+	}
+
+	users, total, err := s.repo.Search(ctx, req.Field, req.Query, req.Limit, req.Offset)
+	if err != nil {
+		span.SetAttributes(attribute.String("error", "repository_error"))
+		s.recordMetric(ctx, "search", "error")
+		return nil, errors.NewDomainErrorWithCause(errors.ErrCodeRepositoryError, "failed to search users", err)
+	}
+
+	telemetry.Log(ctx, telemetry.LevelInfo, "Users searched successfully",
 		nil,
-		semconv.HTTPRoute("/users"),
-		attribute.String("handler", "list_users"),
+		semconv.HTTPRoute("/users/search"),
+		attribute.String("handler", "search_users"),
 		attribute.String("operation", "read"),
+		attribute.String("search.field", req.Field),
 		attribute.Int("users.count", len(users)),
 		attribute.Int("total.count", total),
 	)
 
-	s.recordMetric(ctx, "list", "success")
+	s.recordMetric(ctx, "search", "success")
 	return dto.NewListUsersResponse(users, total, req.Limit, req.Offset), nil
 }
 
 // UpdateUser updates an existing user
-func (s *UserService) UpdateUser(ctx context.Context, idStr string, req dto.UpdateUserRequest) (*dto.UserResponse, error) {
+func (s *UserService) UpdateUser(ctx context.Context, idStr string, req dto.UpdateUserRequest) (_ *dto.UserResponse, err error) {
 	ctx, span := s.tracer.Start(ctx, "UserService.UpdateUser")
 	defer span.End()
+	defer func() { s.recordError(ctx, "update", err) }()
+	defer s.recordDuration(ctx, "update", time.Now(), &err)
+
+	if err := checkContext(ctx, span); err != nil {
+		return nil, err
+	}
 
 	span.SetAttributes(
 		attribute.String("operation", "update_user"),
@@ -309,60 +777,204 @@ func (s *UserService) UpdateUser(ctx context.Context, idStr string, req dto.Upda
 		return nil, errors.NewDomainErrorWithCause(errors.ErrCodeValidationFailed, "request validation failed", err)
 	}
 
+	// Read and write within one transaction so concurrent updates don't clobber each other
+	var existingUser *entity.User
+	var opErr error
+	txErr := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		existingUser, opErr = s.applyUserUpdate(ctx, span, "update", userID, req.IfMatch, &req.Name, &req.Email, &req.Phone, optional(req.Role))
+		return opErr
+	})
+	if opErr != nil {
+		return nil, opErr
+	}
+	if txErr != nil {
+		span.SetAttributes(attribute.String("error", "transaction_error"))
+		s.recordMetric(ctx, "update", "error")
+		return nil, errors.NewDomainErrorWithCause(errors.ErrCodeRepositoryError, "failed to commit user update", txErr)
+	}
+
+	telemetry.Log(ctx, telemetry.LevelInfo, "User updated successfully",
+		nil,
+		semconv.HTTPRoute("/users/{id}"),
+		attribute.String("handler", "update_user"),
+		attribute.String("operation", "update"),
+		attribute.String("user.id", existingUser.ID().String()),
+	)
+
+	s.recordActivity(ctx, existingUser.ID(), repository.ActivityUserUpdated)
+
+	s.recordMetric(ctx, "update", "success")
+	return dto.NewUserResponse(existingUser), nil
+}
+
+// PatchUser updates only the provided fields of an existing user
+func (s *UserService) PatchUser(ctx context.Context, idStr string, req dto.PatchUserRequest) (_ *dto.UserResponse, err error) {
+	ctx, span := s.tracer.Start(ctx, "UserService.PatchUser")
+	defer span.End()
+	defer func() { s.recordError(ctx, "patch", err) }()
+	defer s.recordDuration(ctx, "patch", time.Now(), &err)
+
+	if err := checkContext(ctx, span); err != nil {
+		return nil, err
+	}
+
+	span.SetAttributes(
+		attribute.String("operation", "patch_user"),
+		attribute.String("user.id", idStr),
+		attribute.Bool("user.name.provided", req.Name != nil),
+		attribute.Bool("user.email.provided", req.Email != nil),
+		attribute.Bool("user.phone.provided", req.Phone != nil),
+		attribute.Bool("user.role.provided", req.Role != nil),
+	)
+
+	telemetry.Log(ctx, telemetry.LevelInfo, "Patching user",
+		nil,
+		semconv.HTTPRoute("/users/{id}"),
+		attribute.String("handler", "patch_user"),
+		attribute.String("operation", "patch"),
+		attribute.String("user.id", idStr),
+	)
+
+	// Parse and validate ID
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		span.SetAttributes(attribute.String("error", "invalid_id"))
+		s.recordMetric(ctx, "patch", "validation_error")
+		return nil, errors.ErrInvalidID.WithContext("id", idStr)
+	}
+
+	userID := entity.UserID(id)
+
+	// Validate request
+	if err := req.Validate(); err != nil {
+		span.SetAttributes(attribute.String("error", "validation_failed"))
+		s.recordMetric(ctx, "patch", "validation_error")
+		return nil, errors.NewDomainErrorWithCause(errors.ErrCodeValidationFailed, "request validation failed", err)
+	}
+
+	// Read and write within one transaction so concurrent updates don't clobber each other
+	var existingUser *entity.User
+	var opErr error
+	txErr := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		existingUser, opErr = s.applyUserUpdate(ctx, span, "patch", userID, req.IfMatch, req.Name, req.Email, req.Phone, req.Role)
+		return opErr
+	})
+	if opErr != nil {
+		return nil, opErr
+	}
+	if txErr != nil {
+		span.SetAttributes(attribute.String("error", "transaction_error"))
+		s.recordMetric(ctx, "patch", "error")
+		return nil, errors.NewDomainErrorWithCause(errors.ErrCodeRepositoryError, "failed to commit user patch", txErr)
+	}
+
+	telemetry.Log(ctx, telemetry.LevelInfo, "User patched successfully",
+		nil,
+		semconv.HTTPRoute("/users/{id}"),
+		attribute.String("handler", "patch_user"),
+		attribute.String("operation", "patch"),
+		attribute.String("user.id", existingUser.ID().String()),
+	)
+
+	s.recordActivity(ctx, existingUser.ID(), repository.ActivityUserUpdated)
+
+	s.recordMetric(ctx, "patch", "success")
+	return dto.NewUserResponse(existingUser), nil
+}
+
+// optional returns nil for an empty value, so an omitted field is left unchanged
+func optional(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// applyUserUpdate loads a user, applies the provided fields and saves it.
+// Nil fields are left unchanged. When ifMatch is set, the update is rejected
+// unless it matches the stored user's ETag.
+func (s *UserService) applyUserUpdate(ctx context.Context, span trace.Span, operation string, userID entity.UserID, ifMatch string, name, email, phone, role *string) (*entity.User, error) {
 	// Get existing user
 	existingUser, err := s.repo.GetByID(ctx, userID)
 	if err != nil {
 		if errors.IsUserNotFound(err) {
 			span.SetAttributes(attribute.String("error", "user_not_found"))
-			s.recordMetric(ctx, "update", "not_found")
+			s.recordMetric(ctx, operation, "not_found")
 			return nil, err
 		}
 		span.SetAttributes(attribute.String("error", "repository_error"))
-		s.recordMetric(ctx, "update", "error")
+		s.recordMetric(ctx, operation, "error")
 		return nil, errors.NewDomainErrorWithCause(errors.ErrCodeRepositoryError, "failed to get user", err)
 	}
 
+	// Reject the update if the client's copy is stale
+	if ifMatch != "" {
+		if current := dto.UserETag(existingUser); !dto.ETagMatches(ifMatch, current) {
+			span.SetAttributes(attribute.String("error", "precondition_failed"))
+			s.recordMetric(ctx, operation, "precondition_failed")
+			return nil, errors.NewDomainError(errors.ErrCodePreconditionFailed, "user has been modified").
+				WithContext("etag", current)
+		}
+	}
+
 	// Update user fields
-	if err := existingUser.UpdateName(req.Name); err != nil {
-		span.SetAttributes(attribute.String("error", "invalid_name"))
-		s.recordMetric(ctx, "update", "validation_error")
-		return nil, errors.NewDomainErrorWithCause(errors.ErrCodeInvalidName, "failed to update name", err)
+	if name != nil {
+		if err := existingUser.UpdateName(*name); err != nil {
+			span.SetAttributes(attribute.String("error", "invalid_name"))
+			s.recordMetric(ctx, operation, "validation_error")
+			return nil, errors.NewDomainErrorWithCause(errors.ErrCodeInvalidName, "failed to update name", err)
+		}
 	}
 
-	if err := existingUser.UpdateEmail(req.Email); err != nil {
-		span.SetAttributes(attribute.String("error", "invalid_email"))
-		s.recordMetric(ctx, "update", "validation_error")
-		return nil, errors.NewDomainErrorWithCause(errors.ErrCodeInvalidEmail, "failed to update email", err)
+	if email != nil {
+		if err := existingUser.UpdateEmail(*email); err != nil {
+			span.SetAttributes(attribute.String("error", "invalid_email"))
+			s.recordMetric(ctx, operation, "validation_error")
+			return nil, errors.NewDomainErrorWithCause(errors.ErrCodeInvalidEmail, "failed to update email", err)
+		}
+	}
+
+	if phone != nil {
+		if err := existingUser.UpdatePhone(*phone); err != nil {
+			span.SetAttributes(attribute.String("error", "invalid_phone"))
+			s.recordMetric(ctx, operation, "validation_error")
+			return nil, errors.NewDomainErrorWithCause(errors.ErrCodeInvalidPhone, "failed to update phone", err)
+		}
+	}
+
+	if role != nil {
+		if err := existingUser.UpdateRole(*role); err != nil {
+			span.SetAttributes(attribute.String("error", "invalid_role"))
+			s.recordMetric(ctx, operation, "validation_error")
+			return nil, errors.NewDomainErrorWithCause(errors.ErrCodeInvalidRole, "failed to update role", err)
+		}
 	}
 
 	// Save updated user
 	if err := s.repo.Update(ctx, existingUser); err != nil {
 		if errors.IsUserAlreadyExists(err) {
 			span.SetAttributes(attribute.String("error", "email_conflict"))
-			s.recordMetric(ctx, "update", "conflict")
+			s.recordMetric(ctx, operation, "conflict")
 			return nil, err
 		}
 		span.SetAttributes(attribute.String("error", "repository_error"))
-		s.recordMetric(ctx, "update", "error")
+		s.recordMetric(ctx, operation, "error")
 		return nil, errors.NewDomainErrorWithCause(errors.ErrCodeRepositoryError, "failed to update user", err)
 	}
 
-	telemetry.Log(ctx, telemetry.LevelInfo, "User updated successfully",
-		nil,
-		semconv.HTTPRoute("/users/{id}"),
-		attribute.String("handler", "update_user"),
-		attribute.String("operation", "update"),
-		attribute.String("user.id", existingUser.ID().String()),
-	)
-
-	s.recordMetric(ctx, "update", "success")
-	return dto.NewUserResponse(existingUser), nil
+	return existingUser, nil
 }
 
 // DeleteUser removes a user by ID
-func (s *UserService) DeleteUser(ctx context.Context, idStr string) error {
+func (s *UserService) DeleteUser(ctx context.Context, idStr string) (err error) {
 	ctx, span := s.tracer.Start(ctx, "UserService.DeleteUser")
 	defer span.End()
+	defer func() { s.recordError(ctx, "delete", err) }()
+	defer s.recordDuration(ctx, "delete", time.Now(), &err)
+
+	if err := checkContext(ctx, span); err != nil {
+		return err
+	}
 
 	span.SetAttributes(
 		attribute.String("operation", "delete_user"),
@@ -407,10 +1019,205 @@ func (s *UserService) DeleteUser(ctx context.Context, idStr string) error {
 		attribute.String("user.id", idStr),
 	)
 
+	s.recordActivity(ctx, userID, repository.ActivityUserDeleted)
+
 	s.recordMetric(ctx, "delete", "success")
 	return nil
 }
 
+// DeleteUsers removes every user matching the request's filters in one
+// repository call and reports how many were removed
+func (s *UserService) DeleteUsers(ctx context.Context, req dto.DeleteUsersRequest) (_ *dto.DeleteUsersResponse, err error) {
+	ctx, span := s.tracer.Start(ctx, "UserService.DeleteUsers")
+	defer span.End()
+	defer func() { s.recordError(ctx, "bulk_delete", err) }()
+	defer s.recordDuration(ctx, "bulk_delete", time.Now(), &err)
+
+	if err := checkContext(ctx, span); err != nil {
+		return nil, err
+	}
+
+	span.SetAttributes(
+		attribute.String("operation", "delete_users"),
+		attribute.Int("filter.ids", len(req.IDs)),
+		attribute.String("filter.email_domain", req.EmailDomain),
+	)
+
+	telemetry.Log(ctx, telemetry.LevelInfo, "Deleting users in bulk",
+		nil,
+		semconv.HTTPRoute("/users"),
+		attribute.String("handler", "delete_users"),
+		attribute.String("operation", "delete"),
+		attribute.Int("filter.ids", len(req.IDs)),
+		attribute.String("filter.email_domain", req.EmailDomain),
+	)
+
+	if err := req.Validate(); err != nil {
+		span.SetAttributes(attribute.String("error", "validation_failed"))
+		s.recordMetric(ctx, "bulk_delete", "validation_error")
+		return nil, errors.NewDomainErrorWithCause(errors.ErrCodeValidationFailed, "request validation failed", err)
+	}
+
+	deleted, err := s.repo.DeleteByFilter(ctx, req.Filter())
+	if err != nil {
+		span.SetAttributes(attribute.String("error", "repository_error"))
+		s.recordMetric(ctx, "bulk_delete", "error")
+		return nil, errors.NewDomainErrorWithCause(errors.ErrCodeRepositoryError, "failed to delete users", err)
+	}
+	span.SetAttributes(attribute.Int("users.deleted", len(deleted)))
+
+	telemetry.Log(ctx, telemetry.LevelInfo, "Users deleted successfully",
+		nil,
+		semconv.HTTPRoute("/users"),
+		attribute.String("handler", "delete_users"),
+		attribute.String("operation", "delete"),
+		attribute.Int("users.deleted", len(deleted)),
+	)
+
+	for _, id := range deleted {
+		s.recordActivity(ctx, id, repository.ActivityUserDeleted)
+	}
+
+	s.recordMetric(ctx, "bulk_delete", "success")
+	return &dto.DeleteUsersResponse{Deleted: len(deleted)}, nil
+}
+
+// GetUserEvents returns the user's latest activity, newest first. The
+// history outlives the user, so deleted users still have one.
+func (s *UserService) GetUserEvents(ctx context.Context, idStr string, req dto.ListUserEventsRequest) (_ *dto.UserEventsResponse, err error) {
+	ctx, span := s.tracer.Start(ctx, "UserService.GetUserEvents")
+	defer span.End()
+	defer func() { s.recordError(ctx, "get_events", err) }()
+	defer s.recordDuration(ctx, "get_events", time.Now(), &err)
+
+	if err := checkContext(ctx, span); err != nil {
+		return nil, err
+	}
+
+	span.SetAttributes(
+		attribute.String("operation", "get_user_events"),
+		attribute.String("user.id", idStr),
+	)
+
+	if s.activity == nil {
+		span.SetAttributes(attribute.String("error", "feature_disabled"))
+		s.recordMetric(ctx, "get_events", "disabled")
+		return nil, errors.NewDomainError(errors.ErrCodeFeatureDisabled, "user activity history is disabled")
+	}
+
+	// Parse and validate ID
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		span.SetAttributes(attribute.String("error", "invalid_id"))
+		s.recordMetric(ctx, "get_events", "validation_error")
+		return nil, errors.ErrInvalidID.WithContext("id", idStr)
+	}
+
+	userID := entity.UserID(id)
+
+	if err := req.Validate(); err != nil {
+		span.SetAttributes(attribute.String("error", "validation_failed"))
+		s.recordMetric(ctx, "get_events", "validation_error")
+		return nil, errors.NewDomainErrorWithCause(errors.ErrCodeValidationFailed, "request validation failed", err)
+	}
+
+	activities, err := s.activity.Recent(ctx, userID, req.Limit)
+	if err != nil {
+		span.SetAttributes(attribute.String("error", "repository_error"))
+		s.recordMetric(ctx, "get_events", "error")
+		return nil, errors.NewDomainErrorWithCause(errors.ErrCodeRepositoryError, "failed to get user events", err)
+	}
+	span.SetAttributes(attribute.Int("events.count", len(activities)))
+
+	s.recordMetric(ctx, "get_events", "success")
+	return dto.NewUserEventsResponse(userID, activities), nil
+}
+
+// enqueueUserCreated stores a UserCreated event in the outbox, with the
+// current trace context so the relay can link its publish to this operation.
+// It must run in the transaction creating the user; without an outbox it does
+// nothing.
+func (s *UserService) enqueueUserCreated(ctx context.Context, user *entity.User) error {
+	if s.outbox == nil {
+		return nil
+	}
+
+	envelope, err := event.NewEvent(event.NewUserCreated(user))
+	if err != nil {
+		return err
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		envelope.TraceID = sc.TraceID().String()
+		envelope.SpanID = sc.SpanID().String()
+	}
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+
+	return s.outbox.Add(ctx, &repository.OutboxMessage{
+		Key:          user.ID().String(),
+		Event:        envelope,
+		TraceContext: carrier,
+	})
+}
+
+// publishUserCreated emits a UserCreated event. Publish failures are logged
+// and do not fail the create. With an outbox, the relay publishes instead.
+func (s *UserService) publishUserCreated(ctx context.Context, user *entity.User) {
+	if s.publisher == nil || s.outbox != nil {
+		return
+	}
+
+	if err := s.publisher.Publish(ctx, user.ID().String(), event.NewUserCreated(user)); err != nil {
+		span := trace.SpanFromContext(ctx)
+		span.SetAttributes(attribute.Bool("event.publish_failed", true))
+		// Warn rather than error so the create span is not marked as failed
+		telemetry.Log(ctx, telemetry.LevelWarn, "Failed to publish UserCreated event", err,
+			attribute.String("event.type", event.TypeUserCreated),
+			attribute.String("user.id", user.ID().String()),
+			attribute.String("error", err.Error()),
+		)
+	}
+}
+
+// recordActivity appends an entry to the user's activity history, with the
+// current trace ID. Failures are logged and do not fail the operation.
+func (s *UserService) recordActivity(ctx context.Context, userID entity.UserID, activityType string) {
+	if s.activity == nil {
+		return
+	}
+
+	activity := &repository.UserActivity{
+		Type:       activityType,
+		OccurredAt: time.Now().UTC(),
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		activity.TraceID = sc.TraceID().String()
+	}
+	if err := s.activity.Append(ctx, userID, activity); err != nil {
+		// Warn rather than error so the operation's span is not marked as failed
+		telemetry.Log(ctx, telemetry.LevelWarn, "Failed to record user activity", err,
+			attribute.String("activity.type", activityType),
+			attribute.String("user.id", userID.String()),
+		)
+	}
+}
+
+// checkContext returns a REQUEST_CANCELLED error if ctx is already done, so
+// requests whose client has gone away or timed out skip the work
+func checkContext(ctx context.Context, span trace.Span) error {
+	if err := ctx.Err(); err != nil {
+		return cancelledError(span, err)
+	}
+	return nil
+}
+
+// cancelledError marks the span as cancelled and wraps the context error,
+// context.Canceled or context.DeadlineExceeded, in a REQUEST_CANCELLED error
+func cancelledError(span trace.Span, err error) error {
+	span.SetAttributes(attribute.Bool("request.cancelled", true))
+	return errors.NewDomainErrorWithCause(errors.ErrCodeRequestCancelled, "request cancelled", err)
+}
+
 // recordMetric records a metric for user operations
 func (s *UserService) recordMetric(ctx context.Context, operation, status string) {
 	if s.telemetry != nil && s.telemetry.UserCounter != nil {
@@ -420,3 +1227,40 @@ func (s *UserService) recordMetric(ctx context.Context, operation, status string
 		))
 	}
 }
+
+// recordDuration records the latency of an operation started at start, with
+// its status taken from the error it returned. Deferred at method entry, so
+// err must point at the method's named error result.
+func (s *UserService) recordDuration(ctx context.Context, operation string, start time.Time, err *error) {
+	if s.telemetry == nil || s.telemetry.UserDuration == nil {
+		return
+	}
+	status := "success"
+	if *err != nil {
+		status = "error"
+	}
+	s.telemetry.UserDuration.Record(ctx, float64(time.Since(start).Microseconds())/1000, metric.WithAttributes(
+		attribute.String("operation", operation),
+		attribute.String("status", status),
+	))
+}
+
+// recordError counts a failed operation by domain error code. Codes outside
+// the known set are reported as OTHER to keep label cardinality bounded.
+func (s *UserService) recordError(ctx context.Context, operation string, err error) {
+	if err == nil || s.telemetry == nil || s.telemetry.UserErrors == nil {
+		return
+	}
+	s.telemetry.UserErrors.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("operation", operation),
+		attribute.String("error.code", errorCodeLabel(err)),
+	))
+}
+
+// errorCodeLabel returns the DomainError code in err's chain, or OTHER
+func errorCodeLabel(err error) string {
+	if code, ok := errors.CodeOf(err); ok && code.IsKnown() {
+		return string(code)
+	}
+	return errorCodeOther
+}
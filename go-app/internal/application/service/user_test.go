@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"go-app/internal/application/dto"
+	"go-app/internal/domain/entity"
+	"go-app/internal/domain/errors"
+	"go-app/internal/domain/repository"
+	"go-app/internal/infrastructure/repository/memory"
+	"go-app/internal/infrastructure/telemetry"
+)
+
+// newTestService creates a UserService over repo without metrics
+func newTestService(repo repository.UserRepository) *UserService {
+	return NewUserService(repo, &telemetry.Telemetry{Tracer: noop.NewTracerProvider().Tracer("test")})
+}
+
+// racingRepository reports every email as free, as when a concurrent create
+// wins the race between the existence check and the insert
+type racingRepository struct {
+	*memory.UserRepository
+}
+
+func (racingRepository) ExistsByEmail(context.Context, entity.Email) (bool, error) {
+	return false, nil
+}
+
+func TestCreateUserMixedCaseDuplicate(t *testing.T) {
+	tests := []struct {
+		name string
+		repo repository.UserRepository
+	}{
+		{"existence check", memory.NewUserRepository()},
+		{"insert conflict", racingRepository{memory.NewUserRepository()}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := newTestService(tt.repo)
+			ctx := context.Background()
+
+			if _, err := svc.CreateUser(ctx, dto.CreateUserRequest{Name: "Jane Doe", Email: "Jane.Doe@Example.com"}); err != nil {
+				t.Fatalf("first CreateUser() error = %v", err)
+			}
+			_, err := svc.CreateUser(ctx, dto.CreateUserRequest{Name: "Jane Doe", Email: "jane.doe@EXAMPLE.COM"})
+			if !errors.IsUserAlreadyExists(err) {
+				t.Fatalf("second CreateUser() error = %v, want USER_ALREADY_EXISTS", err)
+			}
+			if code, _ := errors.CodeOf(err); code != errors.ErrCodeUserAlreadyExists {
+				t.Errorf("outermost code = %s, want %s", code, errors.ErrCodeUserAlreadyExists)
+			}
+		})
+	}
+}
+
+func TestCreateUsersDuplicateMidBatch(t *testing.T) {
+	batch := []dto.CreateUserRequest{
+		{Name: "Jane Doe", Email: "jane@example.com"},
+		{Name: "John Doe", Email: "john@example.com"},
+		{Name: "Jane Again", Email: "JANE@example.com"},
+		{Name: "Mary Major", Email: "mary@example.com"},
+	}
+
+	t.Run(dto.BulkModeBestEffort, func(t *testing.T) {
+		repo := memory.NewUserRepository()
+		resp, err := newTestService(repo).CreateUsers(context.Background(), batch, dto.BulkModeBestEffort)
+		if err != nil {
+			t.Fatalf("CreateUsers() error = %v", err)
+		}
+		if resp.Created != 3 || resp.Failed != 1 {
+			t.Errorf("created = %d, failed = %d; want 3 and 1", resp.Created, resp.Failed)
+		}
+		for i, result := range resp.Results {
+			if result.Index != i {
+				t.Errorf("results[%d].Index = %d", i, result.Index)
+			}
+			if failed := result.Error != nil; failed != (i == 2) {
+				t.Errorf("results[%d] = %+v, want only index 2 to fail", i, result)
+			}
+		}
+		if code := resp.Results[2].Error.Code; code != string(errors.ErrCodeUserAlreadyExists) {
+			t.Errorf("results[2] code = %s, want %s", code, errors.ErrCodeUserAlreadyExists)
+		}
+		for _, email := range []string{"jane@example.com", "john@example.com", "mary@example.com"} {
+			if exists, _ := repo.ExistsByEmail(context.Background(), entity.Email(email)); !exists {
+				t.Errorf("%s was not created", email)
+			}
+		}
+	})
+
+	t.Run(dto.BulkModeAllOrNothing, func(t *testing.T) {
+		repo := memory.NewUserRepository()
+		resp, err := newTestService(repo).CreateUsers(context.Background(), batch, dto.BulkModeAllOrNothing)
+		if !errors.IsUserAlreadyExists(err) {
+			t.Fatalf("CreateUsers() = %+v, %v; want USER_ALREADY_EXISTS", resp, err)
+		}
+		for _, req := range batch {
+			if exists, _ := repo.ExistsByEmail(context.Background(), entity.Email(req.Email)); exists {
+				t.Errorf("%s was created from a rejected batch", req.Email)
+			}
+		}
+	})
+}
+
+func TestCreateUsersExistingEmail(t *testing.T) {
+	ctx := context.Background()
+	batch := []dto.CreateUserRequest{
+		{Name: "John Doe", Email: "john@example.com"},
+		{Name: "Jane Doe", Email: "Jane@Example.com"},
+	}
+
+	t.Run(dto.BulkModeBestEffort, func(t *testing.T) {
+		svc := newTestService(memory.NewUserRepository())
+		if _, err := svc.CreateUser(ctx, dto.CreateUserRequest{Name: "Jane Doe", Email: "jane@example.com"}); err != nil {
+			t.Fatalf("CreateUser() error = %v", err)
+		}
+		resp, err := svc.CreateUsers(ctx, batch, dto.BulkModeBestEffort)
+		if err != nil {
+			t.Fatalf("CreateUsers() error = %v", err)
+		}
+		if resp.Created != 1 || resp.Failed != 1 || resp.Results[1].Error == nil {
+			t.Errorf("CreateUsers() = %+v, want only the existing email to fail", resp)
+		}
+	})
+
+	t.Run(dto.BulkModeAllOrNothing, func(t *testing.T) {
+		svc := newTestService(memory.NewUserRepository())
+		if _, err := svc.CreateUser(ctx, dto.CreateUserRequest{Name: "Jane Doe", Email: "jane@example.com"}); err != nil {
+			t.Fatalf("CreateUser() error = %v", err)
+		}
+		if _, err := svc.CreateUsers(ctx, batch, dto.BulkModeAllOrNothing); !errors.IsUserAlreadyExists(err) {
+			t.Errorf("CreateUsers() error = %v, want USER_ALREADY_EXISTS", err)
+		}
+	})
+}
@@ -0,0 +1,93 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	domainErrors "go-app/internal/domain/errors"
+	"go-app/internal/domain/event"
+	"go-app/internal/infrastructure/telemetry"
+
+	kgopkg "github.com/twmb/franz-go/pkg/kgo"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// EventHandler processes the data of one event type
+type EventHandler func(ctx context.Context, data json.RawMessage) error
+
+// JSONHandler adapts a handler of a typed payload into an EventHandler.
+// Payloads that do not decode into T are reported as terminal errors, so
+// they are dead-lettered instead of retried.
+func JSONHandler[T any](fn func(ctx context.Context, payload T) error) EventHandler {
+	return func(ctx context.Context, data json.RawMessage) error {
+		var payload T
+		if err := json.Unmarshal(data, &payload); err != nil {
+			return domainErrors.NewDomainErrorWithCause(domainErrors.ErrCodeInvalidEvent, "failed to decode event data", err)
+		}
+		return fn(ctx, payload)
+	}
+}
+
+// RegisterHandler routes events of eventType to fn, replacing any handler
+// already registered for it. Register handlers before calling Start.
+func (w *KafkaWorker) RegisterHandler(eventType string, fn EventHandler) *KafkaWorker {
+	w.handlers[eventType] = fn
+	return w
+}
+
+// dispatch decodes the record's event envelope and calls the handler
+// registered for its type. Malformed envelopes are terminal errors. Unknown
+// types are skipped, or reported as terminal errors when they should be
+// dead-lettered.
+func (w *KafkaWorker) dispatch(ctx context.Context, record *kgopkg.Record) error {
+	var envelope event.Event
+	if err := json.Unmarshal(record.Value, &envelope); err != nil || envelope.Type == "" {
+		if err == nil {
+			err = fmt.Errorf("missing event type")
+		}
+		return domainErrors.NewDomainErrorWithCause(domainErrors.ErrCodeInvalidEvent, "failed to decode event envelope", err)
+	}
+
+	attrs := []attribute.KeyValue{
+		attribute.String("event.type", envelope.Type),
+		attribute.Int("event.version", envelope.Version),
+		attribute.String("kafka.topic", record.Topic),
+		attribute.Int64("kafka.offset", record.Offset),
+	}
+	if envelope.TraceID != "" {
+		attrs = append(attrs, attribute.String("event.trace_id", envelope.TraceID))
+	}
+	trace.SpanFromContext(ctx).SetAttributes(attrs...)
+
+	handler, ok := w.handlers[envelope.Type]
+	if !ok {
+		if w.config.DeadLetterUnknownEvents {
+			return domainErrors.NewDomainError(domainErrors.ErrCodeUnknownEvent, "no handler registered for event type").
+				WithContext("event_type", envelope.Type)
+		}
+		telemetry.Log(ctx, telemetry.LevelWarn, "Skipping Kafka event with no registered handler", nil, attrs...)
+		return nil
+	}
+
+	telemetry.Log(ctx, telemetry.LevelInfo, "Processing Kafka event", nil, attrs...)
+	return handler(ctx, envelope.Data)
+}
+
+// handleUserCreated processes UserCreated events
+func (w *KafkaWorker) handleUserCreated(ctx context.Context, payload event.UserCreated) error {
+	telemetry.Log(ctx, telemetry.LevelInfo, "User created", nil,
+		attribute.Int("user.id", payload.ID),
+	)
+
+	// Add your business logic processing here
+	// For example, you could:
+	// 1. Validate business rules
+	// 2. Execute domain operations
+	// 3. Update application state
+	// 4. Trigger other business processes
+	// 5. Send notifications or events
+
+	return nil
+}
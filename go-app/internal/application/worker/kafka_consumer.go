@@ -2,7 +2,12 @@ package worker
 
 import (
 	"context"
+	"errors"
+	"time"
 
+	domainErrors "go-app/internal/domain/errors"
+	"go-app/internal/domain/event"
+	"go-app/internal/infrastructure/config"
 	"go-app/internal/infrastructure/kafka"
 	"go-app/internal/infrastructure/telemetry"
 
@@ -10,47 +15,146 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 )
 
+// retryBackoff is the base delay between handler attempts
+const retryBackoff = 100 * time.Millisecond
+
 // KafkaWorker handles Kafka message consumption and business logic processing
 type KafkaWorker struct {
 	consumer  *kafka.Consumer
+	producer  *kafka.Producer
+	config    config.KafkaConfig
 	telemetry *telemetry.Telemetry
+	handlers  map[string]EventHandler
+	cancel    context.CancelFunc // stops polling
+	abort     context.CancelFunc // cancels the records in flight
+	done      chan struct{}
 }
 
 // NewKafkaWorker creates a new Kafka worker instance
-func NewKafkaWorker(consumer *kafka.Consumer, tel *telemetry.Telemetry) *KafkaWorker {
-	return &KafkaWorker{
+func NewKafkaWorker(consumer *kafka.Consumer, producer *kafka.Producer, cfg config.KafkaConfig, tel *telemetry.Telemetry) *KafkaWorker {
+	w := &KafkaWorker{
 		consumer:  consumer,
+		producer:  producer,
+		config:    cfg,
 		telemetry: tel,
+		handlers:  make(map[string]EventHandler),
+		done:      make(chan struct{}),
 	}
+	w.RegisterHandler(event.TypeUserCreated, JSONHandler(w.handleUserCreated))
+	return w
 }
 
-// Start begins the Kafka consumer in a separate goroutine
+// Start begins the Kafka consumer in a separate goroutine. The consumer stops
+// once ctx is cancelled or Stop is called, after the records already fetched
+// have been handled. Handlers run on a context that is not cancelled with
+// ctx, so records in flight can finish; Stop cancels it at its deadline.
 func (w *KafkaWorker) Start(ctx context.Context) {
-	go w.startConsumer(ctx)
+	var handleCtx context.Context
+	handleCtx, w.abort = context.WithCancel(context.WithoutCancel(ctx))
+	ctx, w.cancel = context.WithCancel(ctx)
+	go func() {
+		defer close(w.done)
+		defer w.abort()
+		w.startConsumer(ctx, handleCtx)
+	}()
 }
 
-// startConsumer starts the Kafka consumer with message handling
-func (w *KafkaWorker) startConsumer(ctx context.Context) {
-	messageHandler := func(ctx context.Context, record *kgopkg.Record) error {
-		telemetry.Log(ctx, telemetry.LevelInfo, "Processing Kafka message", nil,
-			attribute.String("kafka.topic", record.Topic),
-			attribute.Int64("kafka.offset", record.Offset),
-			attribute.String("kafka.value", string(record.Value)),
-		)
-
-		// Add your business logic processing here
-		// For example, you could:
-		// 1. Parse the message content
-		// 2. Validate business rules
-		// 3. Execute domain operations
-		// 4. Update application state
-		// 5. Trigger other business processes
-		// 6. Send notifications or events
-
+// Wait blocks until the consumer started by Start has drained its in-flight
+// records, or until ctx expires
+func (w *KafkaWorker) Wait(ctx context.Context) error {
+	if w.cancel == nil {
+		// Never started
 		return nil
 	}
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stop stops polling, waits for in-flight records to be handled and
+// committed, then closes the consumer client. If ctx expires first, the
+// records still in flight are cancelled and the client is closed anyway,
+// which ends the consumer loop, and ctx's error is returned.
+func (w *KafkaWorker) Stop(ctx context.Context) error {
+	if w.cancel != nil {
+		w.cancel()
+	}
+	err := w.Wait(ctx)
+	if w.abort != nil {
+		w.abort()
+	}
+	w.consumer.Close()
+	return err
+}
 
-	if err := w.consumer.ConsumeWithTracing(ctx, messageHandler); err != nil {
+// startConsumer starts the Kafka consumer, dispatching records to the
+// registered event handlers on handleCtx
+func (w *KafkaWorker) startConsumer(ctx, handleCtx context.Context) {
+	if err := w.consumer.ConsumeWithTracing(ctx, handleCtx, w.withDeadLetter(w.dispatch)); err != nil && !errors.Is(err, context.Canceled) {
 		telemetry.Log(ctx, telemetry.LevelError, "Kafka consumer error", err)
 	}
 }
+
+// withDeadLetter retries the handler up to the configured number of attempts and
+// routes records that still fail to the dead-letter topic. Terminal errors (see
+// domain errors.IsRetryable) are dead-lettered without retrying. An error is returned
+// only when the record could not be handled nor dead-lettered, so its offset
+// is left uncommitted.
+func (w *KafkaWorker) withDeadLetter(handler kafka.RecordHandler) kafka.RecordHandler {
+	return func(ctx context.Context, record *kgopkg.Record) error {
+		attempts := w.config.MaxRetries
+		if attempts < 1 {
+			attempts = 1
+		}
+
+		var err error
+		for attempt := 1; attempt <= attempts; attempt++ {
+			if err = handler(ctx, record); err == nil {
+				return nil
+			}
+
+			retryable := domainErrors.IsRetryable(err)
+			telemetry.Log(ctx, telemetry.LevelWarn, "Kafka message handler failed", err,
+				attribute.String("kafka.topic", record.Topic),
+				attribute.Int64("kafka.offset", record.Offset),
+				attribute.Int("kafka.attempt", attempt),
+				attribute.Int("kafka.max_attempts", attempts),
+				attribute.Bool("kafka.retryable", retryable),
+			)
+
+			if !retryable {
+				break
+			}
+			if attempt < attempts {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(time.Duration(attempt) * retryBackoff):
+				}
+			}
+		}
+
+		if w.config.DeadLetterTopic == "" || w.producer == nil {
+			return err
+		}
+
+		if dlqErr := w.producer.ProduceToDeadLetter(ctx, w.config.DeadLetterTopic, record, err); dlqErr != nil {
+			telemetry.Log(ctx, telemetry.LevelError, "Failed to route Kafka message to dead-letter topic", dlqErr,
+				attribute.String("kafka.topic", record.Topic),
+				attribute.Int64("kafka.offset", record.Offset),
+				attribute.String("kafka.dead_letter_topic", w.config.DeadLetterTopic),
+			)
+			return dlqErr
+		}
+
+		telemetry.Log(ctx, telemetry.LevelWarn, "Routed Kafka message to dead-letter topic", err,
+			attribute.String("kafka.topic", record.Topic),
+			attribute.Int64("kafka.offset", record.Offset),
+			attribute.String("kafka.dead_letter_topic", w.config.DeadLetterTopic),
+		)
+		return nil
+	}
+}
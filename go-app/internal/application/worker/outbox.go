@@ -0,0 +1,196 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"go-app/internal/domain/repository"
+	"go-app/internal/infrastructure/config"
+	"go-app/internal/infrastructure/kafka"
+	"go-app/internal/infrastructure/retry"
+	"go-app/internal/infrastructure/telemetry"
+)
+
+// recordProducer produces a record, continuing the trace in ctx
+type recordProducer interface {
+	ProduceWithTracing(ctx context.Context, topic string, key, value []byte) error
+}
+
+// OutboxRelay publishes the events stored in the outbox to Kafka, in the
+// order they were stored, and marks them published. Delivery is at least
+// once: a crash between producing and committing republishes the batch.
+type OutboxRelay struct {
+	outbox       repository.OutboxRepository
+	txManager    repository.TxManager
+	producer     recordProducer
+	topic        string
+	batchSize    int
+	pollInterval time.Duration
+	backoff      retry.Policy
+	tracer       trace.Tracer
+	cancel       context.CancelFunc // stops polling
+	abort        context.CancelFunc // cancels the batch in flight
+	done         chan struct{}
+}
+
+// NewOutboxRelay creates a relay publishing to the configured topic.
+// txManager must provide the transactions outbox claims messages in.
+func NewOutboxRelay(outbox repository.OutboxRepository, txManager repository.TxManager, producer *kafka.Producer, cfg config.KafkaConfig, tel *telemetry.Telemetry) *OutboxRelay {
+	return &OutboxRelay{
+		outbox:       outbox,
+		txManager:    txManager,
+		producer:     producer,
+		topic:        cfg.Topic,
+		batchSize:    cfg.OutboxBatchSize,
+		pollInterval: time.Duration(cfg.OutboxPollIntervalMs) * time.Millisecond,
+		backoff: retry.Policy{
+			BaseDelay: time.Duration(cfg.OutboxRetryBaseDelayMs) * time.Millisecond,
+			MaxDelay:  time.Duration(cfg.OutboxRetryMaxDelayMs) * time.Millisecond,
+			Jitter:    0.2,
+		},
+		tracer: tel.Tracer,
+		done:   make(chan struct{}),
+	}
+}
+
+// Start polls the outbox in a separate goroutine until ctx is cancelled or
+// Stop is called. Batches run on a context that is not cancelled with ctx,
+// so a batch already produced to Kafka is still marked published.
+func (r *OutboxRelay) Start(ctx context.Context) {
+	var batchCtx context.Context
+	batchCtx, r.abort = context.WithCancel(context.WithoutCancel(ctx))
+	ctx, r.cancel = context.WithCancel(ctx)
+	go func() {
+		defer close(r.done)
+		defer r.abort()
+		r.run(ctx, batchCtx)
+	}()
+}
+
+// Stop stops polling and waits for the batch in flight to finish. If ctx
+// expires first, the batch is cancelled, which rolls back its transaction so
+// its messages are published again on the next start, and ctx's error is
+// returned.
+func (r *OutboxRelay) Stop(ctx context.Context) error {
+	if r.cancel == nil {
+		// Never started
+		return nil
+	}
+	r.cancel()
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		r.abort()
+		return ctx.Err()
+	}
+}
+
+// run relays batches on batchCtx until ctx ends. Full batches are followed
+// immediately by the next one; after a failure the relay backs off, doubling
+// the delay for each consecutive failure.
+func (r *OutboxRelay) run(ctx, batchCtx context.Context) {
+	failures := 0
+	for {
+		published, err := r.relayBatch(batchCtx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		wait := r.pollInterval
+		switch {
+		case err != nil:
+			failures++
+			wait = r.backoff.Delay(failures)
+			telemetry.Log(ctx, telemetry.LevelWarn, "Outbox relay failed; backing off", err,
+				attribute.Int("outbox.published", published),
+				attribute.Int("outbox.consecutive_failures", failures),
+				attribute.String("outbox.backoff", wait.String()),
+			)
+		case published == r.batchSize:
+			failures = 0
+			wait = 0
+		default:
+			failures = 0
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// relayBatch publishes one batch of pending messages within a transaction.
+// It stops at the first message that fails to publish, so no later event
+// overtakes it, and records the failure on that message.
+func (r *OutboxRelay) relayBatch(ctx context.Context) (int, error) {
+	var published []int64
+	var publishErr error
+	err := r.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		messages, err := r.outbox.ClaimPending(ctx, r.batchSize)
+		if err != nil {
+			return err
+		}
+		for _, message := range messages {
+			if publishErr = r.publish(ctx, message); publishErr != nil {
+				if err := r.outbox.MarkFailed(ctx, message.ID, publishErr); err != nil {
+					return err
+				}
+				break
+			}
+			published = append(published, message.ID)
+		}
+		return r.outbox.MarkPublished(ctx, published)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(published), publishErr
+}
+
+// publish produces one message in a new trace linked to the operation that
+// stored it. Consumers continue the relay's trace from the record headers.
+func (r *OutboxRelay) publish(ctx context.Context, message *repository.OutboxMessage) error {
+	opts := []trace.SpanStartOption{trace.WithNewRoot()}
+	origin := otel.GetTextMapPropagator().Extract(context.Background(), propagation.MapCarrier(message.TraceContext))
+	if sc := trace.SpanContextFromContext(origin); sc.IsValid() {
+		opts = append(opts, trace.WithLinks(trace.Link{SpanContext: sc}))
+	}
+	ctx, span := r.tracer.Start(ctx, "OutboxRelay.publish", opts...)
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("outbox.id", message.ID),
+		attribute.Int("outbox.attempts", message.Attempts),
+		attribute.String("event.type", message.Event.Type),
+		attribute.String("kafka.topic", r.topic),
+	)
+	if message.Event.TraceID != "" {
+		span.SetAttributes(attribute.String("event.trace_id", message.Event.TraceID))
+	}
+
+	value, err := json.Marshal(message.Event)
+	if err != nil {
+		err = fmt.Errorf("failed to encode outbox event: %w", err)
+	} else {
+		err = r.producer.ProduceWithTracing(ctx, r.topic, []byte(message.Key), value)
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "outbox publish failed")
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"go-app/internal/domain/event"
+	"go-app/internal/domain/repository"
+)
+
+// fakeOutbox hands out its messages once and records what was published
+type fakeOutbox struct {
+	mu        sync.Mutex
+	pending   []*repository.OutboxMessage
+	published []int64
+	markErr   error // context error seen by MarkPublished
+}
+
+func (o *fakeOutbox) Add(context.Context, *repository.OutboxMessage) error { return nil }
+
+func (o *fakeOutbox) ClaimPending(_ context.Context, limit int) ([]*repository.OutboxMessage, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	claimed := o.pending[:min(limit, len(o.pending))]
+	o.pending = o.pending[len(claimed):]
+	return claimed, nil
+}
+
+func (o *fakeOutbox) MarkPublished(ctx context.Context, ids []int64) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		o.markErr = err
+		return err
+	}
+	o.published = append(o.published, ids...)
+	return nil
+}
+
+func (o *fakeOutbox) MarkFailed(context.Context, int64, error) error { return nil }
+
+// directTx runs work without a transaction
+type directTx struct{}
+
+func (directTx) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// blockingProducer blocks each produce until released, then fails if its
+// context was cancelled meanwhile
+type blockingProducer struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (p *blockingProducer) ProduceWithTracing(ctx context.Context, _ string, _, _ []byte) error {
+	close(p.started)
+	<-p.release
+	return ctx.Err()
+}
+
+func TestOutboxRelayStopFinishesBatchInFlight(t *testing.T) {
+	outbox := &fakeOutbox{pending: []*repository.OutboxMessage{
+		{ID: 1, Key: "1", Event: event.Event{Type: event.TypeUserCreated}},
+	}}
+	producer := &blockingProducer{started: make(chan struct{}), release: make(chan struct{})}
+	relay := &OutboxRelay{
+		outbox:       outbox,
+		txManager:    directTx{},
+		producer:     producer,
+		topic:        "user-events",
+		batchSize:    10,
+		pollInterval: time.Hour,
+		tracer:       noop.NewTracerProvider().Tracer("test"),
+		done:         make(chan struct{}),
+	}
+
+	runCtx, shutdown := context.WithCancel(context.Background())
+	relay.Start(runCtx)
+	<-producer.started
+
+	// Shut down while the batch is still producing
+	shutdown()
+	close(producer.release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := relay.Stop(ctx); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+
+	if outbox.markErr != nil {
+		t.Fatalf("batch was cancelled before being marked published: %v", outbox.markErr)
+	}
+	if len(outbox.published) != 1 || outbox.published[0] != 1 {
+		t.Errorf("published = %v, want [1]", outbox.published)
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"go-app/internal/domain/errors"
 )
@@ -51,16 +54,27 @@ func (e Email) IsValid() bool {
 // Name represents a user's name with validation
 type Name string
 
-// NewName creates a new Name after validation
+// NewName creates a new Name after validation.
+//
+// Runs of whitespace, including tabs and newlines, collapse to a single space.
+// Lengths are counted in characters (runes), not bytes, so names in
+// multibyte scripts get the same 2 to 100 character range.
 func NewName(name string) (Name, error) {
-	name = strings.TrimSpace(name)
+	if !utf8.ValidString(name) {
+		return "", errors.NewDomainError(errors.ErrCodeInvalidName, "name must be valid UTF-8")
+	}
+	name = strings.Join(strings.Fields(name), " ")
 	if name == "" {
 		return "", errors.NewDomainError(errors.ErrCodeInvalidName, "name cannot be empty")
 	}
-	if len(name) < 2 {
+	if strings.IndexFunc(name, unicode.IsControl) >= 0 {
+		return "", errors.NewDomainError(errors.ErrCodeInvalidName, "name cannot contain control characters")
+	}
+	length := utf8.RuneCountInString(name)
+	if length < 2 {
 		return "", errors.NewDomainError(errors.ErrCodeInvalidName, "name must be at least 2 characters long")
 	}
-	if len(name) > 100 {
+	if length > 100 {
 		return "", errors.NewDomainError(errors.ErrCodeInvalidName, "name cannot exceed 100 characters")
 	}
 	return Name(name), nil
@@ -71,15 +85,82 @@ func (n Name) String() string {
 	return string(n)
 }
 
+// Phone represents an optional phone number in E.164 format. The zero value
+// means no phone number.
+type Phone string
+
+var phoneRegex = regexp.MustCompile(`^\+[1-9][0-9]{1,14}$`)
+
+// IsE164 reports whether phone is in E.164 format: a "+", a non-zero country
+// code digit and at most 15 digits in total
+func IsE164(phone string) bool {
+	return phoneRegex.MatchString(phone)
+}
+
+// NewPhone creates a new Phone after validation. An empty string yields the
+// empty Phone.
+func NewPhone(phone string) (Phone, error) {
+	phone = strings.TrimSpace(phone)
+	if phone == "" {
+		return "", nil
+	}
+	if !IsE164(phone) {
+		return "", errors.ErrInvalidPhone
+	}
+	return Phone(phone), nil
+}
+
+// String returns the string representation of the phone number
+func (p Phone) String() string {
+	return string(p)
+}
+
+// IsSet reports whether a phone number is present
+func (p Phone) IsSet() bool {
+	return p != ""
+}
+
+// Role represents a user's authorization role
+type Role string
+
+// Supported roles
+const (
+	RoleAdmin Role = "admin"
+	RoleUser  Role = "user"
+)
+
+// NewRole creates a new Role after validation. An empty string yields the
+// default RoleUser.
+func NewRole(role string) (Role, error) {
+	switch Role(strings.ToLower(strings.TrimSpace(role))) {
+	case "", RoleUser:
+		return RoleUser, nil
+	case RoleAdmin:
+		return RoleAdmin, nil
+	default:
+		return "", errors.ErrInvalidRole.WithContext("role", role)
+	}
+}
+
+// String returns the string representation of the role
+func (r Role) String() string {
+	return string(r)
+}
+
 // User represents a user entity in the domain
 type User struct {
-	id    UserID
-	name  Name
-	email Email
+	id        UserID
+	name      Name
+	email     Email
+	phone     Phone
+	role      Role
+	createdAt time.Time
+	updatedAt time.Time
 }
 
-// NewUser creates a new User with validation
-func NewUser(name, email string) (*User, error) {
+// NewUser creates a new User with validation. The phone is optional and may
+// be empty. New users get RoleUser; use UpdateRole to change it.
+func NewUser(name, email, phone string) (*User, error) {
 	userName, err := NewName(name)
 	if err != nil {
 		return nil, errors.NewDomainErrorWithCause(errors.ErrCodeInvalidUserData, "invalid name for new user", err)
@@ -90,9 +171,19 @@ func NewUser(name, email string) (*User, error) {
 		return nil, errors.NewDomainErrorWithCause(errors.ErrCodeInvalidUserData, "invalid email for new user", err)
 	}
 
+	userPhone, err := NewPhone(phone)
+	if err != nil {
+		return nil, errors.NewDomainErrorWithCause(errors.ErrCodeInvalidUserData, "invalid phone for new user", err)
+	}
+
+	now := time.Now().UTC()
 	return &User{
-		name:  userName,
-		email: userEmail,
+		name:      userName,
+		email:     userEmail,
+		phone:     userPhone,
+		role:      RoleUser,
+		createdAt: now,
+		updatedAt: now,
 	}, nil
 }
 
@@ -111,11 +202,42 @@ func (u *User) Email() Email {
 	return u.email
 }
 
+// Phone returns the user's phone number, empty when not set
+func (u *User) Phone() Phone {
+	return u.phone
+}
+
+// Role returns the user's role
+func (u *User) Role() Role {
+	return u.role
+}
+
+// HasRole reports whether the user has the given role
+func (u *User) HasRole(role Role) bool {
+	return u.role == role
+}
+
+// CreatedAt returns when the user was created
+func (u *User) CreatedAt() time.Time {
+	return u.createdAt
+}
+
+// UpdatedAt returns when the user was last updated
+func (u *User) UpdatedAt() time.Time {
+	return u.updatedAt
+}
+
 // SetID sets the user's ID (used by repository layer)
 func (u *User) SetID(id UserID) {
 	u.id = id
 }
 
+// SetTimestamps sets the user's creation and update times (used by repository layer)
+func (u *User) SetTimestamps(createdAt, updatedAt time.Time) {
+	u.createdAt = createdAt
+	u.updatedAt = updatedAt
+}
+
 // UpdateName updates the user's name with validation
 func (u *User) UpdateName(name string) error {
 	userName, err := NewName(name)
@@ -123,6 +245,7 @@ func (u *User) UpdateName(name string) error {
 		return errors.NewDomainErrorWithCause(errors.ErrCodeInvalidUserData, "invalid name for update", err)
 	}
 	u.name = userName
+	u.updatedAt = time.Now().UTC()
 	return nil
 }
 
@@ -133,6 +256,31 @@ func (u *User) UpdateEmail(email string) error {
 		return errors.NewDomainErrorWithCause(errors.ErrCodeInvalidUserData, "invalid email for update", err)
 	}
 	u.email = userEmail
+	u.updatedAt = time.Now().UTC()
+	return nil
+}
+
+// UpdatePhone updates the user's phone number with validation. An empty
+// phone removes it.
+func (u *User) UpdatePhone(phone string) error {
+	userPhone, err := NewPhone(phone)
+	if err != nil {
+		return errors.NewDomainErrorWithCause(errors.ErrCodeInvalidUserData, "invalid phone for update", err)
+	}
+	u.phone = userPhone
+	u.updatedAt = time.Now().UTC()
+	return nil
+}
+
+// UpdateRole updates the user's role, rejecting unknown roles. An empty role
+// resets it to RoleUser.
+func (u *User) UpdateRole(role string) error {
+	userRole, err := NewRole(role)
+	if err != nil {
+		return errors.NewDomainErrorWithCause(errors.ErrCodeInvalidUserData, "invalid role for update", err)
+	}
+	u.role = userRole
+	u.updatedAt = time.Now().UTC()
 	return nil
 }
 
@@ -0,0 +1,49 @@
+package entity
+
+import (
+	"strings"
+	"testing"
+
+	"go-app/internal/domain/errors"
+)
+
+func TestNewName(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Name
+		wantErr bool
+	}{
+		{"ascii", "Jane Doe", "Jane Doe", false},
+		{"emoji", "Jane 🚀", "Jane 🚀", false},
+		{"two emoji", "🚀🌕", "🚀🌕", false},
+		{"single emoji", "🚀", "", true},
+		{"cjk", "山田太郎", "山田太郎", false},
+		{"two cjk", "李明", "李明", false},
+		{"single cjk", "李", "", true},
+		{"100 cjk runes", strings.Repeat("山", 100), Name(strings.Repeat("山", 100)), false},
+		{"101 cjk runes", strings.Repeat("山", 101), "", true},
+		{"50 emoji", strings.Repeat("🚀", 50), Name(strings.Repeat("🚀", 50)), false},
+		{"collapsed whitespace", "  Jane \t\n Doe  ", "Jane Doe", false},
+		{"nul byte", "Jane\x00Doe", "", true},
+		{"escape sequence", "Jane\x1b[31mDoe", "", true},
+		{"delete byte", "Jane\x7fDoe", "", true},
+		{"c1 control", "Jane\u0085Doe", "Jane Doe", false},
+		{"invalid utf-8", "Jane\xffDoe", "", true},
+		{"whitespace only", " \t\n ", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewName(tt.input)
+			if tt.wantErr {
+				if code, _ := errors.CodeOf(err); code != errors.ErrCodeInvalidName {
+					t.Errorf("NewName(%q) = %q, %v; want %s", tt.input, got, err, errors.ErrCodeInvalidName)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("NewName(%q) = %q, %v; want %q", tt.input, got, err, tt.want)
+			}
+		})
+	}
+}
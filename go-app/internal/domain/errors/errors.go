@@ -18,23 +18,71 @@ const (
 	ErrCodeValidationFailed ErrorCode = "VALIDATION_FAILED"
 	ErrCodeInvalidEmail     ErrorCode = "INVALID_EMAIL"
 	ErrCodeInvalidName      ErrorCode = "INVALID_NAME"
+	ErrCodeInvalidPhone     ErrorCode = "INVALID_PHONE"
+	ErrCodeInvalidRole      ErrorCode = "INVALID_ROLE"
 	ErrCodeInvalidID        ErrorCode = "INVALID_ID"
 
+	// Concurrency errors
+	ErrCodePreconditionFailed ErrorCode = "PRECONDITION_FAILED"
+
 	// Repository errors
 	ErrCodeRepositoryError ErrorCode = "REPOSITORY_ERROR"
 	ErrCodeDatabaseError   ErrorCode = "DATABASE_ERROR"
 
 	// Application errors
-	ErrCodeInternalError ErrorCode = "INTERNAL_ERROR"
-	ErrCodeServiceError  ErrorCode = "SERVICE_ERROR"
+	ErrCodeInternalError   ErrorCode = "INTERNAL_ERROR"
+	ErrCodeServiceError    ErrorCode = "SERVICE_ERROR"
+	ErrCodeFeatureDisabled ErrorCode = "FEATURE_DISABLED"
+
+	// Request lifecycle errors
+	ErrCodeRequestCancelled ErrorCode = "REQUEST_CANCELLED"
+
+	// Messaging errors
+	ErrCodeInvalidEvent ErrorCode = "INVALID_EVENT"
+	ErrCodeUnknownEvent ErrorCode = "UNKNOWN_EVENT"
 )
 
+// knownCodes lists every ErrorCode defined above
+var knownCodes = map[ErrorCode]bool{
+	ErrCodeUserNotFound:       true,
+	ErrCodeUserAlreadyExists:  true,
+	ErrCodeInvalidUserData:    true,
+	ErrCodeValidationFailed:   true,
+	ErrCodeInvalidEmail:       true,
+	ErrCodeInvalidName:        true,
+	ErrCodeInvalidPhone:       true,
+	ErrCodeInvalidRole:        true,
+	ErrCodeInvalidID:          true,
+	ErrCodePreconditionFailed: true,
+	ErrCodeRepositoryError:    true,
+	ErrCodeDatabaseError:      true,
+	ErrCodeInternalError:      true,
+	ErrCodeServiceError:       true,
+	ErrCodeFeatureDisabled:    true,
+	ErrCodeRequestCancelled:   true,
+	ErrCodeInvalidEvent:       true,
+	ErrCodeUnknownEvent:       true,
+}
+
+// IsKnown reports whether the code is one of the predefined error codes
+func (c ErrorCode) IsKnown() bool {
+	return knownCodes[c]
+}
+
+// retryableCodes lists the codes of transient failures, where repeating the
+// same operation may succeed
+var retryableCodes = map[ErrorCode]bool{
+	ErrCodeRepositoryError: true,
+	ErrCodeDatabaseError:   true,
+}
+
 // DomainError represents a domain-specific error with context
 type DomainError struct {
-	Code    ErrorCode
-	Message string
-	Cause   error
-	Context map[string]interface{}
+	Code      ErrorCode
+	Message   string
+	Cause     error
+	Context   map[string]interface{}
+	Retryable bool // whether retrying the operation may succeed
 }
 
 // Error implements the error interface
@@ -58,31 +106,51 @@ func (e *DomainError) Is(target error) bool {
 	return false
 }
 
-// WithContext adds context information to the error
+// WithContext returns a copy of the error with key set in its context. The
+// receiver is left untouched, so it is safe to call on the shared predefined
+// errors below.
 func (e *DomainError) WithContext(key string, value interface{}) *DomainError {
-	if e.Context == nil {
-		e.Context = make(map[string]interface{})
+	clone := e.clone()
+	clone.Context[key] = value
+	return clone
+}
+
+// WithRetryable returns a copy of the error with its retryability overridden
+func (e *DomainError) WithRetryable(retryable bool) *DomainError {
+	clone := e.clone()
+	clone.Retryable = retryable
+	return clone
+}
+
+// clone copies the error along with its context map
+func (e *DomainError) clone() *DomainError {
+	clone := *e
+	clone.Context = make(map[string]interface{}, len(e.Context)+1)
+	for key, value := range e.Context {
+		clone.Context[key] = value
 	}
-	e.Context[key] = value
-	return e
+	return &clone
 }
 
-// NewDomainError creates a new domain error
+// NewDomainError creates a new domain error, retryable if its code is
 func NewDomainError(code ErrorCode, message string) *DomainError {
 	return &DomainError{
-		Code:    code,
-		Message: message,
-		Context: make(map[string]interface{}),
+		Code:      code,
+		Message:   message,
+		Context:   make(map[string]interface{}),
+		Retryable: retryableCodes[code],
 	}
 }
 
-// NewDomainErrorWithCause creates a new domain error with an underlying cause
+// NewDomainErrorWithCause creates a new domain error with an underlying cause,
+// retryable if its code is
 func NewDomainErrorWithCause(code ErrorCode, message string, cause error) *DomainError {
 	return &DomainError{
-		Code:    code,
-		Message: message,
-		Cause:   cause,
-		Context: make(map[string]interface{}),
+		Code:      code,
+		Message:   message,
+		Cause:     cause,
+		Context:   make(map[string]interface{}),
+		Retryable: retryableCodes[code],
 	}
 }
 
@@ -94,13 +162,41 @@ var (
 	ErrValidationFailed  = NewDomainError(ErrCodeValidationFailed, "validation failed")
 	ErrInvalidEmail      = NewDomainError(ErrCodeInvalidEmail, "invalid email format")
 	ErrInvalidName       = NewDomainError(ErrCodeInvalidName, "invalid name")
+	ErrInvalidPhone      = NewDomainError(ErrCodeInvalidPhone, "phone must be in E.164 format, e.g. +14155552671")
+	ErrInvalidRole       = NewDomainError(ErrCodeInvalidRole, "role must be one of: admin, user")
 	ErrInvalidID         = NewDomainError(ErrCodeInvalidID, "invalid ID")
 	ErrRepositoryError   = NewDomainError(ErrCodeRepositoryError, "repository error")
 	ErrDatabaseError     = NewDomainError(ErrCodeDatabaseError, "database error")
 	ErrInternalError     = NewDomainError(ErrCodeInternalError, "internal error")
 	ErrServiceError      = NewDomainError(ErrCodeServiceError, "service error")
+	ErrRequestCancelled  = NewDomainError(ErrCodeRequestCancelled, "request cancelled")
+
+	ErrPreconditionFailed = NewDomainError(ErrCodePreconditionFailed, "resource has been modified")
 )
 
+// CodeOf returns the code of the first DomainError in err's chain
+func CodeOf(err error) (ErrorCode, bool) {
+	var domainErr *DomainError
+	if errors.As(err, &domainErr) {
+		return domainErr.Code, true
+	}
+	return "", false
+}
+
+// IsRetryable reports whether retrying the operation that returned err may
+// succeed. Errors that are not DomainErrors are treated as retryable, since
+// nothing is known about them.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	var domainErr *DomainError
+	if errors.As(err, &domainErr) {
+		return domainErr.Retryable
+	}
+	return true
+}
+
 // IsUserNotFound checks if the error is a user not found error
 func IsUserNotFound(err error) bool {
 	return errors.Is(err, ErrUserNotFound)
@@ -118,6 +214,8 @@ func IsValidationError(err error) bool {
 		return domainErr.Code == ErrCodeValidationFailed ||
 			domainErr.Code == ErrCodeInvalidEmail ||
 			domainErr.Code == ErrCodeInvalidName ||
+			domainErr.Code == ErrCodeInvalidPhone ||
+			domainErr.Code == ErrCodeInvalidRole ||
 			domainErr.Code == ErrCodeInvalidID ||
 			domainErr.Code == ErrCodeInvalidUserData
 	}
@@ -0,0 +1,109 @@
+package errors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// sensitiveContextKeys holds lower-cased context keys that are never serialized
+var (
+	sensitiveMu          sync.RWMutex
+	sensitiveContextKeys = map[string]bool{
+		"password":      true,
+		"token":         true,
+		"secret":        true,
+		"authorization": true,
+		"api_key":       true,
+	}
+)
+
+// SetSensitiveContextKeys replaces the deny-list of context keys omitted when
+// a DomainError is serialized. Keys are matched case-insensitively.
+func SetSensitiveContextKeys(keys ...string) {
+	denied := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if key = strings.ToLower(strings.TrimSpace(key)); key != "" {
+			denied[key] = true
+		}
+	}
+
+	sensitiveMu.Lock()
+	defer sensitiveMu.Unlock()
+	sensitiveContextKeys = denied
+}
+
+func isSensitiveContextKey(key string) bool {
+	sensitiveMu.RLock()
+	defer sensitiveMu.RUnlock()
+	return sensitiveContextKeys[strings.ToLower(key)]
+}
+
+// domainErrorJSON is the wire shape of a DomainError
+type domainErrorJSON struct {
+	Code      ErrorCode              `json:"code"`
+	Message   string                 `json:"message"`
+	Context   map[string]interface{} `json:"context,omitempty"`
+	Cause     string                 `json:"cause,omitempty"`
+	Retryable bool                   `json:"retryable"`
+}
+
+// SafeContext returns a copy of the context without deny-listed keys. Values
+// that cannot be encoded as JSON are replaced by their fmt representation.
+func (e *DomainError) SafeContext() map[string]interface{} {
+	if len(e.Context) == 0 {
+		return nil
+	}
+
+	safe := make(map[string]interface{}, len(e.Context))
+	for key, value := range e.Context {
+		if isSensitiveContextKey(key) {
+			continue
+		}
+		if _, err := json.Marshal(value); err != nil {
+			value = fmt.Sprint(value)
+		}
+		safe[key] = value
+	}
+	return safe
+}
+
+// MarshalJSON encodes the error as {code, message, context, cause, retryable},
+// with the cause rendered as a string and sensitive context keys omitted
+func (e *DomainError) MarshalJSON() ([]byte, error) {
+	body := domainErrorJSON{
+		Code:      e.Code,
+		Message:   e.Message,
+		Context:   e.SafeContext(),
+		Retryable: e.Retryable,
+	}
+	if e.Cause != nil {
+		body.Cause = e.Cause.Error()
+	}
+	return json.Marshal(body)
+}
+
+// UnmarshalJSON decodes the shape written by MarshalJSON. The cause comes
+// back as a plain error carrying the original message.
+func (e *DomainError) UnmarshalJSON(data []byte) error {
+	var body domainErrorJSON
+	if err := json.Unmarshal(data, &body); err != nil {
+		return err
+	}
+
+	*e = DomainError{
+		Code:      body.Code,
+		Message:   body.Message,
+		Context:   body.Context,
+		Retryable: body.Retryable,
+	}
+	if e.Context == nil {
+		e.Context = make(map[string]interface{})
+	}
+	if body.Cause != "" {
+		e.Cause = errors.New(body.Cause)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package errors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestDomainErrorJSONRoundTrip(t *testing.T) {
+	cause := fmt.Errorf("insert user: %w", errors.New("connection reset"))
+	original := NewDomainErrorWithCause(ErrCodeRepositoryError, "failed to save user", cause).
+		WithContext("email", "jane@example.com").
+		WithContext("password", "hunter2").
+		WithContext("Authorization", "Bearer abc").
+		WithContext("callback", func() {})
+
+	data, err := json.Marshal(original)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	var decoded DomainError
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	if decoded.Code != original.Code || decoded.Message != original.Message {
+		t.Errorf("decoded = %s %q, want %s %q", decoded.Code, decoded.Message, original.Code, original.Message)
+	}
+	if !decoded.Retryable {
+		t.Error("decoded error lost its retryable flag")
+	}
+	if decoded.Cause == nil || decoded.Cause.Error() != cause.Error() {
+		t.Errorf("decoded cause = %v, want %q", decoded.Cause, cause.Error())
+	}
+	if decoded.Context["email"] != "jane@example.com" {
+		t.Errorf("decoded email = %v, want jane@example.com", decoded.Context["email"])
+	}
+	for _, key := range []string{"password", "Authorization"} {
+		if _, ok := decoded.Context[key]; ok {
+			t.Errorf("deny-listed key %q was serialized", key)
+		}
+	}
+	if _, ok := decoded.Context["callback"].(string); !ok {
+		t.Errorf("non-JSON value = %#v, want its fmt representation", decoded.Context["callback"])
+	}
+}
+
+func TestSetSensitiveContextKeys(t *testing.T) {
+	t.Cleanup(func() {
+		SetSensitiveContextKeys("password", "token", "secret", "authorization", "api_key")
+	})
+	SetSensitiveContextKeys(" Email ")
+
+	safe := ErrUserAlreadyExists.WithContext("email", "jane@example.com").
+		WithContext("password", "hunter2").
+		SafeContext()
+
+	if _, ok := safe["email"]; ok {
+		t.Error("email was not omitted after being deny-listed")
+	}
+	if safe["password"] != "hunter2" {
+		t.Error("password was omitted after the deny-list was replaced")
+	}
+}
+
+func TestWithContextLeavesReceiverUntouched(t *testing.T) {
+	withEmail := ErrUserAlreadyExists.WithContext("email", "jane@example.com")
+
+	if len(ErrUserAlreadyExists.Context) != 0 {
+		t.Fatalf("predefined error context = %v, want empty", ErrUserAlreadyExists.Context)
+	}
+	if withEmail == ErrUserAlreadyExists {
+		t.Fatal("WithContext returned the receiver")
+	}
+	if !IsUserAlreadyExists(withEmail) {
+		t.Error("copy no longer matches the predefined error")
+	}
+
+	notRetryable := ErrRepositoryError.WithRetryable(false)
+	if !ErrRepositoryError.Retryable || notRetryable.Retryable {
+		t.Error("WithRetryable modified the receiver")
+	}
+}
+
+func TestWithContextConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := ErrUserNotFound.WithContext("id", i)
+			if err.Context["id"] != i {
+				t.Errorf("context id = %v, want %d", err.Context["id"], i)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,40 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Event is the envelope events are published in. Data holds the payload for
+// Type, which consumers decode with the handler registered for that type.
+// TraceID and SpanID identify the span the event originated in, so consumers
+// can link back to it.
+type Event struct {
+	Type       string          `json:"type"`
+	Version    int             `json:"version"`
+	Data       json.RawMessage `json:"data"`
+	TraceID    string          `json:"trace_id,omitempty"`
+	SpanID     string          `json:"span_id,omitempty"`
+	OccurredAt time.Time       `json:"occurred_at"`
+}
+
+// Payload is implemented by the events carried in an Event envelope
+type Payload interface {
+	EventType() string
+	EventVersion() int
+}
+
+// NewEvent wraps a payload in an envelope
+func NewEvent(payload Payload) (Event, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Event{}, fmt.Errorf("failed to encode %s payload: %w", payload.EventType(), err)
+	}
+	return Event{
+		Type:       payload.EventType(),
+		Version:    payload.EventVersion(),
+		Data:       data,
+		OccurredAt: time.Now().UTC(),
+	}, nil
+}
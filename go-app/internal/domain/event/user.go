@@ -0,0 +1,43 @@
+package event
+
+import (
+	"time"
+
+	"go-app/internal/domain/entity"
+)
+
+// Event types
+const (
+	TypeUserCreated = "UserCreated"
+)
+
+// Payload versions; bump when a payload changes incompatibly
+const (
+	VersionUserCreated = 1
+)
+
+// UserCreated is published after a user has been persisted
+type UserCreated struct {
+	Type      string    `json:"type"`
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// NewUserCreated creates a UserCreated event from a domain entity
+func NewUserCreated(user *entity.User) UserCreated {
+	return UserCreated{
+		Type:      TypeUserCreated,
+		ID:        int(user.ID()),
+		Name:      user.Name().String(),
+		Email:     user.Email().String(),
+		Timestamp: time.Now().UTC(),
+	}
+}
+
+// EventType implements Payload
+func (e UserCreated) EventType() string { return TypeUserCreated }
+
+// EventVersion implements Payload
+func (e UserCreated) EventVersion() int { return VersionUserCreated }
@@ -0,0 +1,68 @@
+package pagination
+
+import "sync/atomic"
+
+// Page size bounds used until SetLimits is called
+const (
+	defaultLimit = 10
+	maxLimit     = 100
+)
+
+// bounds holds the page size bounds shared by request validation and the
+// repositories
+type bounds struct {
+	defaultLimit int
+	maxLimit     int
+}
+
+var current atomic.Pointer[bounds]
+
+func init() {
+	current.Store(&bounds{defaultLimit: defaultLimit, maxLimit: maxLimit})
+}
+
+// SetLimits sets the page size used when a request sets none and the
+// largest page size accepted. Call it at startup, before serving requests.
+func SetLimits(defaultLimit, maxLimit int) {
+	current.Store(&bounds{defaultLimit: defaultLimit, maxLimit: maxLimit})
+}
+
+// DefaultLimit returns the page size used when a request sets none
+func DefaultLimit() int {
+	return current.Load().defaultLimit
+}
+
+// MaxLimit returns the largest page size accepted
+func MaxLimit() int {
+	return current.Load().maxLimit
+}
+
+// Normalize clamps limit to [1, MaxLimit] and offset to >= 0. An unset
+// (zero or negative) limit becomes DefaultLimit.
+func Normalize(limit, offset int) (int, int) {
+	b := current.Load()
+	switch {
+	case limit <= 0:
+		limit = b.defaultLimit
+	case limit > b.maxLimit:
+		limit = b.maxLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
+
+// Slice returns the page of items selected by limit and offset after
+// normalizing them. An offset past the end gives an empty page.
+func Slice[T any](items []T, limit, offset int) []T {
+	limit, offset = Normalize(limit, offset)
+	if offset >= len(items) {
+		return []T{}
+	}
+	end := len(items)
+	if limit < end-offset {
+		end = offset + limit
+	}
+	return items[offset:end]
+}
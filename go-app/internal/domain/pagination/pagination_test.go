@@ -0,0 +1,76 @@
+package pagination
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name                  string
+		limit, offset         int
+		wantLimit, wantOffset int
+	}{
+		{"zero limit", 0, 0, defaultLimit, 0},
+		{"negative limit", -5, 0, defaultLimit, 0},
+		{"minimum limit", 1, 0, 1, 0},
+		{"maximum limit", maxLimit, 0, maxLimit, 0},
+		{"limit above maximum", maxLimit + 1, 0, maxLimit, 0},
+		{"huge limit", math.MaxInt, 0, maxLimit, 0},
+		{"negative offset", 10, -1, 10, 0},
+		{"huge negative offset", 10, math.MinInt, 10, 0},
+		{"huge offset", 10, math.MaxInt, 10, math.MaxInt},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limit, offset := Normalize(tt.limit, tt.offset)
+			if limit != tt.wantLimit || offset != tt.wantOffset {
+				t.Errorf("Normalize(%d, %d) = %d, %d; want %d, %d",
+					tt.limit, tt.offset, limit, offset, tt.wantLimit, tt.wantOffset)
+			}
+		})
+	}
+}
+
+func TestSlice(t *testing.T) {
+	items := make([]int, 25)
+	for i := range items {
+		items[i] = i
+	}
+
+	tests := []struct {
+		name          string
+		limit, offset int
+		want          []int
+	}{
+		{"zero limit uses default", 0, 0, items[:defaultLimit]},
+		{"first page", 5, 0, items[:5]},
+		{"middle page", 5, 10, items[10:15]},
+		{"partial last page", 10, 20, items[20:]},
+		{"offset at end", 10, 25, []int{}},
+		{"offset past end", 10, 1000, []int{}},
+		{"huge offset", math.MaxInt, math.MaxInt, []int{}},
+		{"negative offset", 3, -10, items[:3]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Slice(items, tt.limit, tt.offset)
+			if got == nil || !slices.Equal(got, tt.want) {
+				t.Errorf("Slice(%d, %d) = %v, want %v", tt.limit, tt.offset, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetLimits(t *testing.T) {
+	t.Cleanup(func() { SetLimits(defaultLimit, maxLimit) })
+	SetLimits(20, 50)
+
+	if limit, _ := Normalize(0, 0); limit != 20 {
+		t.Errorf("Normalize(0, 0) limit = %d, want 20", limit)
+	}
+	if limit, _ := Normalize(500, 0); limit != 50 {
+		t.Errorf("Normalize(500, 0) limit = %d, want 50", limit)
+	}
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go-app/internal/domain/entity"
+)
+
+// Types of the entries in a user's activity history
+const (
+	ActivityUserCreated = "created"
+	ActivityUserUpdated = "updated"
+	ActivityUserDeleted = "deleted"
+)
+
+// UserActivity is one entry in a user's activity history
+type UserActivity struct {
+	ID         string // assigned by the repository, ordered by time
+	Type       string
+	TraceID    string // trace of the operation, empty when it was not traced
+	OccurredAt time.Time
+}
+
+// UserActivityRepository keeps a bounded, append-only history of each user's
+// lifecycle events. Once a history reaches the repository's cap, its oldest
+// entries are dropped.
+type UserActivityRepository interface {
+	// Append adds activity to the user's history and sets its ID
+	Append(ctx context.Context, userID entity.UserID, activity *UserActivity) error
+
+	// Recent returns up to limit of the user's newest entries, newest first
+	Recent(ctx context.Context, userID entity.UserID, limit int) ([]*UserActivity, error)
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+
+	"go-app/internal/domain/event"
+)
+
+// OutboxMessage is an event stored for publishing by the outbox relay
+type OutboxMessage struct {
+	ID       int64
+	Key      string
+	Event    event.Event
+	Attempts int
+
+	// TraceContext holds the propagation fields, such as traceparent, of the
+	// operation that stored the event
+	TraceContext map[string]string
+}
+
+// OutboxRepository stores events in the transaction of the change they
+// describe, so an event exists exactly when the change was committed, and
+// hands them to the relay in the order they were stored
+type OutboxRepository interface {
+	// Add stores message, joining the transaction in ctx
+	Add(ctx context.Context, message *OutboxMessage) error
+
+	// ClaimPending returns up to limit unpublished messages, oldest first.
+	// It must run within a transaction; only one transaction at a time can
+	// claim messages, and others get none until it ends.
+	ClaimPending(ctx context.Context, limit int) ([]*OutboxMessage, error)
+
+	// MarkPublished records the messages with the given IDs as published
+	MarkPublished(ctx context.Context, ids []int64) error
+
+	// MarkFailed records a failed publish attempt of a message
+	MarkFailed(ctx context.Context, id int64, cause error) error
+}
@@ -0,0 +1,11 @@
+package repository
+
+import "context"
+
+// TxManager runs work within a transaction. Repositories called with the
+// context passed to fn participate in the same transaction.
+type TxManager interface {
+	// WithinTx runs fn in a transaction, committing when fn returns nil and
+	// rolling back otherwise
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
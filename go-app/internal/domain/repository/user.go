@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"slices"
+	"strings"
 
 	"go-app/internal/domain/entity"
 	"go-app/internal/domain/errors"
@@ -12,6 +14,11 @@ type UserRepository interface {
 	// Create creates a new user
 	Create(ctx context.Context, user *entity.User) error
 
+	// CreateBatch creates several users in one operation. The returned slice
+	// holds one error per user, nil when that user was created; the second
+	// return value reports a failure of the whole batch.
+	CreateBatch(ctx context.Context, users []*entity.User) ([]error, error)
+
 	// GetByID retrieves a user by ID
 	GetByID(ctx context.Context, id entity.UserID) (*entity.User, error)
 
@@ -27,13 +34,54 @@ type UserRepository interface {
 	// Delete removes a user by ID
 	Delete(ctx context.Context, id entity.UserID) error
 
+	// DeleteByFilter removes every user matching filter and returns the IDs
+	// of the users removed. An empty filter is rejected rather than matching
+	// every user.
+	DeleteByFilter(ctx context.Context, filter UserFilter) ([]entity.UserID, error)
+
 	// ExistsByEmail checks if a user with the given email exists
 	ExistsByEmail(ctx context.Context, email entity.Email) (bool, error)
 
 	// Count returns the total number of users
 	Count(ctx context.Context) (int, error)
+
+	// Search retrieves users whose field contains query, case-insensitively,
+	// along with the total number of matches
+	Search(ctx context.Context, field, query string, limit, offset int) ([]*entity.User, int, error)
 }
 
+// UserFilter selects users for bulk operations. The criteria that are set
+// must all match.
+type UserFilter struct {
+	IDs         []entity.UserID
+	EmailDomain string // the part of the email after '@', matched case-insensitively
+}
+
+// IsEmpty reports whether the filter sets no criteria
+func (f UserFilter) IsEmpty() bool {
+	return len(f.IDs) == 0 && f.EmailDomain == ""
+}
+
+// Matches reports whether user meets every criterion of the filter
+func (f UserFilter) Matches(user *entity.User) bool {
+	if len(f.IDs) > 0 && !slices.Contains(f.IDs, user.ID()) {
+		return false
+	}
+	if f.EmailDomain != "" {
+		_, domain, _ := strings.Cut(user.Email().String(), "@")
+		if !strings.EqualFold(domain, f.EmailDomain) {
+			return false
+		}
+	}
+	return true
+}
+
+// Searchable user fields
+const (
+	SearchFieldName  = "name"
+	SearchFieldEmail = "email"
+)
+
 // Repository errors - these wrap the domain errors for repository-specific context
 var (
 	ErrUserNotFound      = errors.ErrUserNotFound
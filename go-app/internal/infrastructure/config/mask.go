@@ -0,0 +1,70 @@
+package config
+
+import (
+	"net/url"
+	"regexp"
+)
+
+// maskedValue replaces secrets in masked output
+const maskedValue = "***"
+
+// dsnPasswordPattern matches the password in key=value DSNs
+var dsnPasswordPattern = regexp.MustCompile(`(?i)(password\s*=\s*)('[^']*'|\S+)`)
+
+// MaskSecret hides a secret, keeping only whether it is set
+func MaskSecret(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return maskedValue
+}
+
+// MaskDSN hides the password in a URL or key=value connection string,
+// keeping the user, host and options readable
+func MaskDSN(dsn string) string {
+	if u, err := url.Parse(dsn); err == nil && u.Scheme != "" && u.Host != "" {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), maskedValue)
+		}
+		// Passwords may also be passed as a query parameter
+		if q := u.Query(); q.Has("password") {
+			q.Set("password", maskedValue)
+			u.RawQuery = q.Encode()
+		}
+		// Keep the mask readable instead of percent-encoding it
+		unescaped, err := url.PathUnescape(u.String())
+		if err != nil {
+			return u.String()
+		}
+		return unescaped
+	}
+	return dsnPasswordPattern.ReplaceAllString(dsn, "${1}"+maskedValue)
+}
+
+// Masked returns a copy of the config with passwords, keys and secrets hidden,
+// safe to log or return to operators
+func (c Config) Masked() Config {
+	c.Otel.Password = MaskSecret(c.Otel.Password)
+	c.Otel.Endpoint = MaskDSN(c.Otel.Endpoint)
+	c.Otel.TracesEndpoints = maskDSNs(c.Otel.TracesEndpoints)
+	c.Otel.MetricsEndpoints = maskDSNs(c.Otel.MetricsEndpoints)
+	c.Otel.LogsEndpoints = maskDSNs(c.Otel.LogsEndpoints)
+	c.Postgres.DSN = MaskDSN(c.Postgres.DSN)
+	c.Redis.Password = MaskSecret(c.Redis.Password)
+	c.Kafka.SASLPassword = MaskSecret(c.Kafka.SASLPassword)
+	c.Auth.APIKey = MaskSecret(c.Auth.APIKey)
+	c.Auth.JWTSecret = MaskSecret(c.Auth.JWTSecret)
+	return c
+}
+
+// maskDSNs masks each entry of a list of DSNs or URLs into a new slice
+func maskDSNs(dsns []string) []string {
+	if dsns == nil {
+		return nil
+	}
+	masked := make([]string, len(dsns))
+	for i, dsn := range dsns {
+		masked[i] = MaskDSN(dsn)
+	}
+	return masked
+}
@@ -0,0 +1,227 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// Validate checks enum settings, numeric ranges and the settings required by
+// enabled features, returning every problem found as one joined error
+func (c Config) Validate() error {
+	v := &validator{}
+
+	// OTel
+	v.oneOf("OTEL_EXPORTER_OTLP_PROTOCOL", c.Otel.Protocol, "", "http", "grpc")
+	v.oneOf("OTEL_EXPORTER_OTLP_COMPRESSION", c.Otel.ExporterCompression, "gzip", "none")
+	v.oneOf("OTEL_LOG_OUTPUT", strings.ToLower(c.Otel.LogOutput), "stdout", "stderr", "file", "otel")
+	v.oneOf("OTEL_LOG_FORMAT", strings.ToLower(c.Otel.LogFormat), "text", "json")
+	v.required("OTEL_SERVICE_NAME", c.Otel.ServiceName)
+	if c.Otel.EnableTraces || c.Otel.EnableMetrics || c.Otel.EnableLogs {
+		v.required("OTEL_EXPORTER_OTLP_ENDPOINT", c.Otel.Endpoint)
+	}
+	for _, setting := range []struct{ name, path string }{
+		{"OTEL_EXPORTER_OTLP_URL_PATH", c.Otel.ExporterURLPath},
+		{"OTEL_EXPORTER_OTLP_TRACES_URL_PATH", c.Otel.TracesURLPath},
+		{"OTEL_EXPORTER_OTLP_METRICS_URL_PATH", c.Otel.MetricsURLPath},
+		{"OTEL_EXPORTER_OTLP_LOGS_URL_PATH", c.Otel.LogsURLPath},
+	} {
+		if setting.path != "" && !strings.HasPrefix(setting.path, "/") {
+			v.fail("%s must start with /, got %q", setting.name, setting.path)
+		}
+	}
+	if port, err := strconv.Atoi(c.Otel.AppPort); err != nil || port < 1 || port > 65535 {
+		v.fail("APP_PORT must be a port number between 1 and 65535, got %q", c.Otel.AppPort)
+	}
+	v.positive("OTEL_EXPORT_INTERVAL_SECS", c.Otel.ExportIntervalSecs)
+	v.positive("OTEL_METRIC_EXPORT_INTERVAL_SECS", c.Otel.MetricExportIntervalSecs)
+	v.positive("OTEL_EXPORT_TIMEOUT_SECS", c.Otel.ExportTimeoutSecs)
+	v.positive("OTEL_MAX_QUEUE_SIZE", c.Otel.MaxQueueSize)
+	v.positive("OTEL_BATCH_TIMEOUT_SECS", c.Otel.BatchTimeoutSecs)
+	v.positive("OTEL_SHUTDOWN_TIMEOUT_SECS", c.Otel.ShutdownTimeoutSecs)
+	if c.Otel.ExporterRetryEnabled {
+		v.positive("OTEL_EXPORTER_RETRY_INITIAL_INTERVAL_SECS", c.Otel.ExporterRetryInitialIntervalSecs)
+		v.positive("OTEL_EXPORTER_RETRY_MAX_INTERVAL_SECS", c.Otel.ExporterRetryMaxIntervalSecs)
+		v.positive("OTEL_EXPORTER_RETRY_MAX_ELAPSED_SECS", c.Otel.ExporterRetryMaxElapsedSecs)
+	}
+	if c.Otel.Protocol == "grpc" {
+		// grpc-go raises shorter keepalive times to 10s anyway
+		if c.Otel.GRPCKeepaliveTimeSecs < 10 {
+			v.fail("OTEL_GRPC_KEEPALIVE_TIME_SECS must be at least 10, got %d", c.Otel.GRPCKeepaliveTimeSecs)
+		}
+		v.positive("OTEL_GRPC_KEEPALIVE_TIMEOUT_SECS", c.Otel.GRPCKeepaliveTimeoutSecs)
+		v.positive("OTEL_GRPC_BACKOFF_BASE_DELAY_MS", c.Otel.GRPCBackoffBaseDelayMs)
+		if c.Otel.GRPCBackoffMaxDelayMs < c.Otel.GRPCBackoffBaseDelayMs {
+			v.fail("OTEL_GRPC_BACKOFF_MAX_DELAY_MS must be at least OTEL_GRPC_BACKOFF_BASE_DELAY_MS, got %d", c.Otel.GRPCBackoffMaxDelayMs)
+		}
+		if c.Otel.GRPCBackoffMultiplier < 1 {
+			v.fail("OTEL_GRPC_BACKOFF_MULTIPLIER must be at least 1, got %g", c.Otel.GRPCBackoffMultiplier)
+		}
+		if c.Otel.GRPCBackoffJitter < 0 || c.Otel.GRPCBackoffJitter > 1 {
+			v.fail("OTEL_GRPC_BACKOFF_JITTER must be between 0 and 1, got %g", c.Otel.GRPCBackoffJitter)
+		}
+		v.positive("OTEL_GRPC_MIN_CONNECT_TIMEOUT_SECS", c.Otel.GRPCMinConnectTimeoutSecs)
+	}
+	if strings.EqualFold(c.Otel.LogOutput, "file") {
+		v.required("OTEL_LOG_FILE_PATH", c.Otel.LogFilePath)
+	}
+	v.nonNegative("OTEL_LOG_FILE_MAX_SIZE_MB", c.Otel.LogFileMaxSizeMB)
+	v.nonNegative("OTEL_LOG_FILE_MAX_AGE_DAYS", c.Otel.LogFileMaxAgeDays)
+	v.nonNegative("OTEL_LOG_FILE_MAX_BACKUPS", c.Otel.LogFileMaxBackups)
+	v.nonNegative("OTEL_SPAN_ATTRIBUTE_COUNT_LIMIT", c.Otel.SpanAttributeCountLimit)
+	v.nonNegative("OTEL_SPAN_EVENT_COUNT_LIMIT", c.Otel.SpanEventCountLimit)
+	if c.Otel.TraceSampleRatio < 0 || c.Otel.TraceSampleRatio > 1 {
+		v.fail("OTEL_TRACE_SAMPLE_RATIO must be between 0 and 1, got %g", c.Otel.TraceSampleRatio)
+	}
+	if c.Otel.SpanAttributeValueLengthLimit < -1 {
+		v.fail("OTEL_SPAN_ATTRIBUTE_VALUE_LENGTH_LIMIT must be -1 (unlimited) or greater, got %d", c.Otel.SpanAttributeValueLengthLimit)
+	}
+
+	// Kafka
+	if len(c.Kafka.Brokers) == 0 {
+		v.fail("KAFKA_BROKERS is required")
+	}
+	v.required("KAFKA_TOPIC", c.Kafka.Topic)
+	v.required("KAFKA_CONSUMER_GROUP", c.Kafka.ConsumerGroup)
+	v.positive("KAFKA_BATCH_SIZE", c.Kafka.BatchSize)
+	v.positive("KAFKA_DIAL_TIMEOUT", c.Kafka.DialTimeout)
+	v.nonNegative("KAFKA_CONN_IDLE_TIME", c.Kafka.ConnIdleTime)
+	v.nonNegative("KAFKA_MAX_RETRIES", c.Kafka.MaxRetries)
+	v.nonNegative("KAFKA_WORKERS", c.Kafka.Workers)
+	v.oneOf("KAFKA_REQUIRED_ACKS", strings.ToLower(c.Kafka.RequiredAcks), "all", "leader", "none")
+	if c.Kafka.Idempotent && !strings.EqualFold(c.Kafka.RequiredAcks, "all") {
+		v.fail("KAFKA_IDEMPOTENT requires KAFKA_REQUIRED_ACKS=all, got %q", c.Kafka.RequiredAcks)
+	}
+	if c.Kafka.TransactionalID != "" && !c.Kafka.Idempotent {
+		v.fail("KAFKA_TRANSACTIONAL_ID requires KAFKA_IDEMPOTENT=true")
+	}
+	if c.Kafka.OutboxEnabled {
+		v.positive("KAFKA_OUTBOX_POLL_INTERVAL_MS", c.Kafka.OutboxPollIntervalMs)
+		v.positive("KAFKA_OUTBOX_BATCH_SIZE", c.Kafka.OutboxBatchSize)
+		v.positive("KAFKA_OUTBOX_RETRY_BASE_DELAY_MS", c.Kafka.OutboxRetryBaseDelayMs)
+		if c.Kafka.OutboxRetryMaxDelayMs < c.Kafka.OutboxRetryBaseDelayMs {
+			v.fail("KAFKA_OUTBOX_RETRY_MAX_DELAY_MS must be at least KAFKA_OUTBOX_RETRY_BASE_DELAY_MS, got %d", c.Kafka.OutboxRetryMaxDelayMs)
+		}
+	}
+	v.oneOf("KAFKA_SASL_MECHANISM", strings.ToLower(c.Kafka.SASLMechanism), "", "plain", "scram-sha-256", "scram-sha-512")
+	if c.Kafka.SASLMechanism != "" {
+		v.required("KAFKA_SASL_USER", c.Kafka.SASLUser)
+		v.required("KAFKA_SASL_PASSWORD", c.Kafka.SASLPassword)
+	}
+
+	// Redis
+	v.required("REDIS_ADDR", c.Redis.Addr)
+	v.nonNegative("REDIS_DB", c.Redis.DB)
+	v.positive("REDIS_POOL_SIZE", c.Redis.PoolSize)
+	v.nonNegative("REDIS_MIN_IDLE_CONNS", c.Redis.MinIdleConns)
+	if c.Redis.CacheEnabled {
+		v.positive("REDIS_CACHE_TTL", c.Redis.CacheTTL)
+	}
+	v.nonNegative("REDIS_IDEMPOTENCY_TTL", c.Redis.IdempotencyTTL)
+	v.nonNegative("REDIS_CONNECT_RETRIES", c.Redis.ConnectRetries)
+	v.nonNegative("REDIS_CONNECT_RETRY_BACKOFF", c.Redis.ConnectRetryBackoff)
+	v.nonNegative("REDIS_MONITOR_INTERVAL", c.Redis.MonitorInterval)
+	if c.Redis.UserEventsEnabled {
+		v.positive("REDIS_USER_EVENTS_MAX_LEN", c.Redis.UserEventsMaxLen)
+	}
+
+	// Postgres
+	v.required("POSTGRES_DSN", c.Postgres.DSN)
+	v.nonNegative("POSTGRES_MAX_OPEN_CONNS", c.Postgres.MaxOpenConns)
+	v.nonNegative("POSTGRES_MAX_IDLE_CONNS", c.Postgres.MaxIdleConns)
+	v.nonNegative("POSTGRES_CONNECT_RETRIES", c.Postgres.ConnectRetries)
+	v.nonNegative("POSTGRES_CONNECT_RETRY_BACKOFF", c.Postgres.ConnectRetryBackoff)
+	v.nonNegative("POSTGRES_QUERY_TIMEOUT_SECS", c.Postgres.QueryTimeoutSecs)
+	v.nonNegative("POSTGRES_RETRY_MAX_ATTEMPTS", c.Postgres.RetryMaxAttempts)
+	v.nonNegative("POSTGRES_RETRY_BASE_DELAY_MS", c.Postgres.RetryBaseDelayMs)
+	v.nonNegative("POSTGRES_RETRY_MAX_DELAY_MS", c.Postgres.RetryMaxDelayMs)
+	if c.Postgres.RetryJitter < 0 || c.Postgres.RetryJitter > 1 {
+		v.fail("POSTGRES_RETRY_JITTER must be between 0 and 1, got %g", c.Postgres.RetryJitter)
+	}
+	v.oneOf("POSTGRES_RECORD_STATEMENT", strings.ToLower(c.Postgres.RecordStatement), "off", "operation_only", "full")
+
+	// Rate limiting
+	if c.RateLimit.Enabled {
+		v.positive("RATE_LIMIT_REQUESTS", c.RateLimit.Requests)
+		v.positive("RATE_LIMIT_WINDOW_SECS", c.RateLimit.WindowSecs)
+	}
+
+	// HTTP
+	for _, proxy := range c.HTTP.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			v.fail("HTTP_TRUSTED_PROXIES entry %q is not a CIDR or IP address", proxy)
+		}
+	}
+	if c.HTTP.PprofEnabled {
+		if _, _, err := net.SplitHostPort(c.HTTP.PprofAddr); err != nil {
+			v.fail("PPROF_ADDR must be a host:port address, got %q", c.HTTP.PprofAddr)
+		}
+	}
+	v.positive("HEALTH_CHECK_INTERVAL_MS", c.HTTP.HealthCheckIntervalMs)
+	v.positive("HEALTH_CHECK_TIMEOUT_MS", c.HTTP.HealthCheckTimeoutMs)
+	v.positive("DEFAULT_PAGE_SIZE", c.HTTP.DefaultPageSize)
+	v.positive("MAX_PAGE_SIZE", c.HTTP.MaxPageSize)
+	if c.HTTP.DefaultPageSize > c.HTTP.MaxPageSize {
+		v.fail("DEFAULT_PAGE_SIZE must be at most MAX_PAGE_SIZE (%d), got %d", c.HTTP.MaxPageSize, c.HTTP.DefaultPageSize)
+	}
+
+	// Authentication
+	switch strings.ToLower(c.Auth.Mode) {
+	case "", "none":
+	case "apikey":
+		v.required("AUTH_API_KEY", c.Auth.APIKey)
+		v.oneOf("AUTH_API_KEY_ROLE", strings.ToLower(c.Auth.APIKeyRole), "", "user", "admin")
+	case "jwt":
+		if c.Auth.JWTSecret == "" && c.Auth.JWKSURL == "" {
+			v.fail("AUTH_JWT_SECRET or AUTH_JWKS_URL is required when AUTH_MODE is jwt")
+		}
+	default:
+		v.fail("AUTH_MODE must be one of none, apikey, jwt, got %q", c.Auth.Mode)
+	}
+
+	return errors.Join(v.errs...)
+}
+
+// validator collects configuration problems
+type validator struct {
+	errs []error
+}
+
+func (v *validator) fail(format string, args ...interface{}) {
+	v.errs = append(v.errs, fmt.Errorf(format, args...))
+}
+
+func (v *validator) required(key, value string) {
+	if strings.TrimSpace(value) == "" {
+		v.fail("%s is required", key)
+	}
+}
+
+func (v *validator) positive(key string, value int) {
+	if value <= 0 {
+		v.fail("%s must be greater than 0, got %d", key, value)
+	}
+}
+
+func (v *validator) nonNegative(key string, value int) {
+	if value < 0 {
+		v.fail("%s must not be negative, got %d", key, value)
+	}
+}
+
+// oneOf checks value against allowed; an empty allowed entry means the
+// setting may be left unset
+func (v *validator) oneOf(key, value string, allowed ...string) {
+	var names []string
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+		if a != "" {
+			names = append(names, a)
+		}
+	}
+	v.fail("%s must be one of %s, got %q", key, strings.Join(names, ", "), value)
+}
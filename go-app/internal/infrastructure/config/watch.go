@@ -0,0 +1,129 @@
+package config
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadableFields are the settings applied on a config file change. Changes
+// to any other field are logged and ignored until the next restart.
+var reloadableFields = map[string]bool{
+	"Otel.LogVerbosity":     true,
+	"Otel.LogSampleEvery":   true,
+	"Otel.TraceSampleRatio": true,
+}
+
+// configFiles are the config files the last LoadConfig read: the base file
+// and, with APP_ENV, its overlay
+var configFiles []string
+
+// Watch re-reads the configuration whenever one of the config files changes,
+// the base file or the APP_ENV overlay, and calls apply with the updated
+// configuration. Only reloadable fields differ from current in what apply
+// receives; invalid configurations are not applied. Nothing is watched when
+// no config file was loaded.
+func Watch(current Config, apply func(Config)) {
+	if _, err := watchFiles(configFiles, current, apply); err != nil {
+		slog.Error("Failed to watch config files", "files", configFiles, "err", err)
+	}
+}
+
+// watchFiles runs Watch for the given files until the returned watcher is
+// closed. It returns a nil watcher when none of the files exist.
+func watchFiles(files []string, current Config, apply func(Config)) (*fsnotify.Watcher, error) {
+	watched := make(map[string]bool)
+	for _, file := range files {
+		if _, err := os.Stat(file); err == nil {
+			watched[filepath.Clean(file)] = true
+		}
+	}
+	if len(watched) == 0 {
+		return nil, nil
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	// The directories are watched rather than the files, so a file replaced
+	// by renaming another over it, as many editors save, is still seen
+	for file := range watched {
+		if err := watcher.Add(filepath.Dir(file)); err != nil {
+			_ = watcher.Close()
+			return nil, err
+		}
+	}
+
+	reload := func(file string) {
+		// LoadConfig reads the base file and the overlay again, so a change
+		// to either is merged the same way as at startup
+		next, err := LoadConfig()
+		if err == nil {
+			err = next.Validate()
+		}
+		if err != nil {
+			slog.Error("Ignoring config file change", "file", file, "err", err)
+			return
+		}
+
+		updated, ignored := mergeReloadable(current, next)
+		if len(ignored) > 0 {
+			slog.Warn("Config changes require a restart and were ignored", "fields", ignored)
+		}
+		if reflect.DeepEqual(updated, current) {
+			return
+		}
+		current = updated
+		slog.Info("Reloaded configuration", "file", file)
+		apply(current)
+	}
+
+	go func() {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if watched[filepath.Clean(event.Name)] && event.Op&(fsnotify.Write|fsnotify.Create) != 0 {
+					reload(event.Name)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				slog.Error("Config file watcher failed", "err", err)
+			}
+		}
+	}()
+	return watcher, nil
+}
+
+// mergeReloadable copies the reloadable fields of next into current and
+// returns the names of the other fields that differ
+func mergeReloadable(current, next Config) (Config, []string) {
+	var ignored []string
+	cur := reflect.ValueOf(&current).Elem()
+	nxt := reflect.ValueOf(next)
+	for i := 0; i < cur.NumField(); i++ {
+		section := cur.Type().Field(i).Name
+		curSection, nxtSection := cur.Field(i), nxt.Field(i)
+		for j := 0; j < curSection.NumField(); j++ {
+			name := section + "." + curSection.Type().Field(j).Name
+			curField, nxtField := curSection.Field(j), nxtSection.Field(j)
+			if reflect.DeepEqual(curField.Interface(), nxtField.Interface()) {
+				continue
+			}
+			if reloadableFields[name] {
+				curField.Set(nxtField)
+			} else {
+				ignored = append(ignored, name)
+			}
+		}
+	}
+	return current, ignored
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// writeConfig writes a YAML config file
+func writeConfig(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func TestWatchReloadsBaseAndOverlay(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	dir := t.TempDir()
+	base := filepath.Join(dir, "config.yaml")
+	overlay := filepath.Join(dir, "config.test.yaml")
+	writeConfig(t, base, "OTEL_SERVICE_NAME: test\nOTEL_LOG_VERBOSITY: 1\n")
+	writeConfig(t, overlay, "OTEL_TRACE_SAMPLE_RATIO: 0.5\n")
+	t.Setenv("CONFIG_FILE", base)
+	t.Setenv("APP_ENV", "test")
+
+	current, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if len(configFiles) != 2 {
+		t.Fatalf("config files = %v, want the base file and the overlay", configFiles)
+	}
+
+	applied := make(chan Config, 10)
+	watcher, err := watchFiles(configFiles, current, func(cfg Config) { applied <- cfg })
+	if err != nil {
+		t.Fatalf("watchFiles() error = %v", err)
+	}
+	t.Cleanup(func() { _ = watcher.Close() })
+
+	// waitFor returns the first applied configuration that satisfies ok
+	waitFor := func(what string, ok func(Config) bool) Config {
+		t.Helper()
+		timeout := time.After(5 * time.Second)
+		for {
+			select {
+			case cfg := <-applied:
+				if ok(cfg) {
+					return cfg
+				}
+			case <-timeout:
+				t.Fatalf("no reload after %s", what)
+			}
+		}
+	}
+
+	writeConfig(t, base, "OTEL_SERVICE_NAME: test\nOTEL_LOG_VERBOSITY: 3\n")
+	cfg := waitFor("changing the base file", func(cfg Config) bool { return cfg.Otel.LogVerbosity == 3 })
+	if cfg.Otel.TraceSampleRatio != 0.5 {
+		t.Errorf("TraceSampleRatio = %g after a base file change, want the overlay's 0.5", cfg.Otel.TraceSampleRatio)
+	}
+
+	writeConfig(t, overlay, "OTEL_TRACE_SAMPLE_RATIO: 0.25\n")
+	cfg = waitFor("changing the overlay", func(cfg Config) bool { return cfg.Otel.TraceSampleRatio == 0.25 })
+	if cfg.Otel.LogVerbosity != 3 {
+		t.Errorf("LogVerbosity = %d after an overlay change, want the base file's 3", cfg.Otel.LogVerbosity)
+	}
+}
@@ -0,0 +1,49 @@
+package kafka
+
+import (
+	"github.com/twmb/franz-go/pkg/kgo"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// RecordCarrier adapts kgo.Record headers to propagation.TextMapCarrier
+type RecordCarrier struct {
+	record *kgo.Record
+}
+
+// Compile-time check RecordCarrier implements propagation.TextMapCarrier
+var _ propagation.TextMapCarrier = (*RecordCarrier)(nil)
+
+// NewRecordCarrier creates a carrier backed by the record's headers
+func NewRecordCarrier(record *kgo.Record) *RecordCarrier {
+	return &RecordCarrier{record: record}
+}
+
+// Get returns the value of the first header with the given key
+func (c *RecordCarrier) Get(key string) string {
+	for _, h := range c.record.Headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+// Set replaces any existing header with the given key
+func (c *RecordCarrier) Set(key, value string) {
+	for i, h := range c.record.Headers {
+		if h.Key == key {
+			c.record.Headers[i].Value = []byte(value)
+			return
+		}
+	}
+	c.record.Headers = append(c.record.Headers, kgo.RecordHeader{Key: key, Value: []byte(value)})
+}
+
+// Keys returns the keys of all record headers
+func (c *RecordCarrier) Keys() []string {
+	keys := make([]string, len(c.record.Headers))
+	for i, h := range c.record.Headers {
+		keys[i] = h.Key
+	}
+	return keys
+}
@@ -2,15 +2,26 @@ package kafka
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"go-app/internal/infrastructure/config"
 	"go-app/internal/infrastructure/telemetry"
 
+	"github.com/twmb/franz-go/pkg/kerr"
 	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/sasl/plain"
+	"github.com/twmb/franz-go/pkg/sasl/scram"
 	"github.com/twmb/franz-go/plugin/kotel"
+	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -19,13 +30,24 @@ type Producer struct {
 	*kgo.Client
 	tracer trace.Tracer
 	tel    *telemetry.Telemetry
+
+	// txClient is a separate transactional client used by ProduceTransactional,
+	// since a transactional client can only produce inside a transaction
+	txClient *kgo.Client
+	txMu     sync.Mutex
 }
 
 // Consumer wraps kgo.Client for consuming messages
 type Consumer struct {
 	*kgo.Client
-	tracer trace.Tracer
-	tel    *telemetry.Telemetry
+	tracer       trace.Tracer
+	tel          *telemetry.Telemetry
+	manualCommit bool
+	workers      int
+
+	lag             *lagTracker
+	lagRegistration metric.Registration
+	fetchErrors     metric.Int64Counter
 }
 
 // NewProducer creates a new Kafka producer with best practices configuration
@@ -41,20 +63,46 @@ func NewProducer(cfg config.KafkaConfig, tel *telemetry.Telemetry) (*Producer, e
 		kgo.DialTimeout(time.Duration(cfg.DialTimeout) * time.Second),
 	}
 
+	ackOpts, err := ackOpts(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure Kafka producer: %w", err)
+	}
+	opts = append(opts, ackOpts...)
+
+	secOpts, err := securityOpts(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure Kafka producer: %w", err)
+	}
+	opts = append(opts, secOpts...)
+
 	client, err := kgo.NewClient(opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Kafka producer: %w", err)
 	}
 
+	var txClient *kgo.Client
+	if cfg.TransactionalID != "" {
+		txOpts := append(slices.Clone(opts), kgo.TransactionalID(cfg.TransactionalID))
+		txClient, err = kgo.NewClient(txOpts...)
+		if err != nil {
+			client.Close()
+			return nil, fmt.Errorf("failed to create transactional Kafka producer: %w", err)
+		}
+	}
+
 	telemetry.Log(context.Background(), telemetry.LevelInfo, "Successfully created Kafka producer", nil,
 		attribute.StringSlice("kafka.brokers", cfg.Brokers),
 		attribute.String("kafka.topic", cfg.Topic),
+		attribute.String("kafka.acks", cfg.RequiredAcks),
+		attribute.Bool("kafka.idempotent", cfg.Idempotent),
+		attribute.Bool("kafka.transactional", txClient != nil),
 	)
 
 	return &Producer{
-		Client: client,
-		tracer: tel.Tracer,
-		tel:    tel,
+		Client:   client,
+		tracer:   tel.Tracer,
+		tel:      tel,
+		txClient: txClient,
 	}, nil
 }
 
@@ -74,6 +122,15 @@ func NewConsumer(cfg config.KafkaConfig, groupID string, tel *telemetry.Telemetr
 		kgo.ConnIdleTimeout(time.Duration(cfg.ConnIdleTime) * time.Second),
 		kgo.DialTimeout(time.Duration(cfg.DialTimeout) * time.Second),
 	}
+	if cfg.ManualCommit {
+		opts = append(opts, kgo.DisableAutoCommit())
+	}
+
+	secOpts, err := securityOpts(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure Kafka consumer: %w", err)
+	}
+	opts = append(opts, secOpts...)
 
 	client, err := kgo.NewClient(opts...)
 	if err != nil {
@@ -84,18 +141,100 @@ func NewConsumer(cfg config.KafkaConfig, groupID string, tel *telemetry.Telemetr
 		attribute.StringSlice("kafka.brokers", cfg.Brokers),
 		attribute.String("kafka.topic", cfg.Topic),
 		attribute.String("kafka.consumer_group", groupID),
+		attribute.Bool("kafka.manual_commit", cfg.ManualCommit),
+		attribute.Int("kafka.workers", cfg.Workers),
 	)
 
-	return &Consumer{
-		Client: client,
-		tracer: tel.Tracer,
-		tel:    tel,
-	}, nil
+	consumer := &Consumer{
+		Client:       client,
+		tracer:       tel.Tracer,
+		tel:          tel,
+		manualCommit: cfg.ManualCommit,
+		workers:      cfg.Workers,
+		lag:          newLagTracker(),
+	}
+
+	if err := consumer.registerLagGauge(tel.Meter); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to register Kafka consumer lag gauge: %w", err)
+	}
+
+	consumer.fetchErrors, err = tel.Meter.Int64Counter("kafka.fetch.errors.total",
+		metric.WithDescription("Number of errors returned by Kafka fetches"),
+		metric.WithUnit("{error}"))
+	if err != nil {
+		consumer.Close()
+		return nil, fmt.Errorf("failed to create Kafka fetch error counter: %w", err)
+	}
+
+	return consumer, nil
+}
+
+// securityOpts translates the SASL and TLS settings into client options.
+// No options are returned for plaintext brokers.
+func securityOpts(cfg config.KafkaConfig) ([]kgo.Opt, error) {
+	var opts []kgo.Opt
+
+	if cfg.TLSEnabled {
+		opts = append(opts, kgo.DialTLSConfig(&tls.Config{MinVersion: tls.VersionTLS12}))
+	}
+
+	if cfg.SASLMechanism != "" && (cfg.SASLUser == "" || cfg.SASLPassword == "") {
+		return nil, fmt.Errorf("SASL mechanism %q requires a username and password", cfg.SASLMechanism)
+	}
+
+	switch strings.ToLower(cfg.SASLMechanism) {
+	case "":
+	case "plain":
+		opts = append(opts, kgo.SASL(plain.Auth{
+			User: cfg.SASLUser,
+			Pass: cfg.SASLPassword,
+		}.AsMechanism()))
+	case "scram-sha-256":
+		opts = append(opts, kgo.SASL(scram.Auth{
+			User: cfg.SASLUser,
+			Pass: cfg.SASLPassword,
+		}.AsSha256Mechanism()))
+	case "scram-sha-512":
+		opts = append(opts, kgo.SASL(scram.Auth{
+			User: cfg.SASLUser,
+			Pass: cfg.SASLPassword,
+		}.AsSha512Mechanism()))
+	default:
+		return nil, fmt.Errorf("unsupported SASL mechanism %q (expected plain, scram-sha-256 or scram-sha-512)", cfg.SASLMechanism)
+	}
+
+	return opts, nil
+}
+
+// ackOpts maps the configured acks and idempotence to producer options.
+// Idempotent writes need acknowledgements from all in-sync replicas.
+func ackOpts(cfg config.KafkaConfig) ([]kgo.Opt, error) {
+	var opts []kgo.Opt
+
+	switch strings.ToLower(cfg.RequiredAcks) {
+	case "", "all":
+		opts = append(opts, kgo.RequiredAcks(kgo.AllISRAcks()))
+	case "leader":
+		opts = append(opts, kgo.RequiredAcks(kgo.LeaderAck()))
+	case "none":
+		opts = append(opts, kgo.RequiredAcks(kgo.NoAck()))
+	default:
+		return nil, fmt.Errorf("unsupported required acks %q (expected all, leader or none)", cfg.RequiredAcks)
+	}
+
+	if !cfg.Idempotent {
+		opts = append(opts, kgo.DisableIdempotentWrite())
+	} else if cfg.RequiredAcks != "" && !strings.EqualFold(cfg.RequiredAcks, "all") {
+		return nil, fmt.Errorf("idempotent writes require acks all, got %q", cfg.RequiredAcks)
+	}
+
+	return opts, nil
 }
 
 // ProduceWithTracing produces a message with tracing and error handling
 func (p *Producer) ProduceWithTracing(ctx context.Context, topic string, key, value []byte) error {
-	ctx, span := p.tracer.Start(ctx, "kafka.produce")
+	ctx, span := p.tracer.Start(ctx, "kafka.produce", trace.WithSpanKind(trace.SpanKindProducer))
 	defer span.End()
 
 	span.SetAttributes(
@@ -110,6 +249,9 @@ func (p *Producer) ProduceWithTracing(ctx context.Context, topic string, key, va
 		Value: value,
 	}
 
+	// Inject trace context so consumers can continue the trace
+	otel.GetTextMapPropagator().Inject(ctx, NewRecordCarrier(record))
+
 	// Produce asynchronously with callback
 	results := p.ProduceSync(ctx, record)
 	err := results.FirstErr()
@@ -127,10 +269,127 @@ func (p *Producer) ProduceWithTracing(ctx context.Context, topic string, key, va
 	return nil
 }
 
-// ConsumeWithTracing consumes messages with tracing and error handling
-func (c *Consumer) ConsumeWithTracing(ctx context.Context, handler func(ctx context.Context, record *kgo.Record) error) error {
+// ProduceBatchWithTracing produces key/value pairs in a single ProduceSync call
+// under one span, returning the joined per-record errors
+func (p *Producer) ProduceBatchWithTracing(ctx context.Context, topic string, records [][2][]byte) error {
+	ctx, span := p.tracer.Start(ctx, "kafka.produce_batch", trace.WithSpanKind(trace.SpanKindProducer))
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("kafka.topic", topic),
+		attribute.String("kafka.operation", "produce_batch"),
+		attribute.Int("kafka.batch_size", len(records)),
+	)
+
+	if len(records) == 0 {
+		return nil
+	}
+
+	propagator := otel.GetTextMapPropagator()
+	batch := make([]*kgo.Record, len(records))
+	for i, kv := range records {
+		batch[i] = &kgo.Record{
+			Topic: topic,
+			Key:   kv[0],
+			Value: kv[1],
+		}
+		// Inject trace context so consumers can continue the trace
+		propagator.Inject(ctx, NewRecordCarrier(batch[i]))
+	}
+
+	results := p.ProduceSync(ctx, batch...)
+
+	var errs []error
+	for _, result := range results {
+		if result.Err != nil {
+			errs = append(errs, result.Err)
+		}
+	}
+	if len(errs) > 0 {
+		span.SetAttributes(
+			attribute.Bool("kafka.error", true),
+			attribute.Int("kafka.failed_count", len(errs)),
+		)
+		return fmt.Errorf("failed to produce %d of %d messages: %w", len(errs), len(records), errors.Join(errs...))
+	}
+
+	span.SetAttributes(attribute.Bool("kafka.success", true))
+	telemetry.Log(ctx, telemetry.LevelInfo, "Message batch produced successfully", nil,
+		attribute.String("kafka.topic", topic),
+		attribute.Int("kafka.batch_size", len(records)),
+	)
+
+	return nil
+}
+
+// Dead-letter headers added to records routed to the dead-letter topic
+const (
+	HeaderDLQError             = "dlq.error"
+	HeaderDLQOriginalTopic     = "dlq.original_topic"
+	HeaderDLQOriginalPartition = "dlq.original_partition"
+	HeaderDLQOriginalOffset    = "dlq.original_offset"
+)
+
+// ProduceToDeadLetter produces a failed record to the dead-letter topic, preserving
+// the original key, value and headers and recording the failure cause and origin
+func (p *Producer) ProduceToDeadLetter(ctx context.Context, topic string, original *kgo.Record, cause error) error {
+	ctx, span := p.tracer.Start(ctx, "kafka.produce_dead_letter", trace.WithSpanKind(trace.SpanKindProducer))
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("kafka.topic", topic),
+		attribute.String("kafka.operation", "produce_dead_letter"),
+		attribute.String("kafka.original_topic", original.Topic),
+		attribute.Int("kafka.original_partition", int(original.Partition)),
+		attribute.Int64("kafka.original_offset", original.Offset),
+	)
+
+	headers := make([]kgo.RecordHeader, 0, len(original.Headers)+4)
+	headers = append(headers, original.Headers...)
+	headers = append(headers,
+		kgo.RecordHeader{Key: HeaderDLQError, Value: []byte(cause.Error())},
+		kgo.RecordHeader{Key: HeaderDLQOriginalTopic, Value: []byte(original.Topic)},
+		kgo.RecordHeader{Key: HeaderDLQOriginalPartition, Value: []byte(strconv.Itoa(int(original.Partition)))},
+		kgo.RecordHeader{Key: HeaderDLQOriginalOffset, Value: []byte(strconv.FormatInt(original.Offset, 10))},
+	)
+
+	record := &kgo.Record{
+		Topic:   topic,
+		Key:     original.Key,
+		Value:   original.Value,
+		Headers: headers,
+	}
+
+	if err := p.ProduceSync(ctx, record).FirstErr(); err != nil {
+		span.SetAttributes(attribute.Bool("kafka.error", true))
+		return fmt.Errorf("failed to produce dead-letter message: %w", err)
+	}
+
+	span.SetAttributes(attribute.Bool("kafka.success", true))
+	return nil
+}
+
+// fetcher is the part of the kgo client the consume loop uses
+type fetcher interface {
+	PollFetches(ctx context.Context) kgo.Fetches
+	CommitRecords(ctx context.Context, rs ...*kgo.Record) error
+	SetOffsets(setOffsets map[string]map[int32]kgo.EpochOffset)
+}
+
+// ConsumeWithTracing consumes messages with tracing and error handling.
+// Polling stops once ctx is done. Records already fetched are handled on
+// handleCtx instead, which should outlive ctx so they can finish and be
+// committed; cancel it to abort them.
+func (c *Consumer) ConsumeWithTracing(ctx, handleCtx context.Context, handler RecordHandler) error {
+	return c.consume(ctx, handleCtx, c.Client, handler)
+}
+
+// consume runs the poll, handle and commit loop of ConsumeWithTracing against client
+func (c *Consumer) consume(ctx, handleCtx context.Context, client fetcher, handler RecordHandler) error {
 	ctx, span := c.tracer.Start(ctx, "kafka.consume")
 	defer span.End()
+	// Record spans still link to the consume span when handled on handleCtx
+	handleCtx = trace.ContextWithSpan(handleCtx, span)
 
 	for {
 		select {
@@ -138,16 +397,31 @@ func (c *Consumer) ConsumeWithTracing(ctx context.Context, handler func(ctx cont
 			telemetry.Log(ctx, telemetry.LevelInfo, "Kafka consumer shutting down", nil)
 			return ctx.Err()
 		default:
-			fetches := c.PollFetches(ctx)
+			fetches := client.PollFetches(ctx)
 
 			if fetches.IsClientClosed() {
 				telemetry.Log(ctx, telemetry.LevelInfo, "Kafka client closed, consumer stopping", nil)
 				return nil
 			}
 
-			// Check for errors
-			if errs := fetches.Errors(); len(errs) > 0 {
-				continue
+			c.lag.observe(fetches)
+
+			// Check for errors. Records fetched from healthy partitions are
+			// still processed below. When shutting down, the errors are only
+			// the cancelled poll, and the records fetched with it are drained.
+			if errs := fetches.Errors(); len(errs) > 0 && ctx.Err() == nil {
+				if err := c.handleFetchErrors(ctx, errs); err != nil {
+					span.RecordError(err)
+					return err
+				}
+				if fetches.NumRecords() == 0 {
+					// Back off so an unavailable broker does not cause a hot loop
+					select {
+					case <-ctx.Done():
+					case <-time.After(fetchErrorBackoff):
+					}
+					continue
+				}
 			}
 
 			// Check if there are no records
@@ -155,23 +429,45 @@ func (c *Consumer) ConsumeWithTracing(ctx context.Context, handler func(ctx cont
 				continue // No records, try again
 			}
 
-			var processedCount int
-			fetches.EachRecord(func(record *kgo.Record) {
-				recordCtx, recordSpan := c.tracer.Start(ctx, "kafka.process_record")
-				recordSpan.SetAttributes(
-					attribute.String("kafka.topic", record.Topic),
-					attribute.Int64("kafka.offset", record.Offset),
-					attribute.Int("kafka.partition", int(record.Partition)),
-				)
+			records := fetches.Records()
+			results := c.processRecords(handleCtx, records, handler)
 
-				if err := handler(recordCtx, record); err != nil {
-					recordSpan.SetAttributes(attribute.Bool("kafka.processing_error", true))
-				} else {
-					processedCount++
+			var processedCount int
+			var committable []*kgo.Record
+			// First failed record per partition; later records there are not
+			// committed so the failed one is redelivered
+			failed := make(map[string]map[int32]kgo.EpochOffset)
+			for i, record := range records {
+				if results[i] != nil {
+					if failed[record.Topic] == nil {
+						failed[record.Topic] = make(map[int32]kgo.EpochOffset)
+					}
+					if _, ok := failed[record.Topic][record.Partition]; !ok {
+						failed[record.Topic][record.Partition] = kgo.EpochOffset{Epoch: record.LeaderEpoch, Offset: record.Offset}
+					}
+					continue
+				}
+				processedCount++
+				if _, ok := failed[record.Topic][record.Partition]; !ok {
+					committable = append(committable, record)
 				}
+			}
 
-				recordSpan.End()
-			})
+			if c.manualCommit {
+				if len(committable) > 0 {
+					// Commit even when shutting down, so the records handled
+					// while draining are not redelivered
+					if err := client.CommitRecords(context.WithoutCancel(ctx), committable...); err != nil {
+						telemetry.Log(ctx, telemetry.LevelError, "Failed to commit Kafka offsets", err,
+							attribute.Int("kafka.commit_count", len(committable)),
+						)
+					}
+				}
+				// Rewind partitions with failures so the failed records are fetched again
+				if len(failed) > 0 {
+					client.SetOffsets(failed)
+				}
+			}
 
 			if processedCount > 0 {
 				telemetry.Log(ctx, telemetry.LevelInfo, "Processed Kafka messages", nil,
@@ -182,47 +478,97 @@ func (c *Consumer) ConsumeWithTracing(ctx context.Context, handler func(ctx cont
 	}
 }
 
-// HealthCheck performs a health check on the Kafka connection
+// fetchErrorBackoff is how long the consumer waits after a poll that returned
+// only errors
+const fetchErrorBackoff = time.Second
+
+// handleFetchErrors logs and counts fetch errors, returning the first fatal one
+func (c *Consumer) handleFetchErrors(ctx context.Context, errs []kgo.FetchError) error {
+	var fatal error
+	for _, fe := range errs {
+		retriable := !isFatalFetchError(fe.Err)
+		attrs := []attribute.KeyValue{
+			attribute.String("topic", fe.Topic),
+			attribute.Int("partition", int(fe.Partition)),
+			attribute.Bool("kafka.retriable", retriable),
+		}
+		c.fetchErrors.Add(ctx, 1, metric.WithAttributes(attrs...))
+		telemetry.Log(ctx, telemetry.LevelWarn, "Kafka fetch error", fe.Err,
+			append(attrs, attribute.String("error", fe.Err.Error()))...,
+		)
+		if !retriable && fatal == nil {
+			fatal = fmt.Errorf("fatal Kafka fetch error on %s[%d]: %w", fe.Topic, fe.Partition, fe.Err)
+		}
+	}
+	return fatal
+}
+
+// isFatalFetchError reports whether a fetch error will not resolve by
+// polling again, such as missing authorization. Everything else, including
+// network errors and retriable broker errors, is retried.
+func isFatalFetchError(err error) bool {
+	var kafkaErr *kerr.Error
+	if !errors.As(err, &kafkaErr) {
+		return false
+	}
+	switch kafkaErr {
+	case kerr.TopicAuthorizationFailed,
+		kerr.GroupAuthorizationFailed,
+		kerr.ClusterAuthorizationFailed,
+		kerr.SaslAuthenticationFailed,
+		kerr.UnsupportedSaslMechanism,
+		kerr.UnsupportedVersion:
+		return true
+	}
+	return false
+}
+
+// healthCheckTimeout bounds how long a health check waits for a broker
+const healthCheckTimeout = 5 * time.Second
+
+// HealthCheck performs a health check on the Kafka producer connection
 func (p *Producer) HealthCheck(ctx context.Context) error {
-	ctx, span := p.tracer.Start(ctx, "kafka.health_check")
-	defer span.End()
+	return healthCheck(ctx, p.Client, p.tracer)
+}
 
-	// Simple ping by trying to get broker metadata
-	results := make(chan error, 1)
-	go func() {
-		defer close(results)
-		// Try a lightweight operation to check connectivity
-		testRecord := &kgo.Record{
-			Topic: "health-check-topic",
-			Key:   []byte("health-check"),
-			Value: []byte("ping"),
-		}
+// HealthCheck performs a health check on the Kafka consumer connection
+func (c *Consumer) HealthCheck(ctx context.Context) error {
+	return healthCheck(ctx, c.Client, c.tracer)
+}
 
-		produceResults := p.ProduceSync(ctx, testRecord)
-		results <- produceResults.FirstErr()
-	}()
+// healthCheck verifies broker connectivity with a metadata request, without
+// producing any data
+func healthCheck(ctx context.Context, client *kgo.Client, tracer trace.Tracer) error {
+	ctx, span := tracer.Start(ctx, "kafka.health_check")
+	defer span.End()
 
-	select {
-	case err := <-results:
-		if err != nil {
-			span.SetAttributes(attribute.Bool("kafka.healthy", false))
-			return fmt.Errorf("kafka health check failed: %w", err)
-		}
-	case <-time.After(5 * time.Second):
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	if err := client.Ping(ctx); err != nil {
 		span.SetAttributes(attribute.Bool("kafka.healthy", false))
-		return fmt.Errorf("kafka health check timed out")
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("kafka health check timed out")
+		}
+		return fmt.Errorf("kafka health check failed: %w", err)
 	}
 
 	span.SetAttributes(attribute.Bool("kafka.healthy", true))
 	return nil
 }
 
-// Close closes the Kafka client
+// Close closes the Kafka clients
 func (p *Producer) Close() {
+	if p.txClient != nil {
+		p.txClient.Close()
+	}
 	p.Client.Close()
 }
 
 // Close closes the Kafka client
 func (c *Consumer) Close() {
+	if c.lagRegistration != nil {
+		_ = c.lagRegistration.Unregister()
+	}
 	c.Client.Close()
 }
@@ -0,0 +1,258 @@
+package kafka
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/sasl"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"go-app/internal/infrastructure/config"
+)
+
+// fakeFetcher returns its batches one per poll, then blocks until the poll's
+// context is done. SetOffsets queues the records of log from the new offsets
+// again, as the broker would return them on the next fetch.
+type fakeFetcher struct {
+	mu        sync.Mutex
+	batches   [][]*kgo.Record
+	log       []*kgo.Record
+	committed []*kgo.Record
+	rewinds   []map[string]map[int32]kgo.EpochOffset
+	// idle, when set, is closed by the first poll that finds no batch queued
+	idle chan struct{}
+}
+
+func (f *fakeFetcher) PollFetches(ctx context.Context) kgo.Fetches {
+	f.mu.Lock()
+	if len(f.batches) > 0 {
+		batch := f.batches[0]
+		f.batches = f.batches[1:]
+		f.mu.Unlock()
+		return kgo.Fetches{{Topics: []kgo.FetchTopic{{
+			Topic:      batch[0].Topic,
+			Partitions: []kgo.FetchPartition{{Partition: batch[0].Partition, Records: batch}},
+		}}}}
+	}
+	if f.idle != nil {
+		close(f.idle)
+		f.idle = nil
+	}
+	f.mu.Unlock()
+
+	<-ctx.Done()
+	return kgo.NewErrFetch(ctx.Err())
+}
+
+func (f *fakeFetcher) CommitRecords(_ context.Context, rs ...*kgo.Record) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.committed = append(f.committed, rs...)
+	return nil
+}
+
+func (f *fakeFetcher) SetOffsets(offsets map[string]map[int32]kgo.EpochOffset) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rewinds = append(f.rewinds, offsets)
+	var batch []*kgo.Record
+	for _, record := range f.log {
+		if offset, ok := offsets[record.Topic][record.Partition]; ok && record.Offset >= offset.Offset {
+			batch = append(batch, record)
+		}
+	}
+	if len(batch) > 0 {
+		f.batches = append(f.batches, batch)
+	}
+}
+
+// committedOffset returns the offset committed for a partition, the one
+// after its last committed record, or -1 when nothing was committed
+func (f *fakeFetcher) committedOffset(topic string, partition int32) int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	offset := int64(-1)
+	for _, record := range f.committed {
+		if record.Topic == topic && record.Partition == partition {
+			offset = max(offset, record.Offset+1)
+		}
+	}
+	return offset
+}
+
+func TestConsumeDrainsInFlightRecordsOnStop(t *testing.T) {
+	for _, workers := range []int{1, 2} {
+		t.Run(fmt.Sprintf("workers=%d", workers), func(t *testing.T) {
+			record := &kgo.Record{Topic: "user-events", Partition: 0, Offset: 7, Key: []byte("1")}
+			client := &fakeFetcher{batches: [][]*kgo.Record{{record}}}
+			consumer := &Consumer{
+				tracer:       noop.NewTracerProvider().Tracer("test"),
+				manualCommit: true,
+				workers:      workers,
+				lag:          newLagTracker(),
+			}
+
+			started := make(chan struct{})
+			release := make(chan struct{})
+			var handlerErr error
+			handler := func(ctx context.Context, _ *kgo.Record) error {
+				close(started)
+				<-release
+				handlerErr = ctx.Err()
+				return handlerErr
+			}
+
+			pollCtx, stop := context.WithCancel(context.Background())
+			done := make(chan error, 1)
+			go func() {
+				done <- consumer.consume(pollCtx, context.Background(), client, handler)
+			}()
+
+			<-started
+			// Stop polling while the record is being handled
+			stop()
+			close(release)
+
+			select {
+			case err := <-done:
+				if !errors.Is(err, context.Canceled) {
+					t.Fatalf("consume() error = %v, want context.Canceled", err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("consume() did not return after stopping")
+			}
+
+			if handlerErr != nil {
+				t.Errorf("in-flight handler saw %v, want its context alive", handlerErr)
+			}
+			if len(client.committed) != 1 || client.committed[0] != record {
+				t.Errorf("committed = %v, want the in-flight record", client.committed)
+			}
+		})
+	}
+}
+
+func TestConsumeManualCommitRedeliversFailedRecords(t *testing.T) {
+	records := make([]*kgo.Record, 3)
+	for i := range records {
+		records[i] = &kgo.Record{Topic: "user-events", Partition: 0, Offset: int64(i), Key: []byte("1")}
+	}
+	idle := make(chan struct{})
+	client := &fakeFetcher{batches: [][]*kgo.Record{records}, log: records, idle: idle}
+	consumer := &Consumer{
+		tracer:       noop.NewTracerProvider().Tracer("test"),
+		manualCommit: true,
+		workers:      1,
+		lag:          newLagTracker(),
+	}
+
+	// The record at offset 1 fails once, then succeeds when redelivered
+	var handled []int64
+	failed := false
+	offsetAtRetry := int64(-1)
+	handler := func(_ context.Context, record *kgo.Record) error {
+		handled = append(handled, record.Offset)
+		if record.Offset != 1 {
+			return nil
+		}
+		if !failed {
+			failed = true
+			return errors.New("handler failed")
+		}
+		offsetAtRetry = client.committedOffset("user-events", 0)
+		return nil
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- consumer.consume(ctx, context.Background(), client, handler)
+	}()
+
+	select {
+	case <-idle:
+	case <-time.After(5 * time.Second):
+		t.Fatal("consume() did not process the redelivered records")
+	}
+	stop()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("consume() did not return after stopping")
+	}
+
+	if len(client.rewinds) != 1 || client.rewinds[0]["user-events"][0].Offset != 1 {
+		t.Errorf("SetOffsets calls = %v, want one rewind of user-events[0] to offset 1", client.rewinds)
+	}
+	if offsetAtRetry != 1 {
+		t.Errorf("committed offset after the failure = %d, want 1, left at the failed record", offsetAtRetry)
+	}
+	if want := []int64{0, 1, 2, 1, 2}; !slices.Equal(handled, want) {
+		t.Errorf("handled offsets = %v, want %v", handled, want)
+	}
+	if got := client.committedOffset("user-events", 0); got != 3 {
+		t.Errorf("committed offset = %d, want 3, one past the last record", got)
+	}
+}
+
+func TestSecurityOpts(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       config.KafkaConfig
+		wantErr   bool
+		wantTLS   bool
+		wantMechs []string
+	}{
+		{"plaintext", config.KafkaConfig{}, false, false, nil},
+		{"tls", config.KafkaConfig{TLSEnabled: true}, false, true, nil},
+		{"plain", config.KafkaConfig{SASLMechanism: "plain", SASLUser: "app", SASLPassword: "secret"}, false, false, []string{"PLAIN"}},
+		{"scram-sha-256 over tls", config.KafkaConfig{SASLMechanism: "SCRAM-SHA-256", SASLUser: "app", SASLPassword: "secret", TLSEnabled: true}, false, true, []string{"SCRAM-SHA-256"}},
+		{"scram-sha-512", config.KafkaConfig{SASLMechanism: "scram-sha-512", SASLUser: "app", SASLPassword: "secret"}, false, false, []string{"SCRAM-SHA-512"}},
+		{"unknown mechanism", config.KafkaConfig{SASLMechanism: "gssapi", SASLUser: "app", SASLPassword: "secret"}, true, false, nil},
+		{"missing username", config.KafkaConfig{SASLMechanism: "plain", SASLPassword: "secret"}, true, false, nil},
+		{"missing password", config.KafkaConfig{SASLMechanism: "scram-sha-256", SASLUser: "app"}, true, false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := securityOpts(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("securityOpts() error = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			// Read the options back from a client built with them; it does
+			// not connect until used
+			client, err := kgo.NewClient(opts...)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+			defer client.Close()
+
+			tlsCfg, _ := client.OptValue(kgo.DialTLSConfig).(*tls.Config)
+			if (tlsCfg != nil) != tt.wantTLS {
+				t.Errorf("TLS config = %v, want TLS %v", tlsCfg, tt.wantTLS)
+			}
+			if tlsCfg != nil && tlsCfg.MinVersion != tls.VersionTLS12 {
+				t.Errorf("TLS MinVersion = %x, want TLS 1.2", tlsCfg.MinVersion)
+			}
+
+			mechanisms, _ := client.OptValue(kgo.SASL).([]sasl.Mechanism)
+			var names []string
+			for _, mechanism := range mechanisms {
+				names = append(names, mechanism.Name())
+			}
+			if !slices.Equal(names, tt.wantMechs) {
+				t.Errorf("SASL mechanisms = %v, want %v", names, tt.wantMechs)
+			}
+		})
+	}
+}
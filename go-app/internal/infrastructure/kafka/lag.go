@@ -0,0 +1,75 @@
+package kafka
+
+import (
+	"context"
+	"sync"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// lagTracker keeps the latest high watermark seen per partition so consumer
+// lag can be computed against committed offsets at collection time
+type lagTracker struct {
+	mu             sync.Mutex
+	highWatermarks map[string]map[int32]int64
+}
+
+// newLagTracker creates an empty lagTracker
+func newLagTracker() *lagTracker {
+	return &lagTracker{highWatermarks: make(map[string]map[int32]int64)}
+}
+
+// observe records the high watermarks returned with a poll
+func (t *lagTracker) observe(fetches kgo.Fetches) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	fetches.EachPartition(func(p kgo.FetchTopicPartition) {
+		if p.Err != nil {
+			return
+		}
+		if t.highWatermarks[p.Topic] == nil {
+			t.highWatermarks[p.Topic] = make(map[int32]int64)
+		}
+		t.highWatermarks[p.Topic][p.Partition] = p.HighWatermark
+	})
+}
+
+// registerLagGauge registers the kafka.consumer.lag gauge, observing end
+// offsets against the consumer group's committed offsets per partition
+func (c *Consumer) registerLagGauge(meter metric.Meter) error {
+	gauge, err := meter.Int64ObservableGauge("kafka.consumer.lag",
+		metric.WithDescription("Difference between the partition end offset and the committed offset"),
+		metric.WithUnit("{message}"))
+	if err != nil {
+		return err
+	}
+
+	c.lagRegistration, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		committed := c.CommittedOffsets()
+
+		c.lag.mu.Lock()
+		defer c.lag.mu.Unlock()
+
+		for topic, partitions := range c.lag.highWatermarks {
+			for partition, highWatermark := range partitions {
+				offset, ok := committed[topic][partition]
+				if !ok {
+					continue
+				}
+				lag := highWatermark - offset.Offset
+				if lag < 0 {
+					lag = 0
+				}
+				o.ObserveInt64(gauge, lag, metric.WithAttributes(
+					attribute.String("topic", topic),
+					attribute.Int("partition", int(partition)),
+				))
+			}
+		}
+		return nil
+	}, gauge)
+	return err
+}
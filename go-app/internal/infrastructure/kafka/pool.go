@@ -0,0 +1,134 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"hash/fnv"
+	"sync"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// workerQueueSize bounds the records buffered per worker; dispatch blocks
+// once a worker's queue is full so a slow handler applies backpressure
+const workerQueueSize = 16
+
+// RecordHandler processes a single consumed record
+type RecordHandler func(ctx context.Context, record *kgo.Record) error
+
+// indexedRecord pairs a record with its position in the fetched batch
+type indexedRecord struct {
+	index  int
+	record *kgo.Record
+}
+
+// processRecords runs the handler for every record and returns the handler
+// error for each record, in batch order. With more than one worker, records
+// are dispatched by key so per-key ordering is preserved. It returns once all
+// records have been handled.
+func (c *Consumer) processRecords(ctx context.Context, records []*kgo.Record, handler RecordHandler) []error {
+	results := make([]error, len(records))
+
+	if c.workers <= 1 {
+		for i, record := range records {
+			results[i] = c.processRecord(ctx, record, handler)
+		}
+		return results
+	}
+
+	queues := make([]chan indexedRecord, c.workers)
+	var wg sync.WaitGroup
+	for i := range queues {
+		queues[i] = make(chan indexedRecord, workerQueueSize)
+		wg.Add(1)
+		go func(queue <-chan indexedRecord) {
+			defer wg.Done()
+			for item := range queue {
+				results[item.index] = c.processRecord(ctx, item.record, handler)
+			}
+		}(queues[i])
+	}
+
+	for i, record := range records {
+		queues[workerIndex(record, c.workers)] <- indexedRecord{index: i, record: record}
+	}
+	for _, queue := range queues {
+		close(queue)
+	}
+
+	// Drain in-flight work before returning
+	wg.Wait()
+	return results
+}
+
+// processRecord runs the handler for a record inside a span that continues
+// the producer's trace and links back to the consume span. When the event
+// originated in another trace, such as an HTTP request whose event was
+// relayed from the outbox, the span also links to the originating span.
+func (c *Consumer) processRecord(ctx context.Context, record *kgo.Record, handler RecordHandler) error {
+	recordCtx := otel.GetTextMapPropagator().Extract(ctx, NewRecordCarrier(record))
+	links := []trace.Link{trace.LinkFromContext(ctx)}
+	if origin, ok := originLink(record); ok && origin.SpanContext.TraceID() != trace.SpanContextFromContext(recordCtx).TraceID() {
+		links = append(links, origin)
+	}
+	recordCtx, recordSpan := c.tracer.Start(recordCtx, "kafka.process_record",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithLinks(links...),
+	)
+	defer recordSpan.End()
+
+	recordSpan.SetAttributes(
+		attribute.String("kafka.topic", record.Topic),
+		attribute.Int64("kafka.offset", record.Offset),
+		attribute.Int("kafka.partition", int(record.Partition)),
+	)
+
+	err := handler(recordCtx, record)
+	if err != nil {
+		recordSpan.SetAttributes(attribute.Bool("kafka.processing_error", true))
+	}
+	return err
+}
+
+// originLink builds a link to the span recorded in the record's event
+// envelope. Records that are not envelopes, or carry no valid span, have none.
+func originLink(record *kgo.Record) (trace.Link, bool) {
+	var origin struct {
+		TraceID string `json:"trace_id"`
+		SpanID  string `json:"span_id"`
+	}
+	if err := json.Unmarshal(record.Value, &origin); err != nil {
+		return trace.Link{}, false
+	}
+	traceID, err := trace.TraceIDFromHex(origin.TraceID)
+	if err != nil {
+		return trace.Link{}, false
+	}
+	spanID, err := trace.SpanIDFromHex(origin.SpanID)
+	if err != nil {
+		return trace.Link{}, false
+	}
+	return trace.Link{
+		SpanContext: trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID: traceID,
+			SpanID:  spanID,
+			Remote:  true,
+		}),
+		Attributes: []attribute.KeyValue{attribute.String("link.type", "event.origin")},
+	}, true
+}
+
+// workerIndex hashes the record key to a worker. Records without a key are
+// assigned by partition so their relative order is kept too.
+func workerIndex(record *kgo.Record, workers int) int {
+	h := fnv.New32a()
+	if len(record.Key) > 0 {
+		h.Write(record.Key)
+	} else {
+		h.Write([]byte{byte(record.Partition >> 24), byte(record.Partition >> 16), byte(record.Partition >> 8), byte(record.Partition)})
+	}
+	return int(h.Sum32() % uint32(workers))
+}
@@ -0,0 +1,49 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"go-app/internal/domain/event"
+)
+
+// EventPublisher publishes JSON-encoded events to a Kafka topic
+type EventPublisher struct {
+	producer *Producer
+	topic    string
+}
+
+// NewEventPublisher creates a new EventPublisher for the given topic
+func NewEventPublisher(producer *Producer, topic string) *EventPublisher {
+	return &EventPublisher{
+		producer: producer,
+		topic:    topic,
+	}
+}
+
+// Publish encodes the event as JSON and produces it with the given key.
+// Payloads are wrapped in an event.Event envelope carrying the current trace ID.
+func (p *EventPublisher) Publish(ctx context.Context, key string, payload interface{}) error {
+	var value []byte
+	var err error
+	if typed, ok := payload.(event.Payload); ok {
+		var envelope event.Event
+		if envelope, err = event.NewEvent(typed); err != nil {
+			return err
+		}
+		if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+			envelope.TraceID = sc.TraceID().String()
+			envelope.SpanID = sc.SpanID().String()
+		}
+		value, err = json.Marshal(envelope)
+	} else {
+		value, err = json.Marshal(payload)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+	return p.producer.ProduceWithTracing(ctx, p.topic, []byte(key), value)
+}
@@ -0,0 +1,99 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"go-app/internal/infrastructure/telemetry"
+)
+
+// ErrTransactionsDisabled is returned by ProduceTransactional when the
+// producer was created without a transactional ID
+var ErrTransactionsDisabled = errors.New("kafka transactions are disabled: KAFKA_TRANSACTIONAL_ID is not set")
+
+// ProduceTransactional produces records atomically: consumers reading with
+// read_committed isolation see either all of them or none. Any produce error
+// aborts the transaction. Transactions are serialized, since a transactional
+// ID allows only one open transaction at a time.
+func (p *Producer) ProduceTransactional(ctx context.Context, records ...*kgo.Record) error {
+	ctx, span := p.tracer.Start(ctx, "kafka.produce_transaction", trace.WithSpanKind(trace.SpanKindProducer))
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("kafka.operation", "produce_transaction"),
+		attribute.Int("kafka.batch_size", len(records)),
+	)
+
+	if p.txClient == nil {
+		span.SetStatus(codes.Error, ErrTransactionsDisabled.Error())
+		return ErrTransactionsDisabled
+	}
+	if len(records) == 0 {
+		return nil
+	}
+
+	p.txMu.Lock()
+	defer p.txMu.Unlock()
+
+	if err := p.txClient.BeginTransaction(); err != nil {
+		return p.transactionFailed(span, "failed to begin kafka transaction", err)
+	}
+
+	// Inject trace context so consumers can continue the trace
+	propagator := otel.GetTextMapPropagator()
+	for _, record := range records {
+		propagator.Inject(ctx, NewRecordCarrier(record))
+	}
+
+	var errs []error
+	for _, result := range p.txClient.ProduceSync(ctx, records...) {
+		if result.Err != nil {
+			errs = append(errs, result.Err)
+		}
+	}
+	if len(errs) > 0 {
+		produceErr := fmt.Errorf("failed to produce %d of %d messages: %w", len(errs), len(records), errors.Join(errs...))
+		// Abort with a context that outlives a cancelled request, so the
+		// transaction does not stay open until the broker times it out
+		if err := p.txClient.EndTransaction(context.WithoutCancel(ctx), kgo.TryAbort); err != nil {
+			produceErr = errors.Join(produceErr, fmt.Errorf("failed to abort kafka transaction: %w", err))
+		}
+		span.SetAttributes(attribute.Bool("kafka.transaction.aborted", true))
+		return p.transactionFailed(span, "kafka transaction aborted", produceErr)
+	}
+
+	if err := p.txClient.EndTransaction(ctx, kgo.TryCommit); err != nil {
+		return p.transactionFailed(span, "failed to commit kafka transaction", err)
+	}
+
+	span.SetAttributes(attribute.Bool("kafka.transaction.committed", true))
+	telemetry.Log(ctx, telemetry.LevelInfo, "Kafka transaction committed", nil,
+		attribute.Int("kafka.batch_size", len(records)),
+	)
+	return nil
+}
+
+// transactionFailed records err on the span and wraps it, calling out clusters
+// that do not support or do not authorize transactions
+func (p *Producer) transactionFailed(span trace.Span, msg string, err error) error {
+	switch {
+	case errors.Is(err, kerr.UnsupportedVersion), errors.Is(err, kerr.UnsupportedForMessageFormat):
+		err = fmt.Errorf("kafka cluster does not support transactions: %w", err)
+	case errors.Is(err, kerr.TransactionalIDAuthorizationFailed):
+		err = fmt.Errorf("not authorized to use the transactional ID: %w", err)
+	default:
+		err = fmt.Errorf("%s: %w", msg, err)
+	}
+
+	span.RecordError(err)
+	span.SetStatus(codes.Error, msg)
+	return err
+}
@@ -0,0 +1,107 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"go-app/internal/infrastructure/telemetry"
+)
+
+// StopFunc stops a component, giving up when ctx expires
+type StopFunc func(ctx context.Context) error
+
+// component is a registered component and the names of the components it uses
+type component struct {
+	name      string
+	stop      StopFunc
+	dependsOn []string
+}
+
+// Manager stops the application's components in dependency order, so each
+// component is stopped only after every component that uses it
+type Manager struct {
+	mu         sync.Mutex
+	components []*component
+	stopped    bool
+}
+
+// NewManager creates an empty lifecycle manager
+func NewManager() *Manager {
+	return &Manager{}
+}
+
+// Register adds a component stopped by stop. dependsOn names the components
+// it uses, which are kept running until it has stopped; they may be
+// registered later. Names must be unique.
+func (m *Manager) Register(name string, stop StopFunc, dependsOn ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, c := range m.components {
+		if c.name == name {
+			panic(fmt.Sprintf("lifecycle: component %q registered twice", name))
+		}
+	}
+	m.components = append(m.components, &component{name: name, stop: stop, dependsOn: dependsOn})
+}
+
+// Shutdown stops every component once, dependents before their dependencies.
+// Components unrelated by dependencies stop in reverse registration order,
+// like deferred calls. Each stop receives ctx, and a failed stop does not
+// prevent the others; failures are logged and returned joined.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.stopped {
+		return nil
+	}
+	m.stopped = true
+
+	var errs []error
+	for _, c := range m.order() {
+		if err := c.stop(ctx); err != nil {
+			telemetry.Log(ctx, telemetry.LevelError, "Error stopping component", err,
+				attribute.String("component", c.name),
+			)
+			errs = append(errs, fmt.Errorf("%s: %w", c.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// order sorts the components so each comes before the components it depends
+// on. A dependency cycle is broken at the component visited first; unknown
+// dependency names are ignored.
+func (m *Manager) order() []*component {
+	dependents := make(map[string][]*component, len(m.components))
+	for _, c := range m.components {
+		for _, dep := range c.dependsOn {
+			dependents[dep] = append(dependents[dep], c)
+		}
+	}
+
+	visited := make(map[*component]bool, len(m.components))
+	order := make([]*component, 0, len(m.components))
+	var visit func(c *component)
+	visit = func(c *component) {
+		if visited[c] {
+			return
+		}
+		visited[c] = true
+		// Latest registered dependents first, matching the order without dependencies
+		users := dependents[c.name]
+		for i := len(users) - 1; i >= 0; i-- {
+			visit(users[i])
+		}
+		order = append(order, c)
+	}
+	for i := len(m.components) - 1; i >= 0; i-- {
+		visit(m.components[i])
+	}
+	return order
+}
@@ -0,0 +1,93 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+// recorder registers components that append their name to stopped
+type recorder struct {
+	manager *Manager
+	stopped []string
+	deps    map[string][]string
+}
+
+func newRecorder() *recorder {
+	return &recorder{manager: NewManager(), deps: make(map[string][]string)}
+}
+
+func (r *recorder) register(name string, dependsOn ...string) {
+	r.deps[name] = dependsOn
+	r.manager.Register(name, func(context.Context) error {
+		r.stopped = append(r.stopped, name)
+		return nil
+	}, dependsOn...)
+}
+
+func TestShutdownOrder(t *testing.T) {
+	r := newRecorder()
+	// Registered as in main.go, where several components depend on
+	// telemetry.flush before it is registered
+	r.register("telemetry")
+	r.register("postgres", "telemetry")
+	r.register("redis", "telemetry")
+	r.register("kafka.producer", "telemetry")
+	r.register("kafka.worker", "kafka.producer", "telemetry.flush")
+	r.register("outbox.relay", "postgres", "kafka.producer", "telemetry.flush")
+	r.register("http.server", "postgres", "redis", "kafka.producer", "telemetry.flush")
+	r.register("telemetry.flush", "postgres", "redis", "kafka.producer", "telemetry")
+
+	if err := r.manager.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	if len(r.stopped) != len(r.deps) {
+		t.Fatalf("stopped %v, want each of the %d components once", r.stopped, len(r.deps))
+	}
+	if last := r.stopped[len(r.stopped)-1]; last != "telemetry" {
+		t.Errorf("last stopped = %q, want telemetry; order %v", last, r.stopped)
+	}
+	for name, deps := range r.deps {
+		for _, dep := range deps {
+			if slices.Index(r.stopped, name) > slices.Index(r.stopped, dep) {
+				t.Errorf("%s stopped after its dependency %s; order %v", name, dep, r.stopped)
+			}
+		}
+	}
+}
+
+func TestShutdownWithoutDependenciesIsReverseRegistration(t *testing.T) {
+	r := newRecorder()
+	r.register("first")
+	r.register("second")
+	r.register("third")
+
+	if err := r.manager.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if want := []string{"third", "second", "first"}; !slices.Equal(r.stopped, want) {
+		t.Errorf("stopped %v, want %v", r.stopped, want)
+	}
+}
+
+func TestShutdownContinuesAfterFailure(t *testing.T) {
+	r := newRecorder()
+	r.register("telemetry")
+	failure := errors.New("close failed")
+	r.manager.Register("postgres", func(context.Context) error { return failure }, "telemetry")
+
+	err := r.manager.Shutdown(context.Background())
+	if !errors.Is(err, failure) {
+		t.Errorf("Shutdown() error = %v, want it to wrap %v", err, failure)
+	}
+	if !slices.Equal(r.stopped, []string{"telemetry"}) {
+		t.Errorf("stopped %v, want telemetry stopped despite the failure", r.stopped)
+	}
+
+	// A second shutdown is a no-op
+	if err := r.manager.Shutdown(context.Background()); err != nil || len(r.stopped) != 1 {
+		t.Errorf("second Shutdown() = %v, stopped %v; want no-op", err, r.stopped)
+	}
+}
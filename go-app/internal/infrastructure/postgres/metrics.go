@@ -0,0 +1,54 @@
+package postgres
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/metric"
+)
+
+// registerPoolMetrics registers instruments observing the connection pool
+// statistics of sql.DB on each metric collection
+func (c *Client) registerPoolMetrics(meter metric.Meter) error {
+	open, err := meter.Int64ObservableGauge("db.connections.open",
+		metric.WithDescription("Established connections, both in use and idle"),
+		metric.WithUnit("{connection}"))
+	if err != nil {
+		return err
+	}
+	idle, err := meter.Int64ObservableGauge("db.connections.idle",
+		metric.WithDescription("Idle connections"),
+		metric.WithUnit("{connection}"))
+	if err != nil {
+		return err
+	}
+	inUse, err := meter.Int64ObservableGauge("db.connections.in_use",
+		metric.WithDescription("Connections currently in use"),
+		metric.WithUnit("{connection}"))
+	if err != nil {
+		return err
+	}
+	// Wait count and duration are cumulative, so they are exported as counters
+	waitCount, err := meter.Int64ObservableCounter("db.connections.wait_count",
+		metric.WithDescription("Total connections waited for because the pool was exhausted"),
+		metric.WithUnit("{wait}"))
+	if err != nil {
+		return err
+	}
+	waitDuration, err := meter.Float64ObservableCounter("db.connections.wait_duration",
+		metric.WithDescription("Total time blocked waiting for a new connection"),
+		metric.WithUnit("s"))
+	if err != nil {
+		return err
+	}
+
+	c.poolRegistration, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		stats := c.Stats()
+		o.ObserveInt64(open, int64(stats.OpenConnections))
+		o.ObserveInt64(idle, int64(stats.Idle))
+		o.ObserveInt64(inUse, int64(stats.InUse))
+		o.ObserveInt64(waitCount, stats.WaitCount)
+		o.ObserveFloat64(waitDuration, stats.WaitDuration.Seconds())
+		return nil
+	}, open, idle, inUse, waitCount, waitDuration)
+	return err
+}
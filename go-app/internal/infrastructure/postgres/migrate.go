@@ -0,0 +1,119 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"go-app/internal/infrastructure/telemetry"
+)
+
+// migrationFiles holds the versioned schema migrations, applied in file name order
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationLockID is the advisory lock key serialising concurrent migration runs
+const migrationLockID = 7_264_011
+
+// Migrate applies every embedded migration not yet recorded in the
+// schema_migrations table. Each migration runs in its own transaction, and an
+// advisory lock keeps concurrently starting instances from racing.
+func (c *Client) Migrate(ctx context.Context) error {
+	ctx, span := c.tracer.Start(ctx, "postgres.migrate")
+	defer span.End()
+
+	names, err := fs.Glob(migrationFiles, "migrations/*.sql")
+	if err != nil {
+		span.SetAttributes(attribute.Bool("db.error", true))
+		return fmt.Errorf("failed to list migrations: %w", err)
+	}
+	sort.Strings(names)
+
+	// Advisory locks are per session, so hold one connection for the whole run
+	conn, err := c.Conn(ctx)
+	if err != nil {
+		span.SetAttributes(attribute.Bool("db.error", true))
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockID); err != nil {
+		span.SetAttributes(attribute.Bool("db.error", true))
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer func() {
+		_, _ = conn.ExecContext(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock($1)", migrationLockID)
+	}()
+
+	if _, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version VARCHAR(255) PRIMARY KEY,
+		applied_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+	)`); err != nil {
+		span.SetAttributes(attribute.Bool("db.error", true))
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	applied := 0
+	for _, name := range names {
+		version := strings.TrimSuffix(strings.TrimPrefix(name, "migrations/"), ".sql")
+
+		var exists bool
+		if err := conn.QueryRowContext(ctx,
+			"SELECT EXISTS(SELECT 1 FROM schema_migrations WHERE version = $1)", version,
+		).Scan(&exists); err != nil {
+			span.SetAttributes(attribute.Bool("db.error", true))
+			return fmt.Errorf("failed to check migration %s: %w", version, err)
+		}
+		if exists {
+			continue
+		}
+
+		script, err := migrationFiles.ReadFile(name)
+		if err != nil {
+			span.SetAttributes(attribute.Bool("db.error", true))
+			return fmt.Errorf("failed to read migration %s: %w", version, err)
+		}
+		if err := applyMigration(ctx, conn, version, string(script)); err != nil {
+			span.SetAttributes(attribute.Bool("db.error", true))
+			return err
+		}
+
+		applied++
+		telemetry.Log(ctx, telemetry.LevelInfo, "Applied database migration", nil,
+			attribute.String("db.migration.version", version),
+		)
+	}
+
+	span.SetAttributes(
+		attribute.Int("db.migrations.total", len(names)),
+		attribute.Int("db.migrations.applied", applied),
+	)
+	return nil
+}
+
+// applyMigration runs one migration script and records its version atomically
+func applyMigration(ctx context.Context, conn *sql.Conn, version, script string) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin migration %s: %w", version, err)
+	}
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("failed to apply migration %s: %w", version, err)
+	}
+	if _, err := tx.ExecContext(ctx, "INSERT INTO schema_migrations (version) VALUES ($1)", version); err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("failed to record migration %s: %w", version, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %s: %w", version, err)
+	}
+	return nil
+}
@@ -0,0 +1,3 @@
+-- Optional E.164 phone number (at most 15 digits plus the leading "+").
+-- NULL when the user has no phone.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS phone VARCHAR(16);
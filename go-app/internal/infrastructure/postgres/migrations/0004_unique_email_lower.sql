@@ -0,0 +1,8 @@
+-- Enforce email uniqueness case-insensitively, so rows written without the
+-- entity's lowercasing cannot duplicate an address. Soft-deleted rows are
+-- excluded so their addresses can be registered again. Creating the index
+-- fails if such duplicates already exist; merge them before migrating.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_lower ON users (lower(email)) WHERE deleted_at IS NULL;
+
+-- Lookups compare lower(email) and use the index above
+DROP INDEX IF EXISTS idx_users_email;
@@ -0,0 +1,12 @@
+-- Scope email uniqueness to users that are not soft-deleted, so a deleted
+-- user's address can be registered again. Databases migrated before this
+-- carry a UNIQUE constraint on the raw column and a unique index on
+-- lower(email) covering deleted rows; both are replaced.
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+
+DROP INDEX IF EXISTS idx_users_email_lower;
+CREATE UNIQUE INDEX idx_users_email_lower ON users (lower(email)) WHERE deleted_at IS NULL;
+
+-- Lookups without the deleted_at filter, as when soft delete is disabled,
+-- cannot use the partial index above
+CREATE INDEX IF NOT EXISTS idx_users_email ON users (lower(email));
@@ -3,7 +3,10 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"go-app/internal/infrastructure/config"
@@ -12,6 +15,7 @@ import (
 	"github.com/XSAM/otelsql"
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 	"go.opentelemetry.io/otel/trace"
 )
@@ -19,17 +23,30 @@ import (
 // Client wraps sql.DB with additional functionality
 type Client struct {
 	*sql.DB
-	tracer trace.Tracer
+	tracer           trace.Tracer
+	queryTimeout     time.Duration
+	statements       statementRecorder
+	poolRegistration metric.Registration
 }
 
 // NewClient creates a new Postgres client with best practices configuration
 func NewClient(ctx context.Context, cfg config.PostgresConfig, tel *telemetry.Telemetry) (*Client, error) {
-	// Open database connection with OpenTelemetry tracing
+	statements := statementRecorder{
+		mode:          strings.ToLower(cfg.RecordStatement),
+		stripLiterals: cfg.StripStatementLiterals,
+	}
+
+	// Open database connection with OpenTelemetry tracing. The statement is
+	// recorded by the getter, following POSTGRES_RECORD_STATEMENT.
 	db, err := otelsql.Open("pgx", cfg.DSN,
 		otelsql.WithAttributes(semconv.DBSystemPostgreSQL),
 		otelsql.WithSpanOptions(otelsql.SpanOptions{
-			Ping:     true,
-			RowsNext: true,
+			Ping:         true,
+			RowsNext:     true,
+			DisableQuery: true,
+		}),
+		otelsql.WithAttributesGetter(func(_ context.Context, _ otelsql.Method, query string, _ []driver.NamedValue) []attribute.KeyValue {
+			return statements.attributes(query)
 		}),
 	)
 	if err != nil {
@@ -42,21 +59,70 @@ func NewClient(ctx context.Context, cfg config.PostgresConfig, tel *telemetry.Te
 	db.SetConnMaxLifetime(time.Duration(cfg.ConnMaxLifetime) * time.Minute)
 	db.SetConnMaxIdleTime(time.Duration(cfg.ConnMaxIdleTime) * time.Minute)
 
-	// Test the connection
-	if err := db.PingContext(ctx); err != nil {
+	// Test the connection, retrying while the database starts up
+	if err := pingWithRetry(ctx, db, cfg); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to ping postgres: %w", err)
 	}
 
 	telemetry.Log(ctx, telemetry.LevelInfo, "Successfully connected to Postgres", nil,
-		attribute.String("postgres.dsn", maskDSN(cfg.DSN)),
+		attribute.String("postgres.dsn", config.MaskDSN(cfg.DSN)),
 		attribute.Int("postgres.max_open_conns", cfg.MaxOpenConns),
 		attribute.Int("postgres.max_idle_conns", cfg.MaxIdleConns),
 	)
 
-	return &Client{
-		DB:     db,
-		tracer: tel.Tracer,
-	}, nil
+	client := &Client{
+		DB:           db,
+		tracer:       tel.Tracer,
+		queryTimeout: time.Duration(cfg.QueryTimeoutSecs) * time.Second,
+		statements:   statements,
+	}
+
+	if err := client.registerPoolMetrics(tel.Meter); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to register postgres pool metrics: %w", err)
+	}
+
+	return client, nil
+}
+
+// maxConnectRetryBackoff caps the delay between startup ping attempts
+const maxConnectRetryBackoff = 30 * time.Second
+
+// pingWithRetry pings the database, retrying with exponential backoff up to
+// cfg.ConnectRetries times. It stops early when ctx is cancelled.
+func pingWithRetry(ctx context.Context, db *sql.DB, cfg config.PostgresConfig) error {
+	backoff := time.Duration(cfg.ConnectRetryBackoff) * time.Millisecond
+	attempts := cfg.ConnectRetries + 1
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err = db.PingContext(ctx); err == nil {
+			return nil
+		}
+		if attempt == attempts {
+			break
+		}
+
+		telemetry.Log(ctx, telemetry.LevelWarn, "Postgres not ready, retrying", err,
+			attribute.Int("postgres.attempt", attempt),
+			attribute.Int("postgres.max_attempts", attempts),
+			attribute.String("postgres.retry_backoff", backoff.String()),
+			attribute.String("error", err.Error()),
+		)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > maxConnectRetryBackoff {
+			backoff = maxConnectRetryBackoff
+		}
+	}
+	return err
 }
 
 // HealthCheck performs a health check on the Postgres connection
@@ -80,6 +146,9 @@ func (c *Client) GetStats() sql.DBStats {
 
 // Close closes the database connection
 func (c *Client) Close() error {
+	if c.poolRegistration != nil {
+		_ = c.poolRegistration.Unregister()
+	}
 	return c.DB.Close()
 }
 
@@ -88,14 +157,16 @@ func (c *Client) ExecWithTracing(ctx context.Context, query string, args ...inte
 	ctx, span := c.tracer.Start(ctx, "postgres.exec")
 	defer span.End()
 
-	span.SetAttributes(
-		attribute.String("db.statement", query),
-		attribute.String("db.operation", "exec"),
-	)
+	span.SetAttributes(attribute.String("db.operation", "exec"))
+	span.SetAttributes(c.statements.attributes(query)...)
+
+	ctx, cancel := c.withQueryTimeout(ctx)
+	defer cancel()
 
 	result, err := c.ExecContext(ctx, query, args...)
 	if err != nil {
 		span.SetAttributes(attribute.Bool("db.error", true))
+		recordTimeout(ctx, span)
 	}
 
 	return result, err
@@ -106,14 +177,17 @@ func (c *Client) QueryWithTracing(ctx context.Context, query string, args ...int
 	ctx, span := c.tracer.Start(ctx, "postgres.query")
 	defer span.End()
 
-	span.SetAttributes(
-		attribute.String("db.statement", query),
-		attribute.String("db.operation", "query"),
-	)
+	span.SetAttributes(attribute.String("db.operation", "query"))
+	span.SetAttributes(c.statements.attributes(query)...)
+
+	// Rows are read after returning, so the timeout context cannot be
+	// cancelled on return; its own timer releases it when the timeout expires
+	ctx, _ = c.withQueryTimeout(ctx)
 
 	rows, err := c.QueryContext(ctx, query, args...)
 	if err != nil {
 		span.SetAttributes(attribute.Bool("db.error", true))
+		recordTimeout(ctx, span)
 	}
 
 	return rows, err
@@ -124,12 +198,19 @@ func (c *Client) QueryRowWithTracing(ctx context.Context, query string, args ...
 	ctx, span := c.tracer.Start(ctx, "postgres.query_row")
 	defer span.End()
 
-	span.SetAttributes(
-		attribute.String("db.statement", query),
-		attribute.String("db.operation", "query_row"),
-	)
+	span.SetAttributes(attribute.String("db.operation", "query_row"))
+	span.SetAttributes(c.statements.attributes(query)...)
+
+	// The row is scanned after returning, so the timeout context cannot be
+	// cancelled on return; its own timer releases it when the timeout expires
+	ctx, _ = c.withQueryTimeout(ctx)
 
-	return c.QueryRowContext(ctx, query, args...)
+	row := c.QueryRowContext(ctx, query, args...)
+	if row.Err() != nil {
+		span.SetAttributes(attribute.Bool("db.error", true))
+		recordTimeout(ctx, span)
+	}
+	return row
 }
 
 // BeginTxWithTracing begins a transaction with tracing
@@ -147,11 +228,21 @@ func (c *Client) BeginTxWithTracing(ctx context.Context, opts *sql.TxOptions) (*
 	return tx, err
 }
 
-// maskDSN masks sensitive information in DSN for logging
-func maskDSN(dsn string) string {
-	// Simple masking - in production, use a more sophisticated approach
-	if len(dsn) > 20 {
-		return dsn[:10] + "***" + dsn[len(dsn)-7:]
+// withQueryTimeout derives a context bounded by the configured query timeout,
+// unless the timeout is disabled or the caller already set a shorter deadline
+func (c *Client) withQueryTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.queryTimeout <= 0 {
+		return ctx, func() {}
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= c.queryTimeout {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, c.queryTimeout)
+}
+
+// recordTimeout marks the span when the query's deadline fired
+func recordTimeout(ctx context.Context, span trace.Span) {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		span.SetAttributes(attribute.Bool("db.timeout", true))
 	}
-	return "***"
 }
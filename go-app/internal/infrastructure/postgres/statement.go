@@ -0,0 +1,107 @@
+package postgres
+
+import (
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// How much of a SQL statement is recorded on spans, set by
+// POSTGRES_RECORD_STATEMENT
+const (
+	recordStatementOff           = "off"
+	recordStatementOperationOnly = "operation_only"
+	recordStatementFull          = "full"
+)
+
+// statementRecorder builds the span attributes describing a SQL statement
+type statementRecorder struct {
+	mode          string
+	stripLiterals bool
+}
+
+// attributes returns the statement attributes allowed by the recording mode:
+// none, the SQL verb as db.operation.name, or the verb and the statement text
+func (r statementRecorder) attributes(query string) []attribute.KeyValue {
+	if r.mode == recordStatementOff || query == "" {
+		return nil
+	}
+
+	var attrs []attribute.KeyValue
+	if verb := sqlVerb(query); verb != "" {
+		attrs = append(attrs, semconv.DBOperationName(verb))
+	}
+	if r.mode == recordStatementFull {
+		if r.stripLiterals {
+			query = stripLiterals(query)
+		}
+		attrs = append(attrs, attribute.String("db.statement", query))
+	}
+	return attrs
+}
+
+// sqlVerb returns the first keyword of query in upper case, such as SELECT
+func sqlVerb(query string) string {
+	fields := strings.Fields(strings.TrimLeft(query, " \t\r\n("))
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.ToUpper(fields[0])
+}
+
+// stripLiterals replaces string and numeric literals in query with ?, keeping
+// $n placeholders and quoted identifiers
+func stripLiterals(query string) string {
+	var b strings.Builder
+	b.Grow(len(query))
+
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '\'':
+			// Skip to the closing quote; '' is an escaped quote
+			j := i + 1
+			for ; j < len(query); j++ {
+				if query[j] != '\'' {
+					continue
+				}
+				if j+1 < len(query) && query[j+1] == '\'' {
+					j++
+					continue
+				}
+				break
+			}
+			b.WriteByte('?')
+			i = j
+		case c == '"':
+			// Quoted identifiers are copied as they are
+			j := strings.IndexByte(query[i+1:], '"')
+			if j < 0 {
+				b.WriteString(query[i:])
+				return b.String()
+			}
+			b.WriteString(query[i : i+j+2])
+			i += j + 1
+		case isDigit(c) && (i == 0 || !isIdentByte(query[i-1])):
+			j := i
+			for j < len(query) && (isDigit(query[j]) || query[j] == '.') {
+				j++
+			}
+			b.WriteByte('?')
+			i = j - 1
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// isIdentByte reports whether c can be part of an identifier or placeholder
+func isIdentByte(c byte) bool {
+	return isDigit(c) || c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
@@ -0,0 +1,122 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"go-app/internal/domain/repository"
+	"go-app/internal/infrastructure/telemetry"
+)
+
+// txKey is the context key holding the active transaction
+type txKey struct{}
+
+// TxFromContext returns the transaction stored in ctx by TxManager, if any
+func TxFromContext(ctx context.Context) (*sql.Tx, bool) {
+	tx, ok := ctx.Value(txKey{}).(*sql.Tx)
+	return tx, ok
+}
+
+// afterCommitKey is the context key holding the hooks of the active transaction
+type afterCommitKey struct{}
+
+// afterCommitHooks collects the functions to run once a transaction commits
+type afterCommitHooks struct {
+	mu  sync.Mutex
+	fns []func(ctx context.Context)
+}
+
+// AfterCommit runs fn once the transaction in ctx commits, or right away when
+// ctx holds no transaction. fn is dropped if the transaction rolls back. Use
+// it for side effects, such as cache invalidation, that must not be visible
+// before the change they follow.
+func AfterCommit(ctx context.Context, fn func(ctx context.Context)) {
+	hooks, ok := ctx.Value(afterCommitKey{}).(*afterCommitHooks)
+	if !ok {
+		fn(ctx)
+		return
+	}
+	hooks.mu.Lock()
+	defer hooks.mu.Unlock()
+	hooks.fns = append(hooks.fns, fn)
+}
+
+// TxManager runs functions within a Postgres transaction
+type TxManager struct {
+	client *Client
+}
+
+// Compile-time check TxManager implements repository.TxManager
+var _ repository.TxManager = (*TxManager)(nil)
+
+// NewTxManager creates a new TxManager
+func NewTxManager(client *Client) *TxManager {
+	return &TxManager{client: client}
+}
+
+// WithinTx runs fn in a transaction stored in the context passed to fn.
+// Nested calls join the outer transaction. Hooks registered with AfterCommit
+// run after the commit, with ctx.
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if _, ok := TxFromContext(ctx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := m.client.BeginTxWithTracing(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			m.rollback(ctx, tx)
+			panic(p)
+		}
+	}()
+
+	hooks := &afterCommitHooks{}
+	txCtx := context.WithValue(context.WithValue(ctx, txKey{}, tx), afterCommitKey{}, hooks)
+	if err := fn(txCtx); err != nil {
+		m.rollback(ctx, tx)
+		return err
+	}
+
+	if err := m.commit(ctx, tx); err != nil {
+		return err
+	}
+	for _, hook := range hooks.fns {
+		hook(ctx)
+	}
+	return nil
+}
+
+// commit commits the transaction with tracing
+func (m *TxManager) commit(ctx context.Context, tx *sql.Tx) error {
+	_, span := m.client.tracer.Start(ctx, "postgres.commit")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("db.operation", "commit"))
+
+	if err := tx.Commit(); err != nil {
+		span.SetAttributes(attribute.Bool("db.error", true))
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// rollback rolls back the transaction with tracing
+func (m *TxManager) rollback(ctx context.Context, tx *sql.Tx) {
+	ctx, span := m.client.tracer.Start(ctx, "postgres.rollback")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("db.operation", "rollback"))
+
+	if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+		span.SetAttributes(attribute.Bool("db.error", true))
+		telemetry.Log(ctx, telemetry.LevelError, "Failed to roll back transaction", err)
+	}
+}
@@ -0,0 +1,127 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/sync/singleflight"
+
+	"go-app/internal/infrastructure/telemetry"
+)
+
+// Cache is a JSON cache-aside helper on top of Client. Redis failures are
+// logged and treated as misses, so a cache outage only costs latency.
+type Cache struct {
+	client *Client
+	group  singleflight.Group
+}
+
+// NewCache creates a new Cache
+func NewCache(client *Client) *Cache {
+	return &Cache{client: client}
+}
+
+// loaderTimeout bounds a GetOrSet loader call. The call is shared by every
+// concurrent miss for its key, so it runs detached from the caller's
+// cancellation.
+const loaderTimeout = 10 * time.Second
+
+// GetOrSet returns the value cached under key, or calls loader on a miss and
+// caches its result for ttl. Concurrent misses for the same key share one
+// loader call, which runs detached from ctx so one caller giving up does not
+// fail the others; a cancelled caller still returns early. Loader errors are
+// returned as-is and never cached.
+func GetOrSet[T any](ctx context.Context, c *Cache, key string, ttl time.Duration, loader func(context.Context) (T, error)) (T, error) {
+	ctx, span := c.client.tracer.Start(ctx, "cache.get_or_set")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("cache.key", key))
+
+	var zero T
+	if value, ok := cacheGet[T](ctx, c, key); ok {
+		span.SetAttributes(attribute.Bool("cache.hit", true))
+		return value, nil
+	}
+	span.SetAttributes(attribute.Bool("cache.hit", false))
+
+	resultCh := c.group.DoChan(key, func() (interface{}, error) {
+		loadCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), loaderTimeout)
+		defer cancel()
+
+		value, err := loader(loadCtx)
+		if err != nil {
+			return value, err
+		}
+		cacheSet(loadCtx, c, key, value, ttl)
+		return value, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	case result := <-resultCh:
+		span.SetAttributes(attribute.Bool("cache.shared", result.Shared))
+		if result.Err != nil {
+			span.SetAttributes(attribute.Bool("cache.loader_error", true))
+			if value, ok := result.Val.(T); ok {
+				return value, result.Err
+			}
+			return zero, result.Err
+		}
+		return result.Val.(T), nil
+	}
+}
+
+// Delete removes cached entries
+func (c *Cache) Delete(ctx context.Context, keys ...string) error {
+	return c.client.DelWithTracing(ctx, keys...)
+}
+
+// cacheGet reads and decodes a cached value. redis.Nil is a clean miss;
+// read and decode failures are logged and also reported as misses.
+func cacheGet[T any](ctx context.Context, c *Cache, key string) (T, bool) {
+	var value T
+
+	data, err := c.client.GetWithTracing(ctx, key)
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			telemetry.Log(ctx, telemetry.LevelWarn, "Failed to read from cache", err,
+				attribute.String("cache.key", key),
+				attribute.String("error", err.Error()),
+			)
+		}
+		return value, false
+	}
+
+	if err := json.Unmarshal([]byte(data), &value); err != nil {
+		telemetry.Log(ctx, telemetry.LevelWarn, "Failed to decode cached value", err,
+			attribute.String("cache.key", key),
+			attribute.String("error", err.Error()),
+		)
+		return value, false
+	}
+	return value, true
+}
+
+// cacheSet encodes and stores a value, logging failures
+func cacheSet(ctx context.Context, c *Cache, key string, value interface{}, ttl time.Duration) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		telemetry.Log(ctx, telemetry.LevelWarn, "Failed to encode value for cache", err,
+			attribute.String("cache.key", key),
+			attribute.String("error", err.Error()),
+		)
+		return
+	}
+
+	if err := c.client.SetWithTracing(ctx, key, data, ttl); err != nil {
+		telemetry.Log(ctx, telemetry.LevelWarn, "Failed to write to cache", err,
+			attribute.String("cache.key", key),
+			attribute.String("error", err.Error()),
+		)
+	}
+}
@@ -0,0 +1,80 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetOrSetCancelledLeader(t *testing.T) {
+	client, server := newTestClient(t)
+	cache := NewCache(client)
+
+	gets := make(chan string, 2)
+	server.mu.Lock()
+	server.onGet = func(key string) { gets <- key }
+	server.mu.Unlock()
+
+	// The loader fails if the context it was given is done when it finishes
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var calls atomic.Int32
+	loader := func(ctx context.Context) (string, error) {
+		if calls.Add(1) == 1 {
+			close(started)
+		}
+		<-release
+		return "value", ctx.Err()
+	}
+
+	leaderCtx, cancel := context.WithCancel(context.Background())
+	leaderErr := make(chan error, 1)
+	go func() {
+		_, err := GetOrSet(leaderCtx, cache, "user:1", time.Minute, loader)
+		leaderErr <- err
+	}()
+	<-gets
+	<-started
+
+	type result struct {
+		value string
+		err   error
+	}
+	follower := make(chan result, 1)
+	go func() {
+		value, err := GetOrSet(context.Background(), cache, "user:1", time.Minute, loader)
+		follower <- result{value, err}
+	}()
+	<-gets
+	// The follower joins the leader's loader call right after its cache miss
+	time.Sleep(50 * time.Millisecond)
+
+	// The leader gives up while the shared load is still running
+	cancel()
+	select {
+	case err := <-leaderErr:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("leader error = %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("cancelled leader did not return before the load finished")
+	}
+	close(release)
+
+	select {
+	case got := <-follower:
+		if got.err != nil || got.value != "value" {
+			t.Errorf("follower = %q, %v; want the loaded value", got.value, got.err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("follower did not return")
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("loader called %d times, want 1 shared call", n)
+	}
+	if cached, ok := server.get("user:1"); !ok || cached != `"value"` {
+		t.Errorf("cached value = %q, %v; want the loaded value", cached, ok)
+	}
+}
@@ -0,0 +1,84 @@
+package redis
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"go.opentelemetry.io/otel/attribute"
+
+	"go-app/internal/infrastructure/telemetry"
+)
+
+// releaseScript deletes the lock only if it is still held by the caller's token
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// AcquireLock tries to acquire a distributed lock using SET NX PX. When ok is
+// true, release must be called to free the lock before its TTL expires.
+func (c *Client) AcquireLock(ctx context.Context, key string, ttl time.Duration) (release func(), ok bool, err error) {
+	ctx, span := c.tracer.Start(ctx, "redis.lock_acquire")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("redis.key", key),
+		attribute.String("redis.operation", "lock_acquire"),
+		attribute.String("redis.expiration", ttl.String()),
+	)
+
+	token, err := lockToken()
+	if err != nil {
+		span.SetAttributes(attribute.Bool("redis.error", true))
+		return nil, false, fmt.Errorf("failed to generate lock token: %w", err)
+	}
+
+	ok, err = c.SetNX(ctx, key, token, ttl).Result()
+	if err != nil {
+		span.SetAttributes(attribute.Bool("redis.error", true))
+		return nil, false, err
+	}
+
+	span.SetAttributes(attribute.Bool("redis.lock_acquired", ok))
+	if !ok {
+		return nil, false, nil
+	}
+
+	release = func() {
+		// Release with a fresh context so a cancelled caller still frees the lock
+		releaseCtx, span := c.tracer.Start(context.WithoutCancel(ctx), "redis.lock_release")
+		defer span.End()
+
+		span.SetAttributes(
+			attribute.String("redis.key", key),
+			attribute.String("redis.operation", "lock_release"),
+		)
+
+		released, err := releaseScript.Run(releaseCtx, c.Client, []string{key}, token).Int()
+		if err != nil {
+			span.SetAttributes(attribute.Bool("redis.error", true))
+			telemetry.Log(releaseCtx, telemetry.LevelError, "Failed to release redis lock", err,
+				attribute.String("redis.key", key),
+			)
+			return
+		}
+		span.SetAttributes(attribute.Bool("redis.lock_released", released == 1))
+	}
+
+	return release, true, nil
+}
+
+// lockToken returns a random token identifying a lock holder
+func lockToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,82 @@
+package redis
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAcquireLockMutualExclusion(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx := context.Background()
+
+	var holders, maxHolders, acquired atomic.Int32
+	var wg sync.WaitGroup
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				release, ok, err := client.AcquireLock(ctx, "lock:job", time.Minute)
+				if err != nil {
+					t.Errorf("AcquireLock() error = %v", err)
+					return
+				}
+				if !ok {
+					continue
+				}
+				acquired.Add(1)
+				n := holders.Add(1)
+				for {
+					seen := maxHolders.Load()
+					if n <= seen || maxHolders.CompareAndSwap(seen, n) {
+						break
+					}
+				}
+				time.Sleep(time.Millisecond)
+				holders.Add(-1)
+				release()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := maxHolders.Load(); got != 1 {
+		t.Errorf("at most %d goroutines held the lock at once, want 1", got)
+	}
+	if acquired.Load() == 0 {
+		t.Error("the lock was never acquired")
+	}
+}
+
+func TestReleaseWithStaleToken(t *testing.T) {
+	client, server := newTestClient(t)
+	ctx := context.Background()
+
+	staleRelease, ok, err := client.AcquireLock(ctx, "lock:job", 50*time.Millisecond)
+	if err != nil || !ok {
+		t.Fatalf("first AcquireLock() = %v, %v; want the lock", ok, err)
+	}
+	// The first holder's lock expires and another holder takes it
+	time.Sleep(100 * time.Millisecond)
+	release, ok, err := client.AcquireLock(ctx, "lock:job", time.Minute)
+	if err != nil || !ok {
+		t.Fatalf("AcquireLock() after expiry = %v, %v; want the lock", ok, err)
+	}
+	token, _ := server.get("lock:job")
+
+	staleRelease()
+	if got, ok := server.get("lock:job"); !ok || got != token {
+		t.Fatalf("stale release left the lock as %q, %v; want the new holder's token", got, ok)
+	}
+	if _, ok, _ := client.AcquireLock(ctx, "lock:job", time.Minute); ok {
+		t.Error("lock acquired while the new holder still holds it")
+	}
+
+	release()
+	if _, ok := server.get("lock:job"); ok {
+		t.Error("lock still held after its holder released it")
+	}
+}
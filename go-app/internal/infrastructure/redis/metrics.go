@@ -0,0 +1,66 @@
+package redis
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/metric"
+)
+
+// registerPoolMetrics registers instruments observing the connection pool
+// statistics and connection state of the Redis client on each metric collection
+func (c *Client) registerPoolMetrics(meter metric.Meter) error {
+	// Hits, misses and timeouts are cumulative, so they are exported as counters
+	hits, err := meter.Int64ObservableCounter("redis.pool.hits",
+		metric.WithDescription("Times a free connection was found in the pool"),
+		metric.WithUnit("{hit}"))
+	if err != nil {
+		return err
+	}
+	misses, err := meter.Int64ObservableCounter("redis.pool.misses",
+		metric.WithDescription("Times a free connection was not found in the pool"),
+		metric.WithUnit("{miss}"))
+	if err != nil {
+		return err
+	}
+	timeouts, err := meter.Int64ObservableCounter("redis.pool.timeouts",
+		metric.WithDescription("Times a wait for a pool connection timed out"),
+		metric.WithUnit("{timeout}"))
+	if err != nil {
+		return err
+	}
+	totalConns, err := meter.Int64ObservableGauge("redis.pool.total_conns",
+		metric.WithDescription("Connections in the pool"),
+		metric.WithUnit("{connection}"))
+	if err != nil {
+		return err
+	}
+	idleConns, err := meter.Int64ObservableGauge("redis.pool.idle_conns",
+		metric.WithDescription("Idle connections in the pool"),
+		metric.WithUnit("{connection}"))
+	if err != nil {
+		return err
+	}
+
+	connected, err := meter.Int64ObservableGauge("redis.connected",
+		metric.WithDescription("Whether the last connection check reached Redis (1) or not (0)"),
+		metric.WithUnit("1"))
+	if err != nil {
+		return err
+	}
+
+	c.poolRegistration, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		stats := c.PoolStats()
+		o.ObserveInt64(hits, int64(stats.Hits))
+		o.ObserveInt64(misses, int64(stats.Misses))
+		o.ObserveInt64(timeouts, int64(stats.Timeouts))
+		o.ObserveInt64(totalConns, int64(stats.TotalConns))
+		o.ObserveInt64(idleConns, int64(stats.IdleConns))
+		if c.connected.Load() {
+			o.ObserveInt64(connected, 1)
+		} else {
+			o.ObserveInt64(connected, 0)
+		}
+		return nil
+	}, hits, misses, timeouts, totalConns, idleConns, connected)
+	return err
+}
@@ -3,6 +3,7 @@ package redis
 import (
 	"context"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"go-app/internal/infrastructure/config"
@@ -11,13 +12,20 @@ import (
 	"github.com/go-redis/redis/extra/redisotel/v8"
 	"github.com/go-redis/redis/v8"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/trace"
 )
 
 // Client wraps redis.Client with additional functionality
 type Client struct {
 	*redis.Client
-	tracer trace.Tracer
+	tracer           trace.Tracer
+	poolRegistration metric.Registration
+
+	// connected is the result of the last connection check
+	connected   atomic.Bool
+	stopMonitor context.CancelFunc
+	monitorDone chan struct{}
 }
 
 // NewClient creates a new Redis client with best practices configuration
@@ -42,8 +50,9 @@ func NewClient(ctx context.Context, cfg config.RedisConfig, tel *telemetry.Telem
 	// Add OpenTelemetry tracing
 	rdb.AddHook(redisotel.NewTracingHook())
 
-	// Test connection
-	if err := rdb.Ping(ctx).Err(); err != nil {
+	// Test the connection, retrying while Redis starts up
+	if err := pingWithRetry(ctx, rdb, cfg); err != nil {
+		rdb.Close()
 		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
 	}
 
@@ -53,10 +62,107 @@ func NewClient(ctx context.Context, cfg config.RedisConfig, tel *telemetry.Telem
 		attribute.Int("redis.pool_size", cfg.PoolSize),
 	)
 
-	return &Client{
+	client := &Client{
 		Client: rdb,
 		tracer: tel.Tracer,
-	}, nil
+	}
+	client.connected.Store(true)
+
+	if err := client.registerPoolMetrics(tel.Meter); err != nil {
+		rdb.Close()
+		return nil, fmt.Errorf("failed to register Redis pool metrics: %w", err)
+	}
+
+	return client, nil
+}
+
+// maxConnectRetryBackoff caps the delay between startup ping attempts
+const maxConnectRetryBackoff = 30 * time.Second
+
+// pingWithRetry pings Redis, retrying with exponential backoff up to
+// cfg.ConnectRetries times. It stops early when ctx is cancelled.
+func pingWithRetry(ctx context.Context, rdb *redis.Client, cfg config.RedisConfig) error {
+	backoff := time.Duration(cfg.ConnectRetryBackoff) * time.Millisecond
+	attempts := cfg.ConnectRetries + 1
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err = rdb.Ping(ctx).Err(); err == nil {
+			return nil
+		}
+		if attempt == attempts {
+			break
+		}
+
+		telemetry.Log(ctx, telemetry.LevelWarn, "Redis not ready, retrying", err,
+			attribute.Int("redis.attempt", attempt),
+			attribute.Int("redis.max_attempts", attempts),
+			attribute.String("redis.retry_backoff", backoff.String()),
+			attribute.String("error", err.Error()),
+		)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > maxConnectRetryBackoff {
+			backoff = maxConnectRetryBackoff
+		}
+	}
+	return err
+}
+
+// StartMonitor pings Redis every interval until ctx is cancelled or the
+// client is closed, logging when the connection is lost or restored and
+// reporting its state on the redis.connected gauge. The driver redials on
+// its own as commands need connections; the monitor makes outages visible.
+func (c *Client) StartMonitor(ctx context.Context, interval time.Duration) {
+	ctx, c.stopMonitor = context.WithCancel(ctx)
+	c.monitorDone = make(chan struct{})
+	go func() {
+		defer close(c.monitorDone)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			c.checkConnection(ctx, interval)
+		}
+	}()
+}
+
+// checkConnection pings Redis, bounded by timeout, and logs state changes
+func (c *Client) checkConnection(ctx context.Context, timeout time.Duration) {
+	pingCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	err := c.Ping(pingCtx).Err()
+	if ctx.Err() != nil {
+		// Shutting down; the failed ping says nothing about Redis
+		return
+	}
+	connected := err == nil
+	if c.connected.Swap(connected) == connected {
+		return
+	}
+
+	if connected {
+		telemetry.Log(ctx, telemetry.LevelInfo, "Redis connection restored", nil,
+			attribute.String("redis.addr", c.Options().Addr),
+		)
+	} else {
+		telemetry.Log(ctx, telemetry.LevelWarn, "Redis connection lost", err,
+			attribute.String("redis.addr", c.Options().Addr),
+			attribute.String("error", err.Error()),
+		)
+	}
 }
 
 // HealthCheck performs a health check on the Redis connection
@@ -78,8 +184,15 @@ func (c *Client) GetPoolStats() *redis.PoolStats {
 	return c.PoolStats()
 }
 
-// Close closes the Redis client
+// Close stops the connection monitor, if started, and closes the Redis client
 func (c *Client) Close() error {
+	if c.stopMonitor != nil {
+		c.stopMonitor()
+		<-c.monitorDone
+	}
+	if c.poolRegistration != nil {
+		_ = c.poolRegistration.Unregister()
+	}
 	return c.Client.Close()
 }
 
@@ -142,3 +255,112 @@ func (c *Client) DelWithTracing(ctx context.Context, keys ...string) error {
 
 	return err
 }
+
+// IncrWithExpireWithTracing increments a counter and sets its expiration in a
+// single round trip, returning the new value
+func (c *Client) IncrWithExpireWithTracing(ctx context.Context, key string, expiration time.Duration) (int64, error) {
+	ctx, span := c.tracer.Start(ctx, "redis.incr")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("redis.key", key),
+		attribute.String("redis.operation", "incr"),
+		attribute.String("redis.expiration", expiration.String()),
+	)
+
+	var incr *redis.IntCmd
+	_, err := c.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(ctx, key)
+		pipe.Expire(ctx, key, expiration)
+		return nil
+	})
+	if err != nil {
+		span.SetAttributes(attribute.Bool("redis.error", true))
+		return 0, err
+	}
+
+	return incr.Val(), nil
+}
+
+// MGet with tracing and error handling
+func (c *Client) MGetWithTracing(ctx context.Context, keys ...string) ([]interface{}, error) {
+	ctx, span := c.tracer.Start(ctx, "redis.mget")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.StringSlice("redis.keys", keys),
+		attribute.String("redis.operation", "mget"),
+		attribute.Int("redis.key_count", len(keys)),
+	)
+
+	result, err := c.MGet(ctx, keys...).Result()
+	if err != nil {
+		span.SetAttributes(attribute.Bool("redis.error", true))
+	}
+
+	return result, err
+}
+
+// PipelineWithTracing executes the commands queued by fn in a single round trip
+func (c *Client) PipelineWithTracing(ctx context.Context, fn func(redis.Pipeliner) error) ([]redis.Cmder, error) {
+	ctx, span := c.tracer.Start(ctx, "redis.pipeline")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("redis.operation", "pipeline"))
+
+	cmds, err := c.Pipelined(ctx, fn)
+	span.SetAttributes(attribute.Int("redis.command_count", len(cmds)))
+	if err != nil {
+		span.SetAttributes(attribute.Bool("redis.error", true))
+	}
+
+	return cmds, err
+}
+
+// XAddWithTracing appends an entry to a stream and returns its ID. When
+// maxLen is positive the stream is trimmed to about maxLen entries; the
+// approximate trim may keep a few more, but is much cheaper.
+func (c *Client) XAddWithTracing(ctx context.Context, stream string, maxLen int64, values map[string]interface{}) (string, error) {
+	ctx, span := c.tracer.Start(ctx, "redis.xadd")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("redis.key", stream),
+		attribute.String("redis.operation", "xadd"),
+		attribute.Int64("redis.stream.max_len", maxLen),
+	)
+
+	id, err := c.XAdd(ctx, &redis.XAddArgs{
+		Stream: stream,
+		MaxLen: maxLen,
+		Approx: true,
+		Values: values,
+	}).Result()
+	if err != nil {
+		span.SetAttributes(attribute.Bool("redis.error", true))
+	}
+
+	return id, err
+}
+
+// XRevRangeNWithTracing returns up to count of a stream's newest entries,
+// newest first
+func (c *Client) XRevRangeNWithTracing(ctx context.Context, stream string, count int64) ([]redis.XMessage, error) {
+	ctx, span := c.tracer.Start(ctx, "redis.xrevrange")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("redis.key", stream),
+		attribute.String("redis.operation", "xrevrange"),
+		attribute.Int64("redis.count", count),
+	)
+
+	messages, err := c.XRevRangeN(ctx, stream, "+", "-", count).Result()
+	if err != nil {
+		span.SetAttributes(attribute.Bool("redis.error", true))
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("redis.entry_count", len(messages)))
+
+	return messages, nil
+}
@@ -0,0 +1,215 @@
+package redis
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// fakeRedis is an in-process server speaking enough of the Redis protocol for
+// the commands this package sends: PING, GET, SET with EX, PX and NX, and DEL.
+// It has no scripting; EVALSHA reports NOSCRIPT and EVAL runs the
+// compare-and-delete of releaseScript, the only script the package uses.
+type fakeRedis struct {
+	listener net.Listener
+
+	mu     sync.Mutex
+	values map[string]fakeValue
+	// onGet, when set, is called with the key of each GET before it is served
+	onGet func(key string)
+}
+
+// fakeValue is a stored string and its expiry, zero for none
+type fakeValue struct {
+	data    string
+	expires time.Time
+}
+
+// newTestClient starts a fakeRedis and returns a Client connected to it
+func newTestClient(t *testing.T) (*Client, *fakeRedis) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	server := &fakeRedis{listener: listener, values: make(map[string]fakeValue)}
+	go server.serve()
+
+	rdb := redis.NewClient(&redis.Options{Addr: listener.Addr().String()})
+	t.Cleanup(func() {
+		_ = rdb.Close()
+		_ = listener.Close()
+	})
+	return &Client{Client: rdb, tracer: noop.NewTracerProvider().Tracer("test")}, server
+}
+
+// get returns the live value stored under key
+func (s *fakeRedis) get(key string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lookup(key)
+}
+
+// lookup returns the live value under key, dropping it once expired. The
+// caller holds s.mu.
+func (s *fakeRedis) lookup(key string) (string, bool) {
+	value, ok := s.values[key]
+	if !ok {
+		return "", false
+	}
+	if !value.expires.IsZero() && !time.Now().Before(value.expires) {
+		delete(s.values, key)
+		return "", false
+	}
+	return value.data, true
+}
+
+func (s *fakeRedis) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.serveConn(conn)
+	}
+}
+
+func (s *fakeRedis) serveConn(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		s.exec(w, args)
+		if err := w.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// readCommand reads one command, sent as a RESP array of bulk strings
+func readCommand(r *bufio.Reader) ([]string, error) {
+	n, err := readLength(r, '*')
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		size, err := readLength(r, '$')
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+// readLength reads a "<prefix><n>\r\n" header line
+func readLength(r *bufio.Reader, prefix byte) (int, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return 0, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if len(line) == 0 || line[0] != prefix {
+		return 0, fmt.Errorf("unexpected line %q", line)
+	}
+	return strconv.Atoi(line[1:])
+}
+
+func (s *fakeRedis) exec(w *bufio.Writer, args []string) {
+	name := strings.ToUpper(args[0])
+	s.mu.Lock()
+	onGet := s.onGet
+	s.mu.Unlock()
+
+	switch name {
+	case "PING":
+		w.WriteString("+PONG\r\n")
+	case "GET":
+		if onGet != nil {
+			onGet(args[1])
+		}
+		if value, ok := s.get(args[1]); ok {
+			fmt.Fprintf(w, "$%d\r\n%s\r\n", len(value), value)
+			return
+		}
+		w.WriteString("$-1\r\n")
+	case "SET":
+		if s.set(args[1:]) {
+			w.WriteString("+OK\r\n")
+			return
+		}
+		w.WriteString("$-1\r\n")
+	case "DEL":
+		s.mu.Lock()
+		deleted := 0
+		for _, key := range args[1:] {
+			if _, ok := s.lookup(key); ok {
+				delete(s.values, key)
+				deleted++
+			}
+		}
+		s.mu.Unlock()
+		fmt.Fprintf(w, ":%d\r\n", deleted)
+	case "EVALSHA":
+		w.WriteString("-NOSCRIPT No matching script.\r\n")
+	case "EVAL":
+		// EVAL script 1 key token
+		s.mu.Lock()
+		released := 0
+		if value, ok := s.lookup(args[3]); ok && value == args[4] {
+			delete(s.values, args[3])
+			released = 1
+		}
+		s.mu.Unlock()
+		fmt.Fprintf(w, ":%d\r\n", released)
+	default:
+		fmt.Fprintf(w, "-ERR unknown command '%s'\r\n", args[0])
+	}
+}
+
+// set runs SET key value [EX seconds|PX milliseconds] [NX], reporting
+// whether the value was stored
+func (s *fakeRedis) set(args []string) bool {
+	key, value := args[0], fakeValue{data: args[1]}
+	nx := false
+	for i := 2; i < len(args); i++ {
+		switch strings.ToUpper(args[i]) {
+		case "NX":
+			nx = true
+		case "EX", "PX":
+			n, _ := strconv.Atoi(args[i+1])
+			unit := time.Second
+			if strings.EqualFold(args[i], "PX") {
+				unit = time.Millisecond
+			}
+			value.expires = time.Now().Add(time.Duration(n) * unit)
+			i++
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.lookup(key); ok && nx {
+		return false
+	}
+	s.values[key] = value
+	return true
+}
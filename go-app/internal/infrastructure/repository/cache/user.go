@@ -0,0 +1,149 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"go-app/internal/domain/entity"
+	"go-app/internal/domain/repository"
+	"go-app/internal/infrastructure/postgres"
+	"go-app/internal/infrastructure/redis"
+	"go-app/internal/infrastructure/telemetry"
+)
+
+// cachedUser is the cached representation of a user entity
+type cachedUser struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Phone     string    `json:"phone,omitempty"`
+	Role      string    `json:"role,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// UserRepository decorates a UserRepository with a Redis cache-aside read path.
+// Cache failures are logged and fall through to the wrapped repository.
+type UserRepository struct {
+	repository.UserRepository
+	cache *redis.Cache
+	ttl   time.Duration
+}
+
+// NewUserRepository creates a new caching UserRepository
+func NewUserRepository(repo repository.UserRepository, client *redis.Client, ttl time.Duration) repository.UserRepository {
+	return &UserRepository{
+		UserRepository: repo,
+		cache:          redis.NewCache(client),
+		ttl:            ttl,
+	}
+}
+
+// userKey returns the cache key for a user ID
+func userKey(id entity.UserID) string {
+	return fmt.Sprintf("user:%d", int(id))
+}
+
+// GetByID retrieves a user from the cache, loading and caching it on a miss.
+// Reads within a transaction bypass the cache so they see locked, current rows.
+func (r *UserRepository) GetByID(ctx context.Context, id entity.UserID) (*entity.User, error) {
+	if _, ok := postgres.TxFromContext(ctx); ok {
+		return r.UserRepository.GetByID(ctx, id)
+	}
+
+	cached, err := redis.GetOrSet(ctx, r.cache, userKey(id), r.ttl, func(ctx context.Context) (cachedUser, error) {
+		user, err := r.UserRepository.GetByID(ctx, id)
+		if err != nil {
+			return cachedUser{}, err
+		}
+		return toCachedUser(user), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := cached.toEntity()
+	if err != nil {
+		// A stale or corrupt entry; serve from the repository instead
+		telemetry.Log(ctx, telemetry.LevelWarn, "Failed to decode cached user", err,
+			attribute.String("cache.key", userKey(id)),
+			attribute.String("error", err.Error()),
+		)
+		return r.UserRepository.GetByID(ctx, id)
+	}
+	return user, nil
+}
+
+// Update updates the user and invalidates its cache entry
+func (r *UserRepository) Update(ctx context.Context, user *entity.User) error {
+	if err := r.UserRepository.Update(ctx, user); err != nil {
+		return err
+	}
+	r.invalidate(ctx, user.ID())
+	return nil
+}
+
+// Delete removes the user and invalidates its cache entry
+func (r *UserRepository) Delete(ctx context.Context, id entity.UserID) error {
+	if err := r.UserRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+	r.invalidate(ctx, id)
+	return nil
+}
+
+// DeleteByFilter removes the matching users and invalidates their cache entries
+func (r *UserRepository) DeleteByFilter(ctx context.Context, filter repository.UserFilter) ([]entity.UserID, error) {
+	deleted, err := r.UserRepository.DeleteByFilter(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range deleted {
+		r.invalidate(ctx, id)
+	}
+	return deleted, nil
+}
+
+// invalidate removes a user's cache entry once the transaction in ctx, if
+// any, commits. Invalidating earlier would let a concurrent read cache the
+// row as it was before the commit, for the full TTL.
+func (r *UserRepository) invalidate(ctx context.Context, id entity.UserID) {
+	postgres.AfterCommit(ctx, func(ctx context.Context) {
+		key := userKey(id)
+		if err := r.cache.Delete(ctx, key); err != nil {
+			telemetry.Log(ctx, telemetry.LevelError, "Failed to invalidate cached user", err,
+				attribute.String("cache.key", key),
+			)
+		}
+	})
+}
+
+// toCachedUser converts a user entity to its cached form
+func toCachedUser(user *entity.User) cachedUser {
+	return cachedUser{
+		ID:        int(user.ID()),
+		Name:      user.Name().String(),
+		Email:     user.Email().String(),
+		Phone:     user.Phone().String(),
+		Role:      user.Role().String(),
+		CreatedAt: user.CreatedAt(),
+		UpdatedAt: user.UpdatedAt(),
+	}
+}
+
+// toEntity rebuilds a user entity from its cached form
+func (cu cachedUser) toEntity() (*entity.User, error) {
+	user, err := entity.NewUser(cu.Name, cu.Email, cu.Phone)
+	if err != nil {
+		return nil, err
+	}
+	if err := user.UpdateRole(cu.Role); err != nil {
+		return nil, err
+	}
+	user.SetID(entity.UserID(cu.ID))
+	user.SetTimestamps(cu.CreatedAt, cu.UpdatedAt)
+	return user, nil
+}
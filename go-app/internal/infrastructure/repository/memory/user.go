@@ -2,13 +2,18 @@ package memory
 
 import (
 	"context"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 
 	"go-app/internal/domain/entity"
 	"go-app/internal/domain/errors"
+	"go-app/internal/domain/pagination"
+	"go-app/internal/domain/repository"
 	"go-app/internal/infrastructure/telemetry"
 )
 
@@ -60,8 +65,10 @@ func (r *UserRepository) Create(ctx context.Context, user *entity.User) error {
 		}
 	}
 
-	// Assign ID and store user
+	// Assign ID and timestamps, then store user
+	now := time.Now().UTC()
 	user.SetID(r.nextID)
+	user.SetTimestamps(now, now)
 	r.users[r.nextID] = user
 	r.nextID++
 
@@ -73,6 +80,23 @@ func (r *UserRepository) Create(ctx context.Context, user *entity.User) error {
 	return nil
 }
 
+// CreateBatch creates several users, reporting a per-user error for conflicts
+func (r *UserRepository) CreateBatch(ctx context.Context, users []*entity.User) ([]error, error) {
+	ctx, span := r.tracer.Start(ctx, "UserRepository.CreateBatch")
+	span.SetAttributes(
+		attribute.String("db.operation", "INSERT"),
+		attribute.String("db.collection", "users"),
+		attribute.Int("users.count", len(users)),
+	)
+	defer span.End()
+
+	results := make([]error, len(users))
+	for i, user := range users {
+		results[i] = r.Create(ctx, user)
+	}
+	return results, nil
+}
+
 // GetByID retrieves a user by ID
 func (r *UserRepository) GetByID(ctx context.Context, id entity.UserID) (*entity.User, error) {
 	ctx, span := r.tracer.Start(ctx, "UserRepository.GetByID")
@@ -145,27 +169,21 @@ func (r *UserRepository) List(ctx context.Context, limit, offset int) ([]*entity
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	// Convert map to slice for consistent ordering
+	// Sort by ID so pages match the ordered Postgres query
 	allUsers := make([]*entity.User, 0, len(r.users))
 	for _, user := range r.users {
 		allUsers = append(allUsers, user)
 	}
+	sort.Slice(allUsers, func(i, j int) bool { return allUsers[i].ID() < allUsers[j].ID() })
 
-	// Apply pagination
-	start := offset
-	if start < 0 {
-		start = 0
-	}
-	if start >= len(allUsers) {
-		return []*entity.User{}, nil
-	}
+	page := pagination.Slice(allUsers, limit, offset)
 
-	end := start + limit
-	if limit <= 0 || end > len(allUsers) {
-		end = len(allUsers)
+	// Return copies so callers cannot mutate the stored entities
+	users := make([]*entity.User, 0, len(page))
+	for _, user := range page {
+		clone := *user
+		users = append(users, &clone)
 	}
-
-	users := allUsers[start:end]
 	span.SetAttributes(attribute.Int("users.count", len(users)))
 
 	return users, nil
@@ -212,7 +230,8 @@ func (r *UserRepository) Update(ctx context.Context, user *entity.User) error {
 		}
 	}
 
-	// Update user
+	// Update user, keeping the original creation time
+	user.SetTimestamps(r.users[user.ID()].CreatedAt(), time.Now().UTC())
 	r.users[user.ID()] = user
 
 	telemetry.Log(ctx, telemetry.LevelInfo, "User updated in memory", nil,
@@ -262,6 +281,44 @@ func (r *UserRepository) Delete(ctx context.Context, id entity.UserID) error {
 	return nil
 }
 
+// DeleteByFilter removes every user matching filter under the write lock
+func (r *UserRepository) DeleteByFilter(ctx context.Context, filter repository.UserFilter) ([]entity.UserID, error) {
+	ctx, span := r.tracer.Start(ctx, "UserRepository.DeleteByFilter")
+	span.SetAttributes(
+		attribute.String("db.operation", "DELETE"),
+		attribute.String("db.collection", "users"),
+		attribute.Int("filter.ids", len(filter.IDs)),
+		attribute.String("filter.email_domain", filter.EmailDomain),
+	)
+	defer span.End()
+
+	if filter.IsEmpty() {
+		return nil, errors.ErrValidationFailed.WithContext("filter", "empty")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted []entity.UserID
+	for id, user := range r.users {
+		if filter.Matches(user) {
+			deleted = append(deleted, id)
+		}
+	}
+	for _, id := range deleted {
+		delete(r.users, id)
+	}
+	sort.Slice(deleted, func(i, j int) bool { return deleted[i] < deleted[j] })
+	span.SetAttributes(attribute.Int("users.deleted", len(deleted)))
+
+	telemetry.Log(ctx, telemetry.LevelInfo, "Users deleted from memory", nil,
+		attribute.String("db.operation", "DELETE"),
+		attribute.String("db.collection", "users"),
+		attribute.Int("users.deleted", len(deleted)),
+	)
+	return deleted, nil
+}
+
 // ExistsByEmail checks if a user with the given email exists
 func (r *UserRepository) ExistsByEmail(ctx context.Context, email entity.Email) (bool, error) {
 	ctx, span := r.tracer.Start(ctx, "UserRepository.ExistsByEmail")
@@ -301,3 +358,53 @@ func (r *UserRepository) Count(ctx context.Context) (int, error) {
 
 	return count, nil
 }
+
+// Search retrieves users whose field contains query, case-insensitively
+func (r *UserRepository) Search(ctx context.Context, field, query string, limit, offset int) ([]*entity.User, int, error) {
+	ctx, span := r.tracer.Start(ctx, "UserRepository.Search")
+	span.SetAttributes(
+		attribute.String("db.operation", "SELECT"),
+		attribute.String("db.collection", "users"),
+		attribute.String("search.field", field),
+		attribute.Int("limit", limit),
+		attribute.Int("offset", offset),
+	)
+	defer span.End()
+
+	if field != repository.SearchFieldName && field != repository.SearchFieldEmail {
+		err := errors.ErrValidationFailed.WithContext("field", field)
+		telemetry.Log(ctx, telemetry.LevelError, "Unsupported search field", err,
+			attribute.String("db.operation", "SELECT"),
+			attribute.String("db.collection", "users"),
+			attribute.String("search.field", field),
+		)
+		return nil, 0, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	needle := strings.ToLower(query)
+	matches := make([]*entity.User, 0)
+	for _, user := range r.users {
+		value := user.Name().String()
+		if field == repository.SearchFieldEmail {
+			value = user.Email().String()
+		}
+		if strings.Contains(strings.ToLower(value), needle) {
+			matches = append(matches, user)
+		}
+	}
+
+	// Sort by ID so pages are stable across calls
+	sort.Slice(matches, func(i, j int) bool { return matches[i].ID() < matches[j].ID() })
+
+	total := len(matches)
+	users := pagination.Slice(matches, limit, offset)
+	span.SetAttributes(
+		attribute.Int("users.count", len(users)),
+		attribute.Int("total.count", total),
+	)
+
+	return users, total, nil
+}
@@ -0,0 +1,84 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+
+	"go-app/internal/domain/entity"
+)
+
+// seedUsers creates n users in repo
+func seedUsers(t *testing.T, repo *UserRepository, n int) {
+	t.Helper()
+	for i := range n {
+		user, err := entity.NewUser(fmt.Sprintf("User %d", i), fmt.Sprintf("user%d@example.com", i), "")
+		if err != nil {
+			t.Fatalf("NewUser() error = %v", err)
+		}
+		if err := repo.Create(context.Background(), user); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+}
+
+// listIDs lists one page and returns the IDs on it
+func listIDs(t *testing.T, repo *UserRepository, limit, offset int) []entity.UserID {
+	t.Helper()
+	users, err := repo.List(context.Background(), limit, offset)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	ids := make([]entity.UserID, len(users))
+	for i, user := range users {
+		ids[i] = user.ID()
+	}
+	return ids
+}
+
+func TestListStableOrder(t *testing.T) {
+	repo := NewUserRepository()
+	seedUsers(t, repo, 25)
+
+	// Map iteration order varies between calls; pages must not
+	first := listIDs(t, repo, 100, 0)
+	if !slices.IsSorted(first) || len(first) != 25 {
+		t.Fatalf("List() ids = %v, want 25 ids in ascending order", first)
+	}
+	for range 20 {
+		if got := listIDs(t, repo, 100, 0); !slices.Equal(got, first) {
+			t.Fatalf("List() ids = %v, then %v", first, got)
+		}
+	}
+
+	// Consecutive pages cover every user exactly once
+	var paged []entity.UserID
+	for offset := 0; offset < 25; offset += 10 {
+		paged = append(paged, listIDs(t, repo, 10, offset)...)
+	}
+	if !slices.Equal(paged, first) {
+		t.Errorf("paged ids = %v, want %v", paged, first)
+	}
+}
+
+func TestListReturnsCopies(t *testing.T) {
+	repo := NewUserRepository()
+	seedUsers(t, repo, 1)
+
+	users, err := repo.List(context.Background(), 10, 0)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if err := users[0].UpdateName("Changed Name"); err != nil {
+		t.Fatalf("UpdateName() error = %v", err)
+	}
+
+	stored, err := repo.GetByID(context.Background(), users[0].ID())
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if stored.Name() == "Changed Name" {
+		t.Error("mutating a listed user changed the stored user")
+	}
+}
@@ -0,0 +1,142 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"go-app/internal/domain/errors"
+	"go-app/internal/domain/repository"
+	pgclient "go-app/internal/infrastructure/postgres"
+)
+
+// outboxRelayLockID is the advisory lock key held by the transaction claiming
+// outbox messages, so concurrent relays cannot publish out of order
+const outboxRelayLockID = 7_264_012
+
+// maxOutboxErrorLength caps the stored error of a failed publish attempt
+const maxOutboxErrorLength = 1024
+
+// OutboxRepository implements the OutboxRepository interface for PostgreSQL,
+// using the 'outbox' table created by migration 0005_create_outbox.
+type OutboxRepository struct {
+	db *sql.DB
+}
+
+// NewOutboxRepository creates a new OutboxRepository.
+func NewOutboxRepository(db *sql.DB) repository.OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+// conn returns the transaction in ctx, if any, or the database handle
+func (r *OutboxRepository) conn(ctx context.Context) dbtx {
+	if tx, ok := pgclient.TxFromContext(ctx); ok {
+		return tx
+	}
+	return r.db
+}
+
+// Add inserts the message within the transaction in ctx, so it commits or
+// rolls back with the change it describes.
+func (r *OutboxRepository) Add(ctx context.Context, message *repository.OutboxMessage) error {
+	payload, err := json.Marshal(message.Event)
+	if err != nil {
+		return errors.NewDomainErrorWithCause(errors.ErrCodeRepositoryError, "failed to encode outbox event", err)
+	}
+	traceContext, err := json.Marshal(message.TraceContext)
+	if err != nil {
+		return errors.NewDomainErrorWithCause(errors.ErrCodeRepositoryError, "failed to encode outbox trace context", err)
+	}
+
+	query := "INSERT INTO outbox (event_type, event_key, payload, trace_context) VALUES ($1, $2, $3, $4) RETURNING id"
+	if err := r.conn(ctx).QueryRowContext(ctx, query, message.Event.Type, message.Key, string(payload), string(traceContext)).Scan(&message.ID); err != nil {
+		return errors.NewDomainErrorWithCause(errors.ErrCodeRepositoryError, "failed to store outbox event", err).
+			WithContext("event_type", message.Event.Type)
+	}
+	return nil
+}
+
+// ClaimPending takes a transaction-scoped advisory lock and returns the
+// oldest unpublished messages. When another transaction holds the lock, no
+// messages are returned.
+func (r *OutboxRepository) ClaimPending(ctx context.Context, limit int) ([]*repository.OutboxMessage, error) {
+	tx, ok := pgclient.TxFromContext(ctx)
+	if !ok {
+		return nil, errors.NewDomainError(errors.ErrCodeRepositoryError, "claiming outbox messages requires a transaction")
+	}
+
+	var locked bool
+	if err := tx.QueryRowContext(ctx, "SELECT pg_try_advisory_xact_lock($1)", outboxRelayLockID).Scan(&locked); err != nil {
+		return nil, errors.NewDomainErrorWithCause(errors.ErrCodeRepositoryError, "failed to lock outbox", err)
+	}
+	if !locked {
+		return nil, nil
+	}
+
+	rows, err := tx.QueryContext(ctx,
+		"SELECT id, event_key, payload, trace_context, attempts FROM outbox WHERE published_at IS NULL ORDER BY id LIMIT $1",
+		limit)
+	if err != nil {
+		return nil, errors.NewDomainErrorWithCause(errors.ErrCodeRepositoryError, "failed to list outbox messages", err)
+	}
+	defer rows.Close()
+
+	var messages []*repository.OutboxMessage
+	for rows.Next() {
+		var message repository.OutboxMessage
+		var payload, traceContext []byte
+		if err := rows.Scan(&message.ID, &message.Key, &payload, &traceContext, &message.Attempts); err != nil {
+			return nil, errors.NewDomainErrorWithCause(errors.ErrCodeRepositoryError, "failed to scan outbox message", err)
+		}
+		if err := json.Unmarshal(payload, &message.Event); err != nil {
+			return nil, errors.NewDomainErrorWithCause(errors.ErrCodeRepositoryError, "failed to decode outbox event", err).
+				WithContext("outbox_id", message.ID)
+		}
+		if err := json.Unmarshal(traceContext, &message.TraceContext); err != nil {
+			// The event is still worth publishing without its trace link
+			message.TraceContext = nil
+		}
+		messages = append(messages, &message)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDomainErrorWithCause(errors.ErrCodeRepositoryError, "failed to list outbox messages", err)
+	}
+	return messages, nil
+}
+
+// MarkPublished sets published_at on the given messages with one UPDATE.
+func (r *OutboxRepository) MarkPublished(ctx context.Context, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, len(ids))
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = id
+	}
+	query := "UPDATE outbox SET published_at = now() WHERE id IN (" + strings.Join(placeholders, ", ") + ")"
+	if _, err := r.conn(ctx).ExecContext(ctx, query, args...); err != nil {
+		return errors.NewDomainErrorWithCause(errors.ErrCodeRepositoryError, "failed to mark outbox messages published", err)
+	}
+	return nil
+}
+
+// MarkFailed increments the message's attempts and stores the error.
+func (r *OutboxRepository) MarkFailed(ctx context.Context, id int64, cause error) error {
+	message := cause.Error()
+	if len(message) > maxOutboxErrorLength {
+		// Cutting may split a multi-byte rune, which Postgres would reject
+		message = strings.ToValidUTF8(message[:maxOutboxErrorLength], "")
+	}
+	if _, err := r.conn(ctx).ExecContext(ctx,
+		"UPDATE outbox SET attempts = attempts + 1, last_error = $2 WHERE id = $1", id, message,
+	); err != nil {
+		return errors.NewDomainErrorWithCause(errors.ErrCodeRepositoryError, "failed to record outbox publish failure", err).
+			WithContext("outbox_id", id)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package postgres
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	pgclient "go-app/internal/infrastructure/postgres"
+	"go-app/internal/infrastructure/retry"
+)
+
+// SQLSTATEs handled by the repository
+const (
+	sqlStateUniqueViolation      = "23505"
+	sqlStateSerializationFailure = "40001"
+	sqlStateDeadlockDetected     = "40P01"
+	sqlStateAdminShutdown        = "57P01"
+	sqlStateCannotConnectNow     = "57P03"
+)
+
+// read runs an idempotent read, retrying transient failures
+func (r *PostgresUserRepository) read(ctx context.Context, fn func(ctx context.Context) error) error {
+	return r.withRetry(ctx, isRetryableReadError, fn)
+}
+
+// write runs a write, retrying only failures that guarantee it was not applied
+func (r *PostgresUserRepository) write(ctx context.Context, fn func(ctx context.Context) error) error {
+	return r.withRetry(ctx, isRetryableWriteError, fn)
+}
+
+// withRetry runs fn under the repository's retry policy and records the
+// number of retries on the current span as db.retries. Inside a transaction
+// fn runs once, since a failed statement aborts the whole transaction.
+func (r *PostgresUserRepository) withRetry(ctx context.Context, retryable func(error) bool, fn func(ctx context.Context) error) error {
+	if _, ok := pgclient.TxFromContext(ctx); ok || r.retryPolicy.MaxAttempts <= 1 {
+		return fn(ctx)
+	}
+
+	policy := r.retryPolicy
+	policy.Retryable = retryable
+	attempts := 0
+	err := retry.Do(ctx, policy, func(ctx context.Context) error {
+		attempts++
+		return fn(ctx)
+	})
+	if attempts > 1 {
+		trace.SpanFromContext(ctx).SetAttributes(attribute.Int("db.retries", attempts-1))
+	}
+	return err
+}
+
+// isRetryableWriteError reports whether the server rejected a statement in a
+// way that guarantees it had no effect
+func isRetryableWriteError(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == sqlStateSerializationFailure || pgErr.Code == sqlStateDeadlockDetected
+	}
+	return false
+}
+
+// isRetryableReadError also retries connection failures, which are safe to
+// repeat for reads
+func isRetryableReadError(err error) bool {
+	// Timed out or cancelled queries are not repeated; context errors would
+	// otherwise match net.Error below
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if isRetryableWriteError(err) {
+		return true
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return strings.HasPrefix(pgErr.Code, "08") || // connection exception
+			pgErr.Code == sqlStateAdminShutdown ||
+			pgErr.Code == sqlStateCannotConnectNow
+	}
+
+	var netErr net.Error
+	return errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		pgconn.SafeToRetry(err) ||
+		errors.As(err, &netErr)
+}
+
+// isUniqueViolation reports whether a statement failed on a unique index,
+// such as a duplicate email
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == sqlStateUniqueViolation
+}
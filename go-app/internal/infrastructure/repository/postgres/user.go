@@ -3,141 +3,440 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 
 	"go-app/internal/domain/entity"
 	"go-app/internal/domain/errors"
+	"go-app/internal/domain/pagination"
 	"go-app/internal/domain/repository"
+	pgclient "go-app/internal/infrastructure/postgres"
+	"go-app/internal/infrastructure/retry"
 )
 
+// tracer spans repository operations worth seeing apart from the per-query
+// spans that otelsql records
+var tracer = otel.Tracer("go-app/internal/infrastructure/repository/postgres")
+
 // PostgresUserRepository implements the UserRepository interface for PostgreSQL.
 // It requires a 'users' table with the following schema:
 // CREATE TABLE users (
 //
 //	id SERIAL PRIMARY KEY,
 //	name VARCHAR(100) NOT NULL,
-//	email VARCHAR(100) NOT NULL UNIQUE
+//	email VARCHAR(100) NOT NULL,
+//	phone VARCHAR(16),
+//	role VARCHAR(20) NOT NULL DEFAULT 'user',
+//	created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+//	updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+//	deleted_at TIMESTAMP WITH TIME ZONE
 //
 // );
+//
+// A missing phone is stored as NULL. Emails are unique case-insensitively
+// among users that are not soft-deleted, through a partial unique index on
+// lower(email) WHERE deleted_at IS NULL, which lookups use as well.
+// When soft delete is enabled, Delete sets deleted_at and reads skip deleted rows.
+// Outside transactions, reads are retried on transient failures and writes on
+// serialization failures and deadlocks, according to the retry policy.
 type PostgresUserRepository struct {
-	db *sql.DB
+	db          *sql.DB
+	softDelete  bool
+	retryPolicy retry.Policy
+}
+
+// ListOptions controls pagination and visibility of soft-deleted users.
+type ListOptions struct {
+	Limit          int
+	Offset         int
+	IncludeDeleted bool
+}
+
+// userColumns are the columns scanned into a user, in Scan order. A NULL
+// phone reads as the empty string.
+const userColumns = "id, name, email, COALESCE(phone, ''), role, created_at, updated_at"
+
+// dbtx is the query interface shared by *sql.DB and *sql.Tx
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
 }
 
 // NewPostgresUserRepository creates a new PostgresUserRepository.
-func NewPostgresUserRepository(db *sql.DB) repository.UserRepository {
-	return &PostgresUserRepository{db: db}
+func NewPostgresUserRepository(db *sql.DB, softDelete bool, retryPolicy retry.Policy) repository.UserRepository {
+	return &PostgresUserRepository{db: db, softDelete: softDelete, retryPolicy: retryPolicy}
+}
+
+// conn returns the transaction in ctx, if any, or the database handle
+func (r *PostgresUserRepository) conn(ctx context.Context) dbtx {
+	if tx, ok := pgclient.TxFromContext(ctx); ok {
+		return tx
+	}
+	return r.db
+}
+
+// notDeleted returns the filter excluding soft-deleted rows, or an always-true
+// condition when soft delete is disabled.
+func (r *PostgresUserRepository) notDeleted() string {
+	if r.softDelete {
+		return "deleted_at IS NULL"
+	}
+	return "TRUE"
 }
 
 // Create creates a new user in the database.
 func (r *PostgresUserRepository) Create(ctx context.Context, user *entity.User) error {
-	query := "INSERT INTO users (name, email) VALUES ($1, $2) RETURNING id"
+	query := "INSERT INTO users (name, email, phone, role) VALUES ($1, $2, NULLIF($3, ''), $4) RETURNING id, created_at, updated_at"
 	var id entity.UserID
-	err := r.db.QueryRowContext(ctx, query, user.Name().String(), user.Email().String()).Scan(&id)
+	var createdAt, updatedAt time.Time
+	err := r.write(ctx, func(ctx context.Context) error {
+		return r.conn(ctx).QueryRowContext(ctx, query, user.Name().String(), user.Email().String(), user.Phone().String(), user.Role().String()).Scan(&id, &createdAt, &updatedAt)
+	})
 	if err != nil {
+		if isUniqueViolation(err) {
+			return errors.ErrUserAlreadyExists.WithContext("email", user.Email().String())
+		}
 		return errors.NewDomainErrorWithCause(errors.ErrCodeRepositoryError, "failed to create user", err)
 	}
 	user.SetID(id)
+	user.SetTimestamps(createdAt, updatedAt)
 	return nil
 }
 
-// GetByID retrieves a user by ID from the database.
-func (r *PostgresUserRepository) GetByID(ctx context.Context, id entity.UserID) (*entity.User, error) {
-	query := "SELECT id, name, email FROM users WHERE id = $1"
-	row := r.db.QueryRowContext(ctx, query, int(id))
+// CreateBatch inserts users with a single multi-row INSERT. Users whose email
+// is already taken are skipped and reported as ErrUserAlreadyExists.
+func (r *PostgresUserRepository) CreateBatch(ctx context.Context, users []*entity.User) ([]error, error) {
+	results := make([]error, len(users))
+	if len(users) == 0 {
+		return results, nil
+	}
+
+	var query strings.Builder
+	query.WriteString("INSERT INTO users (name, email, phone, role) VALUES ")
+	args := make([]interface{}, 0, len(users)*4)
+	for i, user := range users {
+		if i > 0 {
+			query.WriteString(", ")
+		}
+		fmt.Fprintf(&query, "($%d, $%d, NULLIF($%d, ''), $%d)", i*4+1, i*4+2, i*4+3, i*4+4)
+		args = append(args, user.Name().String(), user.Email().String(), user.Phone().String(), user.Role().String())
+	}
+	query.WriteString(" ON CONFLICT ((lower(email))) WHERE deleted_at IS NULL DO NOTHING RETURNING id, email, created_at, updated_at")
 
+	byEmail := make(map[string]*entity.User, len(users))
+	for _, user := range users {
+		byEmail[user.Email().String()] = user
+	}
+
+	var created map[string]struct{}
+	err := r.write(ctx, func(ctx context.Context) error {
+		created = make(map[string]struct{}, len(users))
+		rows, err := r.conn(ctx).QueryContext(ctx, query.String(), args...)
+		if err != nil {
+			return errors.NewDomainErrorWithCause(errors.ErrCodeRepositoryError, "failed to create users", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var id entity.UserID
+			var email string
+			var createdAt, updatedAt time.Time
+			if err := rows.Scan(&id, &email, &createdAt, &updatedAt); err != nil {
+				return errors.NewDomainErrorWithCause(errors.ErrCodeRepositoryError, "failed to scan created user", err)
+			}
+			if user, ok := byEmail[email]; ok {
+				user.SetID(id)
+				user.SetTimestamps(createdAt, updatedAt)
+				created[email] = struct{}{}
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return errors.NewDomainErrorWithCause(errors.ErrCodeRepositoryError, "failed to create users", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i, user := range users {
+		if _, ok := created[user.Email().String()]; !ok {
+			results[i] = errors.NewDomainError(errors.ErrCodeUserAlreadyExists, "user already exists").WithContext("email", user.Email().String())
+		}
+	}
+	return results, nil
+}
+
+// GetByID retrieves a user by ID from the database. Within a transaction the
+// row is locked until the transaction ends.
+func (r *PostgresUserRepository) GetByID(ctx context.Context, id entity.UserID) (*entity.User, error) {
+	query := "SELECT " + userColumns + " FROM users WHERE id = $1 AND " + r.notDeleted()
+	if _, ok := pgclient.TxFromContext(ctx); ok {
+		query += " FOR UPDATE"
+	}
 	var userID int
-	var name, email string
-	if err := row.Scan(&userID, &name, &email); err != nil {
+	var name, email, phone, role string
+	var createdAt, updatedAt time.Time
+	err := r.read(ctx, func(ctx context.Context) error {
+		return r.conn(ctx).QueryRowContext(ctx, query, int(id)).Scan(&userID, &name, &email, &phone, &role, &createdAt, &updatedAt)
+	})
+	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errors.ErrUserNotFound
 		}
 		return nil, errors.NewDomainErrorWithCause(errors.ErrCodeRepositoryError, "failed to get user by id", err)
 	}
 
-	user, err := entity.NewUser(name, email)
+	user, err := entity.NewUser(name, email, phone)
 	if err != nil {
 		return nil, errors.NewDomainErrorWithCause(errors.ErrCodeInvalidUserData, "failed to create user entity from db data", err)
 	}
+	if err := user.UpdateRole(role); err != nil {
+		return nil, errors.NewDomainErrorWithCause(errors.ErrCodeInvalidUserData, "failed to create user entity from db data", err)
+	}
 	user.SetID(entity.UserID(userID))
+	user.SetTimestamps(createdAt, updatedAt)
 
 	return user, nil
 }
 
 // GetByEmail retrieves a user by email from the database.
 func (r *PostgresUserRepository) GetByEmail(ctx context.Context, email entity.Email) (*entity.User, error) {
-	query := "SELECT id, name, email FROM users WHERE email = $1"
-	row := r.db.QueryRowContext(ctx, query, email.String())
-
+	query := "SELECT " + userColumns + " FROM users WHERE lower(email) = lower($1) AND " + r.notDeleted()
 	var userID int
-	var name, dbEmail string
-	if err := row.Scan(&userID, &name, &dbEmail); err != nil {
+	var name, dbEmail, phone, role string
+	var createdAt, updatedAt time.Time
+	err := r.read(ctx, func(ctx context.Context) error {
+		return r.conn(ctx).QueryRowContext(ctx, query, email.String()).Scan(&userID, &name, &dbEmail, &phone, &role, &createdAt, &updatedAt)
+	})
+	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errors.ErrUserNotFound
 		}
 		return nil, errors.NewDomainErrorWithCause(errors.ErrCodeRepositoryError, "failed to get user by email", err)
 	}
 
-	user, err := entity.NewUser(name, dbEmail)
+	user, err := entity.NewUser(name, dbEmail, phone)
 	if err != nil {
 		return nil, errors.NewDomainErrorWithCause(errors.ErrCodeInvalidUserData, "failed to create user entity from db data", err)
 	}
+	if err := user.UpdateRole(role); err != nil {
+		return nil, errors.NewDomainErrorWithCause(errors.ErrCodeInvalidUserData, "failed to create user entity from db data", err)
+	}
 	user.SetID(entity.UserID(userID))
+	user.SetTimestamps(createdAt, updatedAt)
 
 	return user, nil
 }
 
 // List retrieves all users with optional pagination.
 func (r *PostgresUserRepository) List(ctx context.Context, limit, offset int) ([]*entity.User, error) {
-	query := "SELECT id, name, email FROM users ORDER BY id LIMIT $1 OFFSET $2"
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	return r.ListWithOptions(ctx, ListOptions{Limit: limit, Offset: offset})
+}
+
+// ListWithOptions retrieves users with pagination, optionally including soft-deleted users.
+func (r *PostgresUserRepository) ListWithOptions(ctx context.Context, opts ListOptions) ([]*entity.User, error) {
+	filter := r.notDeleted()
+	if opts.IncludeDeleted {
+		filter = "TRUE"
+	}
+	query := "SELECT " + userColumns + " FROM users WHERE " + filter + " ORDER BY id LIMIT $1 OFFSET $2"
+	limit, offset := pagination.Normalize(opts.Limit, opts.Offset)
+
+	var users []*entity.User
+	err := r.read(ctx, func(ctx context.Context) error {
+		var err error
+		users, err = r.queryUsers(ctx, query, limit, offset)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// queryUsers runs a query selecting userColumns and maps the rows to users
+func (r *PostgresUserRepository) queryUsers(ctx context.Context, query string, args ...interface{}) ([]*entity.User, error) {
+	rows, err := r.conn(ctx).QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, errors.NewDomainErrorWithCause(errors.ErrCodeRepositoryError, "failed to list users", err)
+		return nil, errors.NewDomainErrorWithCause(errors.ErrCodeRepositoryError, "failed to query users", err)
 	}
 	defer rows.Close()
 
 	var users []*entity.User
 	for rows.Next() {
 		var userID int
-		var name, email string
-		if err := rows.Scan(&userID, &name, &email); err != nil {
+		var name, email, phone, role string
+		var createdAt, updatedAt time.Time
+		if err := rows.Scan(&userID, &name, &email, &phone, &role, &createdAt, &updatedAt); err != nil {
 			return nil, errors.NewDomainErrorWithCause(errors.ErrCodeRepositoryError, "failed to scan user row", err)
 		}
 
-		user, err := entity.NewUser(name, email)
+		user, err := entity.NewUser(name, email, phone)
 		if err != nil {
 			return nil, errors.NewDomainErrorWithCause(errors.ErrCodeInvalidUserData, "failed to create user entity from db data", err)
 		}
+		if err := user.UpdateRole(role); err != nil {
+			return nil, errors.NewDomainErrorWithCause(errors.ErrCodeInvalidUserData, "failed to create user entity from db data", err)
+		}
 		user.SetID(entity.UserID(userID))
+		user.SetTimestamps(createdAt, updatedAt)
 		users = append(users, user)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDomainErrorWithCause(errors.ErrCodeRepositoryError, "failed to read user rows", err)
+	}
 
 	return users, nil
 }
 
 // Update updates an existing user in the database.
 func (r *PostgresUserRepository) Update(ctx context.Context, user *entity.User) error {
-	query := "UPDATE users SET name = $1, email = $2 WHERE id = $3"
-	_, err := r.db.ExecContext(ctx, query, user.Name().String(), user.Email().String(), int(user.ID()))
+	query := "UPDATE users SET name = $1, email = $2, phone = NULLIF($3, ''), role = $4 WHERE id = $5 AND " + r.notDeleted() + " RETURNING created_at, updated_at"
+	var createdAt, updatedAt time.Time
+	err := r.write(ctx, func(ctx context.Context) error {
+		return r.conn(ctx).QueryRowContext(ctx, query, user.Name().String(), user.Email().String(), user.Phone().String(), user.Role().String(), int(user.ID())).Scan(&createdAt, &updatedAt)
+	})
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.ErrUserNotFound
+		}
+		if isUniqueViolation(err) {
+			return errors.ErrUserAlreadyExists.WithContext("email", user.Email().String())
+		}
 		return errors.NewDomainErrorWithCause(errors.ErrCodeRepositoryError, "failed to update user", err)
 	}
+	user.SetTimestamps(createdAt, updatedAt)
 	return nil
 }
 
-// Delete removes a user by ID from the database.
+// Delete removes a user by ID from the database, or marks it deleted when soft delete is enabled.
 func (r *PostgresUserRepository) Delete(ctx context.Context, id entity.UserID) error {
 	query := "DELETE FROM users WHERE id = $1"
-	_, err := r.db.ExecContext(ctx, query, int(id))
+	if r.softDelete {
+		query = "UPDATE users SET deleted_at = now() WHERE id = $1 AND deleted_at IS NULL"
+	}
+	var result sql.Result
+	err := r.write(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = r.conn(ctx).ExecContext(ctx, query, int(id))
+		return err
+	})
 	if err != nil {
 		return errors.NewDomainErrorWithCause(errors.ErrCodeRepositoryError, "failed to delete user", err)
 	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return errors.ErrUserNotFound
+	}
+	return nil
+}
+
+// DeleteByFilter removes, or soft-deletes, every user matching filter in a
+// single statement and returns their IDs.
+func (r *PostgresUserRepository) DeleteByFilter(ctx context.Context, filter repository.UserFilter) (_ []entity.UserID, err error) {
+	ctx, span := tracer.Start(ctx, "PostgresUserRepository.DeleteByFilter")
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("db.operation", "DELETE"),
+		attribute.String("db.collection", "users"),
+		attribute.Int("filter.ids", len(filter.IDs)),
+		attribute.String("filter.email_domain", filter.EmailDomain),
+	)
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "delete by filter failed")
+		}
+	}()
+
+	if filter.IsEmpty() {
+		return nil, errors.ErrValidationFailed.WithContext("filter", "empty")
+	}
+
+	var (
+		conditions []string
+		args       []interface{}
+	)
+	if len(filter.IDs) > 0 {
+		placeholders := make([]string, len(filter.IDs))
+		for i, id := range filter.IDs {
+			args = append(args, int(id))
+			placeholders[i] = fmt.Sprintf("$%d", len(args))
+		}
+		conditions = append(conditions, "id IN ("+strings.Join(placeholders, ", ")+")")
+	}
+	if filter.EmailDomain != "" {
+		args = append(args, filter.EmailDomain)
+		conditions = append(conditions, fmt.Sprintf("lower(split_part(email, '@', 2)) = lower($%d)", len(args)))
+	}
+	where := strings.Join(conditions, " AND ")
+
+	query := "DELETE FROM users WHERE " + where + " RETURNING id"
+	if r.softDelete {
+		query = "UPDATE users SET deleted_at = now() WHERE " + where + " AND deleted_at IS NULL RETURNING id"
+	}
+
+	var deleted []entity.UserID
+	err = r.write(ctx, func(ctx context.Context) error {
+		deleted = deleted[:0]
+		rows, err := r.conn(ctx).QueryContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var id entity.UserID
+			if err := rows.Scan(&id); err != nil {
+				return err
+			}
+			deleted = append(deleted, id)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, errors.NewDomainErrorWithCause(errors.ErrCodeRepositoryError, "failed to delete users", err)
+	}
+	span.SetAttributes(attribute.Int("users.deleted", len(deleted)))
+	return deleted, nil
+}
+
+// RestoreByID clears the deleted_at marker of a soft-deleted user. It fails
+// with ErrUserAlreadyExists when the email has been registered again since.
+func (r *PostgresUserRepository) RestoreByID(ctx context.Context, id entity.UserID) error {
+	query := "UPDATE users SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL"
+	var result sql.Result
+	err := r.write(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = r.conn(ctx).ExecContext(ctx, query, int(id))
+		return err
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			return errors.ErrUserAlreadyExists.WithContext("id", id.String())
+		}
+		return errors.NewDomainErrorWithCause(errors.ErrCodeRepositoryError, "failed to restore user", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return errors.ErrUserNotFound
+	}
 	return nil
 }
 
 // ExistsByEmail checks if a user with the given email exists.
 func (r *PostgresUserRepository) ExistsByEmail(ctx context.Context, email entity.Email) (bool, error) {
-	query := "SELECT EXISTS(SELECT 1 FROM users WHERE email = $1)"
+	query := "SELECT EXISTS(SELECT 1 FROM users WHERE lower(email) = lower($1) AND " + r.notDeleted() + ")"
 	var exists bool
-	if err := r.db.QueryRowContext(ctx, query, email.String()).Scan(&exists); err != nil {
+	err := r.read(ctx, func(ctx context.Context) error {
+		return r.conn(ctx).QueryRowContext(ctx, query, email.String()).Scan(&exists)
+	})
+	if err != nil {
 		return false, errors.NewDomainErrorWithCause(errors.ErrCodeRepositoryError, "failed to check if user exists by email", err)
 	}
 	return exists, nil
@@ -145,10 +444,52 @@ func (r *PostgresUserRepository) ExistsByEmail(ctx context.Context, email entity
 
 // Count returns the total number of users.
 func (r *PostgresUserRepository) Count(ctx context.Context) (int, error) {
-	query := "SELECT COUNT(*) FROM users"
+	query := "SELECT COUNT(*) FROM users WHERE " + r.notDeleted()
 	var count int
-	if err := r.db.QueryRowContext(ctx, query).Scan(&count); err != nil {
+	err := r.read(ctx, func(ctx context.Context) error {
+		return r.conn(ctx).QueryRowContext(ctx, query).Scan(&count)
+	})
+	if err != nil {
 		return 0, errors.NewDomainErrorWithCause(errors.ErrCodeRepositoryError, "failed to count users", err)
 	}
 	return count, nil
 }
+
+// Search retrieves users whose field contains query, case-insensitively, along
+// with the total number of matches.
+func (r *PostgresUserRepository) Search(ctx context.Context, field, query string, limit, offset int) ([]*entity.User, int, error) {
+	column, ok := searchColumns[field]
+	if !ok {
+		return nil, 0, errors.ErrValidationFailed.WithContext("field", field)
+	}
+	pattern := "%" + escapeLike(query) + "%"
+	filter := " WHERE " + column + " ILIKE $1 AND " + r.notDeleted()
+	limit, offset = pagination.Normalize(limit, offset)
+
+	var total int
+	var users []*entity.User
+	err := r.read(ctx, func(ctx context.Context) error {
+		if err := r.conn(ctx).QueryRowContext(ctx, "SELECT COUNT(*) FROM users"+filter, pattern).Scan(&total); err != nil {
+			return errors.NewDomainErrorWithCause(errors.ErrCodeRepositoryError, "failed to count matching users", err)
+		}
+		var err error
+		users, err = r.queryUsers(ctx, "SELECT "+userColumns+" FROM users"+filter+" ORDER BY id LIMIT $2 OFFSET $3", pattern, limit, offset)
+		return err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+// searchColumns maps searchable fields to their columns
+var searchColumns = map[string]string{
+	repository.SearchFieldName:  "name",
+	repository.SearchFieldEmail: "email",
+}
+
+// escapeLike escapes LIKE wildcards so the query matches literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
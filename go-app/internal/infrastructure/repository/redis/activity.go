@@ -0,0 +1,83 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go-app/internal/domain/entity"
+	"go-app/internal/domain/errors"
+	"go-app/internal/domain/repository"
+	redisclient "go-app/internal/infrastructure/redis"
+)
+
+// Fields of an activity stream entry
+const (
+	fieldType       = "type"
+	fieldTraceID    = "trace_id"
+	fieldOccurredAt = "occurred_at"
+)
+
+// UserActivityRepository stores each user's activity history in a Redis
+// stream, user:{id}:events, trimmed to about maxLen entries
+type UserActivityRepository struct {
+	client *redisclient.Client
+	maxLen int64
+}
+
+// NewUserActivityRepository creates a Redis stream backed activity repository
+func NewUserActivityRepository(client *redisclient.Client, maxLen int) repository.UserActivityRepository {
+	return &UserActivityRepository{
+		client: client,
+		maxLen: int64(maxLen),
+	}
+}
+
+// activityKey returns the stream key for a user ID
+func activityKey(id entity.UserID) string {
+	return fmt.Sprintf("user:%d:events", int(id))
+}
+
+// Append adds an entry to the user's stream
+func (r *UserActivityRepository) Append(ctx context.Context, userID entity.UserID, activity *repository.UserActivity) error {
+	id, err := r.client.XAddWithTracing(ctx, activityKey(userID), r.maxLen, map[string]interface{}{
+		fieldType:       activity.Type,
+		fieldTraceID:    activity.TraceID,
+		fieldOccurredAt: activity.OccurredAt.UTC().Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		return errors.NewDomainErrorWithCause(errors.ErrCodeRepositoryError, "failed to append user activity", err).
+			WithContext("user_id", int(userID))
+	}
+	activity.ID = id
+	return nil
+}
+
+// Recent reads the newest entries of the user's stream
+func (r *UserActivityRepository) Recent(ctx context.Context, userID entity.UserID, limit int) ([]*repository.UserActivity, error) {
+	messages, err := r.client.XRevRangeNWithTracing(ctx, activityKey(userID), int64(limit))
+	if err != nil {
+		return nil, errors.NewDomainErrorWithCause(errors.ErrCodeRepositoryError, "failed to read user activity", err).
+			WithContext("user_id", int(userID))
+	}
+
+	activities := make([]*repository.UserActivity, 0, len(messages))
+	for _, message := range messages {
+		activity := &repository.UserActivity{
+			ID:      message.ID,
+			Type:    stringField(message.Values, fieldType),
+			TraceID: stringField(message.Values, fieldTraceID),
+		}
+		// Entries with an unreadable time keep the zero time rather than
+		// hiding the rest of the history
+		activity.OccurredAt, _ = time.Parse(time.RFC3339Nano, stringField(message.Values, fieldOccurredAt))
+		activities = append(activities, activity)
+	}
+	return activities, nil
+}
+
+// stringField returns a stream entry field as a string, or "" when missing
+func stringField(values map[string]interface{}, field string) string {
+	value, _ := values[field].(string)
+	return value
+}
@@ -0,0 +1,56 @@
+package retry
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+)
+
+// Policy controls how Do retries an operation
+type Policy struct {
+	MaxAttempts int           // total attempts, including the first; <= 1 disables retries
+	BaseDelay   time.Duration // delay before the second attempt, doubled for each later one
+	MaxDelay    time.Duration // upper bound on the delay; 0 means unbounded
+	Jitter      float64       // randomizes each delay by up to this fraction, 0 to 1
+
+	// Retryable reports whether an error is worth retrying. A nil Retryable
+	// retries every error.
+	Retryable func(err error) bool
+}
+
+// Do calls fn until it succeeds, returns an error the policy does not retry,
+// or the attempts run out. The last error from fn is returned; if ctx ends
+// while waiting between attempts, that error is returned without retrying.
+func Do(ctx context.Context, policy Policy, fn func(ctx context.Context) error) error {
+	attempts := max(policy.MaxAttempts, 1)
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = fn(ctx); err == nil {
+			return nil
+		}
+		if attempt >= attempts || (policy.Retryable != nil && !policy.Retryable(err)) {
+			return err
+		}
+
+		timer := time.NewTimer(policy.Delay(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// Delay returns the wait after the given failed attempt, counting from 1
+func (p Policy) Delay(attempt int) time.Duration {
+	d := p.BaseDelay << (attempt - 1)
+	if d < 0 || (p.MaxDelay > 0 && d > p.MaxDelay) {
+		d = p.MaxDelay
+	}
+	if p.Jitter > 0 {
+		d = time.Duration(float64(d) * (1 + p.Jitter*(2*rand.Float64()-1)))
+	}
+	return d
+}
@@ -0,0 +1,148 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Signal types reported on the exporter failure counter
+const (
+	signalTrace  = "trace"
+	signalMetric = "metric"
+	signalLog    = "log"
+)
+
+// failureRecorder counts failed exports. The counter is attached after the
+// meter provider exists, since the metric exporter itself is decorated.
+type failureRecorder struct {
+	counter atomic.Value // metric.Int64Counter
+}
+
+// setCounter attaches the counter used to record failures
+func (r *failureRecorder) setCounter(counter metric.Int64Counter) {
+	r.counter.Store(counter)
+}
+
+// record increments the failure counter for the given signal and endpoint
+// when err is non-nil
+func (r *failureRecorder) record(ctx context.Context, signal, endpoint string, err error) {
+	if err == nil {
+		return
+	}
+	if counter, ok := r.counter.Load().(metric.Int64Counter); ok {
+		counter.Add(ctx, 1, metric.WithAttributes(
+			attribute.String("signal", signal),
+			attribute.String("endpoint", endpoint),
+		))
+	}
+}
+
+// countingSpanExporter decorates a span exporter with failure counting
+type countingSpanExporter struct {
+	sdktrace.SpanExporter
+	failures *failureRecorder
+	endpoint string
+}
+
+func (e *countingSpanExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	err := e.SpanExporter.ExportSpans(ctx, spans)
+	e.failures.record(ctx, signalTrace, e.endpoint, err)
+	return err
+}
+
+// countingMetricExporter decorates a metric exporter with failure counting
+type countingMetricExporter struct {
+	sdkmetric.Exporter
+	failures *failureRecorder
+	endpoint string
+}
+
+func (e *countingMetricExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	err := e.Exporter.Export(ctx, rm)
+	e.failures.record(ctx, signalMetric, e.endpoint, err)
+	return err
+}
+
+// countingLogExporter decorates a log exporter with failure counting
+type countingLogExporter struct {
+	sdklog.Exporter
+	failures *failureRecorder
+	endpoint string
+}
+
+func (e *countingLogExporter) Export(ctx context.Context, records []sdklog.Record) error {
+	err := e.Exporter.Export(ctx, records)
+	e.failures.record(ctx, signalLog, e.endpoint, err)
+	return err
+}
+
+// onceReader makes a metric reader's Shutdown idempotent, so it can be shut
+// down ahead of the meter provider, which shuts down its readers again
+type onceReader struct {
+	sdkmetric.Reader
+	once sync.Once
+}
+
+func (r *onceReader) Shutdown(ctx context.Context) error {
+	var err error
+	r.once.Do(func() {
+		err = r.Reader.Shutdown(ctx)
+	})
+	return err
+}
+
+// shutdownConcurrently returns a shutdown func that runs fns in parallel, so
+// one slow backend can't use up the shutdown deadline of the others
+func shutdownConcurrently(fns []func(context.Context) error) func(context.Context) error {
+	return func(ctx context.Context) error {
+		errs := make([]error, len(fns))
+		var wg sync.WaitGroup
+		for i, fn := range fns {
+			wg.Add(1)
+			go func(i int, fn func(context.Context) error) {
+				defer wg.Done()
+				errs[i] = fn(ctx)
+			}(i, fn)
+		}
+		wg.Wait()
+		return errors.Join(errs...)
+	}
+}
+
+// Default OTLP/HTTP signal paths, appended to the configured base path
+const (
+	tracesURLPath  = "/v1/traces"
+	metricsURLPath = "/v1/metrics"
+	logsURLPath    = "/v1/logs"
+)
+
+// httpTarget splits an OTLP/HTTP endpoint into the host:port WithEndpoint
+// expects and the URL path of one signal. The endpoint may carry a scheme,
+// which is dropped since TLS follows OTEL_EXPORTER_OTLP_INSECURE, and a base
+// path, which replaces basePath. override, when set, is the whole path.
+func httpTarget(endpoint, basePath, signalPath, override string) (string, string) {
+	host := endpoint
+	if _, rest, ok := strings.Cut(host, "://"); ok {
+		host = rest
+	}
+	if h, path, ok := strings.Cut(host, "/"); ok {
+		host = h
+		if path = strings.Trim(path, "/"); path != "" {
+			basePath = "/" + path
+		}
+	}
+	if override != "" {
+		return host, override
+	}
+	return host, strings.TrimRight(basePath, "/") + signalPath
+}
@@ -0,0 +1,208 @@
+package telemetry
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+
+	"go-app/internal/infrastructure/config"
+)
+
+// captureCollector is an OTLP/HTTP collector that records the path and
+// Content-Encoding of each export it receives. The first failures exports
+// are answered with 503, as by a collector that is briefly unavailable.
+type captureCollector struct {
+	mu        sync.Mutex
+	paths     []string
+	encodings []string
+	failures  int
+}
+
+func (c *captureCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	c.paths = append(c.paths, r.URL.Path)
+	c.encodings = append(c.encodings, r.Header.Get("Content-Encoding"))
+	fail := len(c.paths) <= c.failures
+	c.mu.Unlock()
+	if fail {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (c *captureCollector) received() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.paths)
+}
+
+func (c *captureCollector) receivedEncodings() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.encodings)
+}
+
+// testTraceConfig exports traces only, over insecure OTLP/HTTP, to endpoint
+func testTraceConfig(endpoint string) config.Config {
+	return config.Config{Otel: config.OtelConfig{
+		ServiceName:       "test",
+		TracerName:        "test",
+		Protocol:          "http",
+		Endpoint:          endpoint,
+		Insecure:          true,
+		EnableTraces:      true,
+		TraceSampleRatio:  1,
+		MaxQueueSize:      100,
+		BatchTimeoutSecs:  60,
+		ExportTimeoutSecs: 10,
+		LogOutput:         "stderr",
+	}}
+}
+
+// exportSpan sets up telemetry from cfg and exports a single span
+func exportSpan(t *testing.T, cfg config.Config) error {
+	t.Helper()
+	tracerProvider, logger := otel.GetTracerProvider(), slog.Default()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(tracerProvider)
+		slog.SetDefault(logger)
+	})
+
+	ctx := context.Background()
+	tel, shutdown, err := Setup(ctx, cfg)
+	if err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	t.Cleanup(func() { _ = shutdown(context.Background()) })
+
+	_, span := tel.Tracer.Start(ctx, "test")
+	span.End()
+	return tel.ForceFlush(ctx)
+}
+
+func TestHTTPTarget(t *testing.T) {
+	tests := []struct {
+		name                         string
+		endpoint, basePath, override string
+		wantHost, wantPath           string
+	}{
+		{"default path", "collector:4318", "", "", "collector:4318", "/v1/traces"},
+		{"base path", "collector:4318", "/otlp", "", "collector:4318", "/otlp/v1/traces"},
+		{"base path with trailing slash", "collector:4318", "/otlp/", "", "collector:4318", "/otlp/v1/traces"},
+		{"scheme", "https://collector:4318", "", "", "collector:4318", "/v1/traces"},
+		{"endpoint path", "https://proxy.example.com/otlp", "", "", "proxy.example.com", "/otlp/v1/traces"},
+		{"endpoint path replaces base path", "proxy.example.com/otlp/", "/other", "", "proxy.example.com", "/otlp/v1/traces"},
+		{"endpoint root path", "collector:4318/", "/otlp", "", "collector:4318", "/otlp/v1/traces"},
+		{"override", "proxy.example.com/otlp", "/other", "/custom/traces", "proxy.example.com", "/custom/traces"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host, path := httpTarget(tt.endpoint, tt.basePath, tracesURLPath, tt.override)
+			if host != tt.wantHost || path != tt.wantPath {
+				t.Errorf("httpTarget(%q, %q, %q) = %q, %q; want %q, %q",
+					tt.endpoint, tt.basePath, tt.override, host, path, tt.wantHost, tt.wantPath)
+			}
+		})
+	}
+}
+
+func TestHTTPExporterURLPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint string // appended to the collector address
+		basePath string
+		override string
+		wantPath string
+	}{
+		{"default", "", "", "", "/v1/traces"},
+		{"base path", "", "/otlp", "", "/otlp/v1/traces"},
+		{"endpoint path", "/otlp", "", "", "/otlp/v1/traces"},
+		{"signal override", "", "/otlp", "/custom/traces", "/custom/traces"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collector := &captureCollector{}
+			server := httptest.NewServer(collector)
+			defer server.Close()
+
+			cfg := testTraceConfig(server.URL + tt.endpoint)
+			cfg.Otel.ExporterURLPath = tt.basePath
+			cfg.Otel.TracesURLPath = tt.override
+			if err := exportSpan(t, cfg); err != nil {
+				t.Fatalf("export error = %v", err)
+			}
+
+			if got := collector.received(); !slices.Equal(got, []string{tt.wantPath}) {
+				t.Errorf("collector received %v, want [%s]", got, tt.wantPath)
+			}
+		})
+	}
+}
+
+func TestHTTPExporterCompression(t *testing.T) {
+	tests := []struct {
+		compression string
+		want        string
+	}{
+		{"gzip", "gzip"},
+		{"", "gzip"},
+		{"none", ""},
+	}
+	for _, tt := range tests {
+		t.Run("compression="+tt.compression, func(t *testing.T) {
+			collector := &captureCollector{}
+			server := httptest.NewServer(collector)
+			defer server.Close()
+
+			cfg := testTraceConfig(server.URL)
+			cfg.Otel.ExporterCompression = tt.compression
+			if err := exportSpan(t, cfg); err != nil {
+				t.Fatalf("export error = %v", err)
+			}
+
+			if got := collector.receivedEncodings(); !slices.Equal(got, []string{tt.want}) {
+				t.Errorf("Content-Encoding of exports = %q, want [%q]", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTPExporterRetry(t *testing.T) {
+	tests := []struct {
+		name     string
+		enabled  bool
+		wantErr  bool
+		attempts int
+	}{
+		{"enabled", true, false, 3},
+		{"disabled", false, true, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collector := &captureCollector{failures: 2}
+			server := httptest.NewServer(collector)
+			defer server.Close()
+
+			cfg := testTraceConfig(server.URL)
+			cfg.Otel.ExporterRetryEnabled = tt.enabled
+			cfg.Otel.ExporterRetryInitialIntervalSecs = 1
+			cfg.Otel.ExporterRetryMaxIntervalSecs = 1
+			cfg.Otel.ExporterRetryMaxElapsedSecs = 10
+			err := exportSpan(t, cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("export error = %v, want error %v", err, tt.wantErr)
+			}
+
+			if got := len(collector.received()); got != tt.attempts {
+				t.Errorf("collector received %d exports, want %d", got, tt.attempts)
+			}
+		})
+	}
+}
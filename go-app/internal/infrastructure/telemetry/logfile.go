@@ -0,0 +1,150 @@
+package telemetry
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat names rotated files so that lexical order is age order
+const backupTimeFormat = "20060102T150405.000"
+
+// rotatingFile is an io.WriteCloser that starts a new file once the current
+// one would exceed maxSize, keeping at most maxBackups rotated files no older
+// than maxAge. Zero values disable the respective limit.
+type rotatingFile struct {
+	mu         sync.Mutex
+	path       string
+	maxSize    int64
+	maxAge     time.Duration
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+// newRotatingFile opens (or appends to) the log file at path
+func newRotatingFile(path string, maxSizeMB, maxAgeDays, maxBackups int) (*rotatingFile, error) {
+	if path == "" {
+		return nil, fmt.Errorf("log file path is required")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	f := &rotatingFile{
+		path:       path,
+		maxSize:    int64(maxSizeMB) * 1024 * 1024,
+		maxAge:     time.Duration(maxAgeDays) * 24 * time.Hour,
+		maxBackups: maxBackups,
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+	var rotateErr error
+	if f.maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		// A failed rotation leaves the current file open, so the write still
+		// lands there and rotation is retried on the next one
+		if rotateErr = f.rotate(); f.file == nil {
+			return 0, rotateErr
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	if err == nil {
+		err = rotateErr
+	}
+	return n, err
+}
+
+// Close closes the current file; later writes fail with os.ErrClosed
+func (f *rotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+func (f *rotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+// rotate moves the current file aside and opens a fresh one. When the move
+// fails, the current file is reopened for appending instead. f.file is only
+// nil afterwards if no file could be opened.
+func (f *rotatingFile) rotate() error {
+	var err error
+	if closeErr := f.file.Close(); closeErr != nil {
+		err = fmt.Errorf("failed to close log file: %w", closeErr)
+	} else {
+		backup := f.path + "." + time.Now().UTC().Format(backupTimeFormat)
+		if renameErr := os.Rename(f.path, backup); renameErr != nil {
+			err = fmt.Errorf("failed to rotate log file: %w", renameErr)
+		}
+	}
+
+	if openErr := f.open(); openErr != nil {
+		f.file = nil
+		return errors.Join(err, openErr)
+	}
+	if err != nil {
+		return err
+	}
+
+	f.prune()
+	return nil
+}
+
+// prune removes rotated files beyond maxBackups or older than maxAge.
+// Failures are ignored; they only leave extra files behind.
+func (f *rotatingFile) prune() {
+	backups, err := filepath.Glob(f.path + ".*")
+	if err != nil {
+		return
+	}
+	// Newest first
+	sort.Sort(sort.Reverse(sort.StringSlice(backups)))
+
+	cutoff := time.Now().Add(-f.maxAge)
+	for i, backup := range backups {
+		if f.maxBackups > 0 && i >= f.maxBackups {
+			os.Remove(backup)
+			continue
+		}
+		if f.maxAge > 0 {
+			if info, err := os.Stat(backup); err == nil && info.ModTime().Before(cutoff) {
+				os.Remove(backup)
+			}
+		}
+	}
+}
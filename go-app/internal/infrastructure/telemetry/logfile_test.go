@@ -0,0 +1,138 @@
+package telemetry
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// newTestRotatingFile opens a rotating file in a temporary directory that
+// rotates once it would exceed maxSize bytes
+func newTestRotatingFile(t *testing.T, maxSize int64) *rotatingFile {
+	t.Helper()
+	f, err := newRotatingFile(filepath.Join(t.TempDir(), "app.log"), 0, 0, 0)
+	if err != nil {
+		t.Fatalf("newRotatingFile() error = %v", err)
+	}
+	t.Cleanup(func() { _ = f.Close() })
+	f.maxSize = maxSize
+	return f
+}
+
+// readFile returns the contents of path
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	return string(data)
+}
+
+// backups returns the rotated files next to f, oldest first
+func backups(t *testing.T, f *rotatingFile) []string {
+	t.Helper()
+	matches, err := filepath.Glob(f.path + ".*")
+	if err != nil {
+		t.Fatalf("glob backups: %v", err)
+	}
+	slices.Sort(matches)
+	return matches
+}
+
+func TestRotatingFileRotatesBySize(t *testing.T) {
+	f := newTestRotatingFile(t, 10)
+
+	for _, line := range []string{"first\n", "second\n"} {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatalf("Write(%q) error = %v", line, err)
+		}
+	}
+
+	if got := readFile(t, f.path); got != "second\n" {
+		t.Errorf("current file = %q, want only the write after rotation", got)
+	}
+	rotated := backups(t, f)
+	if len(rotated) != 1 {
+		t.Fatalf("backups = %v, want one", rotated)
+	}
+	if got := readFile(t, rotated[0]); got != "first\n" {
+		t.Errorf("backup = %q, want the write before rotation", got)
+	}
+}
+
+func TestRotatingFilePrune(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxBackups int
+		maxAge     time.Duration
+		want       []string // suffixes of the backups kept
+	}{
+		{"by count", 2, 0, []string{"20240103T000000.000", "20240104T000000.000"}},
+		{"by age", 0, 36 * time.Hour, []string{"20240104T000000.000"}},
+		{"no limits", 0, 0, []string{"20240101T000000.000", "20240102T000000.000", "20240103T000000.000", "20240104T000000.000"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTestRotatingFile(t, 0)
+			f.maxBackups = tt.maxBackups
+			f.maxAge = tt.maxAge
+
+			// One backup per day, the newest written a day ago
+			for i, suffix := range []string{"20240101T000000.000", "20240102T000000.000", "20240103T000000.000", "20240104T000000.000"} {
+				path := f.path + "." + suffix
+				if err := os.WriteFile(path, []byte(suffix), 0o644); err != nil {
+					t.Fatalf("write backup: %v", err)
+				}
+				modTime := time.Now().Add(-time.Duration(4-i) * 24 * time.Hour)
+				if err := os.Chtimes(path, modTime, modTime); err != nil {
+					t.Fatalf("set backup time: %v", err)
+				}
+			}
+
+			f.prune()
+
+			var want []string
+			for _, suffix := range tt.want {
+				want = append(want, f.path+"."+suffix)
+			}
+			if got := backups(t, f); !slices.Equal(got, want) {
+				t.Errorf("backups = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestRotatingFileFailedRotation(t *testing.T) {
+	f := newTestRotatingFile(t, 10)
+	if _, err := f.Write([]byte("first\n")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	// Removing the file out from under the writer makes the rotation's
+	// rename fail
+	if err := os.Remove(f.path); err != nil {
+		t.Fatalf("remove log file: %v", err)
+	}
+	n, err := f.Write([]byte("second\n"))
+	if err == nil {
+		t.Error("Write() error = nil, want the rotation failure")
+	}
+	if n != len("second\n") {
+		t.Errorf("Write() = %d bytes, want the write to land despite the failed rotation", n)
+	}
+
+	if got := readFile(t, f.path); got != "second\n" {
+		t.Errorf("log file = %q, want the write made during the failed rotation", got)
+	}
+
+	// The reopened file keeps taking writes and rotates again
+	if _, err := f.Write([]byte("third\n")); err != nil {
+		t.Fatalf("Write() after a failed rotation error = %v", err)
+	}
+	if got := readFile(t, f.path); got != "third\n" {
+		t.Errorf("log file = %q, want the write after the next rotation", got)
+	}
+}
@@ -3,32 +3,87 @@ package telemetry
 import (
 	"context"
 	"log/slog"
+	"math"
+	"runtime"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	otellog "go.opentelemetry.io/otel/log"
 	"go.opentelemetry.io/otel/trace"
 )
 
 type LogLevel string
 
 const (
+	LevelDebug LogLevel = "debug"
 	LevelInfo  LogLevel = "info"
 	LevelWarn  LogLevel = "warn"
 	LevelError LogLevel = "error"
 )
 
-// Global variable to store the current log verbosity level
+// levelMapping pairs the slog level used for stdout/stderr output with the
+// OTEL severity number carried by exported log records
+type levelMapping struct {
+	slog     slog.Level
+	severity otellog.Severity
+}
+
+// levelMappings is the explicit LogLevel -> slog/OTEL severity table
+var levelMappings = map[LogLevel]levelMapping{
+	LevelDebug: {slog: slog.LevelDebug, severity: otellog.SeverityDebug},
+	LevelInfo:  {slog: slog.LevelInfo, severity: otellog.SeverityInfo},
+	LevelWarn:  {slog: slog.LevelWarn, severity: otellog.SeverityWarn},
+	LevelError: {slog: slog.LevelError, severity: otellog.SeverityError},
+}
+
+// mappingFor returns the mapping for level, falling back to info for unknown levels
+func mappingFor(level LogLevel) levelMapping {
+	if m, ok := levelMappings[level]; ok {
+		return m
+	}
+	return levelMappings[LevelInfo]
+}
+
+// Severity returns the OTEL severity number for the log level
+func (l LogLevel) Severity() otellog.Severity {
+	return mappingFor(l).severity
+}
+
+// severityForSlogLevel returns the OTEL severity mapped to the given slog level
+func severityForSlogLevel(level slog.Level) (otellog.Severity, bool) {
+	for _, m := range levelMappings {
+		if m.slog == level {
+			return m.severity, true
+		}
+	}
+	return otellog.SeverityUndefined, false
+}
+
+// Global variables to store the current log verbosity level and source toggle
 var (
 	logVerbosity int
+	logSource    bool
 	mu           sync.RWMutex
 )
 
+// handlerLevel is the minimum level of the slog handlers installed by
+// setupSlog. It follows the verbosity so debug records reach the output.
+var handlerLevel slog.LevelVar
+
 // SetLogVerbosity sets the global log verbosity level
 func SetLogVerbosity(verbosity int) {
 	mu.Lock()
 	defer mu.Unlock()
 	logVerbosity = verbosity
+
+	if verbosity >= 3 {
+		handlerLevel.Set(slog.LevelDebug)
+	} else {
+		handlerLevel.Set(slog.LevelInfo)
+	}
 }
 
 // GetLogVerbosity gets the current log verbosity level
@@ -38,7 +93,57 @@ func GetLogVerbosity() int {
 	return logVerbosity
 }
 
-// shouldLogMessage determines if a message should be logged based on verbosity level
+// setLogSource toggles whether Log captures the caller's source location
+func setLogSource(enabled bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	logSource = enabled
+}
+
+// getLogSource reports whether Log captures the caller's source location
+func getLogSource() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return logSource
+}
+
+// Info log sampling: with logSampleEvery > 1, only 1 in N info records per
+// message is emitted. Counters are keyed by the message string, so messages
+// built with fmt.Sprintf each get their own counter.
+var (
+	logSampleEvery  atomic.Int64
+	logSampleCounts sync.Map // string -> *atomic.Uint64
+)
+
+// setLogSampleEvery sets the info log sampling rate; values <= 1 disable sampling
+func setLogSampleEvery(n int) {
+	logSampleEvery.Store(int64(n))
+}
+
+// sampleInfo reports whether this occurrence of an info message should be emitted
+func sampleInfo(msg string) bool {
+	every := logSampleEvery.Load()
+	if every <= 1 {
+		return true
+	}
+	counter, ok := logSampleCounts.Load(msg)
+	if !ok {
+		counter, _ = logSampleCounts.LoadOrStore(msg, new(atomic.Uint64))
+	}
+	// Emit the first occurrence, then every Nth
+	return (counter.(*atomic.Uint64).Add(1)-1)%uint64(every) == 0
+}
+
+// shouldLogMessage determines if a message should be logged based on verbosity level.
+//
+//	verbosity | debug | info | warn | error
+//	----------+-------+------+------+------
+//	0         |       |      |      |  x
+//	1         |       |      |  x   |  x
+//	2         |       |  x   |  x   |  x
+//	3+        |  x    |  x   |  x   |  x
+//
+// Span events are recorded regardless of verbosity.
 func shouldLogMessage(level LogLevel) bool {
 	verbosity := GetLogVerbosity()
 
@@ -52,6 +157,9 @@ func shouldLogMessage(level LogLevel) bool {
 	case LevelInfo:
 		// Log info messages only when verbosity is 2 (verbose)
 		return verbosity >= 2
+	case LevelDebug:
+		// Log debug messages only when verbosity is 3 or higher
+		return verbosity >= 3
 	default:
 		return verbosity >= 2
 	}
@@ -62,12 +170,14 @@ func shouldLogMessage(level LogLevel) bool {
 func Log(ctx context.Context, level LogLevel, msg string, err error, attrs ...attribute.KeyValue) {
 	span := trace.SpanFromContext(ctx)
 
-	// For performance, only convert attributes when needed
-	var logAttrs []any
-	shouldLog := shouldLogMessage(level)
+	// For performance, only convert attributes when needed. Sampled-out info
+	// messages are dropped here, before any conversion
+	var logAttrs *[]slog.Attr
+	shouldLog := shouldLogMessage(level) && (level != LevelInfo || sampleInfo(msg))
 
 	if shouldLog {
 		logAttrs = attrsToLogAttrs(attrs)
+		defer putLogAttrs(logAttrs)
 	}
 
 	switch level {
@@ -79,33 +189,147 @@ func Log(ctx context.Context, level LogLevel, msg string, err error, attrs ...at
 			}
 		}
 		if err != nil && shouldLog {
-			logAttrs = append(logAttrs, slog.String("error", err.Error()))
+			*logAttrs = append(*logAttrs, slog.String("error", err.Error()))
 		}
 		if shouldLog {
-			slog.ErrorContext(ctx, msg, logAttrs...)
+			emit(ctx, mappingFor(level).slog, msg, *logAttrs)
 		}
 	case LevelWarn:
 		if span.IsRecording() {
 			span.AddEvent(msg, trace.WithAttributes(attrs...))
 		}
 		if shouldLog {
-			slog.WarnContext(ctx, msg, logAttrs...)
+			emit(ctx, mappingFor(level).slog, msg, *logAttrs)
+		}
+	case LevelDebug:
+		// Diagnostic only: never marks the span as failed, even with an err
+		if span.IsRecording() {
+			span.AddEvent(msg, trace.WithAttributes(attrs...))
+		}
+		if err != nil && shouldLog {
+			*logAttrs = append(*logAttrs, slog.String("error", err.Error()))
+		}
+		if shouldLog {
+			emit(ctx, mappingFor(level).slog, msg, *logAttrs)
 		}
 	default:
 		if span.IsRecording() {
 			span.AddEvent(msg, trace.WithAttributes(attrs...))
 		}
 		if shouldLog {
-			slog.InfoContext(ctx, msg, logAttrs...)
+			emit(ctx, mappingFor(level).slog, msg, *logAttrs)
 		}
 	}
 }
 
-// attrsToLogAttrs converts OTel attributes to slog attributes
-func attrsToLogAttrs(attrs []attribute.KeyValue) []any {
-	logAttrs := make([]any, len(attrs))
-	for i, attr := range attrs {
-		logAttrs[i] = slog.Any(string(attr.Key), attr.Value.AsInterface())
+// emit writes a record to the default slog logger. When source logging is
+// enabled, the caller of Log is recorded instead of this file.
+func emit(ctx context.Context, level slog.Level, msg string, attrs []slog.Attr) {
+	logger := slog.Default()
+	if !logger.Enabled(ctx, level) {
+		return
+	}
+
+	var pc uintptr
+	if getLogSource() {
+		var pcs [1]uintptr
+		// Skip runtime.Callers, emit and Log
+		runtime.Callers(3, pcs[:])
+		pc = pcs[0]
+	}
+
+	record := slog.NewRecord(time.Now(), level, msg, pc)
+	record.AddAttrs(attrs...)
+	_ = logger.Handler().Handle(ctx, record)
+}
+
+// logAttrsPool reuses attribute slices across Log calls; records copy the
+// attributes they are given, so a slice can be returned once emit is done
+var logAttrsPool = sync.Pool{
+	New: func() any {
+		attrs := make([]slog.Attr, 0, 8)
+		return &attrs
+	},
+}
+
+// putLogAttrs returns a slice from attrsToLogAttrs to the pool
+func putLogAttrs(attrs *[]slog.Attr) {
+	// Don't keep unusually large slices alive
+	if cap(*attrs) > 64 {
+		return
+	}
+	*attrs = (*attrs)[:0]
+	logAttrsPool.Put(attrs)
+}
+
+// attrsToLogAttrs converts OTel attributes to slog attributes in a pooled
+// slice. Scalar types are converted directly to avoid boxing through
+// AsInterface. Slices become typed slices, which the JSON handler writes as
+// arrays of the element type. Non-finite floats, which JSON cannot represent,
+// are written as the strings OTLP/JSON uses, so JSON output always parses.
+func attrsToLogAttrs(attrs []attribute.KeyValue) *[]slog.Attr {
+	logAttrs := logAttrsPool.Get().(*[]slog.Attr)
+	for _, attr := range attrs {
+		key := string(attr.Key)
+		switch attr.Value.Type() {
+		case attribute.STRING:
+			*logAttrs = append(*logAttrs, slog.String(key, attr.Value.AsString()))
+		case attribute.INT64:
+			*logAttrs = append(*logAttrs, slog.Int64(key, attr.Value.AsInt64()))
+		case attribute.BOOL:
+			*logAttrs = append(*logAttrs, slog.Bool(key, attr.Value.AsBool()))
+		case attribute.FLOAT64:
+			if f := attr.Value.AsFloat64(); isFinite(f) {
+				*logAttrs = append(*logAttrs, slog.Float64(key, f))
+			} else {
+				*logAttrs = append(*logAttrs, slog.String(key, nonFiniteString(f)))
+			}
+		case attribute.STRINGSLICE:
+			*logAttrs = append(*logAttrs, slog.Any(key, attr.Value.AsStringSlice()))
+		case attribute.INT64SLICE:
+			*logAttrs = append(*logAttrs, slog.Any(key, attr.Value.AsInt64Slice()))
+		case attribute.BOOLSLICE:
+			*logAttrs = append(*logAttrs, slog.Any(key, attr.Value.AsBoolSlice()))
+		case attribute.FLOAT64SLICE:
+			*logAttrs = append(*logAttrs, slog.Any(key, floatSliceValue(attr.Value.AsFloat64Slice())))
+		default:
+			*logAttrs = append(*logAttrs, slog.Any(key, attr.Value.AsInterface()))
+		}
 	}
 	return logAttrs
 }
+
+func isFinite(f float64) bool {
+	return !math.IsNaN(f) && !math.IsInf(f, 0)
+}
+
+// nonFiniteString renders NaN and infinities as OTLP/JSON does
+func nonFiniteString(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case f > 0:
+		return "Infinity"
+	default:
+		return "-Infinity"
+	}
+}
+
+// floatSliceValue returns floats unchanged when every element is finite, or
+// as a []any with the non-finite elements rendered as strings
+func floatSliceValue(floats []float64) any {
+	for _, f := range floats {
+		if isFinite(f) {
+			continue
+		}
+		values := make([]any, len(floats))
+		for i, f := range floats {
+			values[i] = f
+			if !isFinite(f) {
+				values[i] = nonFiniteString(f)
+			}
+		}
+		return values
+	}
+	return floats
+}
@@ -0,0 +1,102 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"math"
+	"reflect"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// captureJSONLogs sends slog output to a JSON handler writing to the
+// returned buffer until the test ends
+func captureJSONLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	logger := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(logger) })
+
+	var buf bytes.Buffer
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+	return &buf
+}
+
+func TestLogJSONAttributes(t *testing.T) {
+	buf := captureJSONLogs(t)
+
+	Log(context.Background(), LevelError, "export failed", nil,
+		attribute.StringSlice("endpoints", []string{"collector-a:4318", "collector-b:4318"}),
+		attribute.StringSlice("empty", []string{}),
+		attribute.Int64Slice("ports", []int64{4317, 4318}),
+		attribute.BoolSlice("healthy", []bool{true, false}),
+		attribute.Float64Slice("ratios", []float64{0.5, math.NaN()}),
+		attribute.Float64("latency", math.Inf(1)),
+		attribute.String("signal", "trace"),
+	)
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("output %q is not JSON: %v", buf.String(), err)
+	}
+
+	want := map[string]any{
+		"endpoints": []any{"collector-a:4318", "collector-b:4318"},
+		"empty":     []any{},
+		"ports":     []any{4317.0, 4318.0},
+		"healthy":   []any{true, false},
+		"ratios":    []any{0.5, "NaN"},
+		"latency":   "Infinity",
+		"signal":    "trace",
+	}
+	for key, value := range want {
+		if got := record[key]; !reflect.DeepEqual(got, value) {
+			t.Errorf("%s = %#v, want %#v", key, got, value)
+		}
+	}
+}
+
+// benchmarkAttrs is a typical set of attributes passed to Log
+var benchmarkAttrs = []attribute.KeyValue{
+	attribute.String("handler", "users"),
+	attribute.String("operation", "get"),
+	attribute.String("user.id", "42"),
+	attribute.Int64("http.status_code", 200),
+	attribute.Bool("cache.hit", true),
+	attribute.Float64("duration_ms", 1.5),
+}
+
+// BenchmarkAttrsToLogAttrs measures the pooled, typed conversion used by Log
+func BenchmarkAttrsToLogAttrs(b *testing.B) {
+	b.ReportAllocs()
+	for b.Loop() {
+		putLogAttrs(attrsToLogAttrs(benchmarkAttrs))
+	}
+}
+
+// BenchmarkAttrsToLogAttrsBoxed measures the previous conversion, which
+// allocated a slice per call and boxed every value with slog.Any
+func BenchmarkAttrsToLogAttrsBoxed(b *testing.B) {
+	b.ReportAllocs()
+	for b.Loop() {
+		logAttrs := make([]any, 0, len(benchmarkAttrs))
+		for _, attr := range benchmarkAttrs {
+			logAttrs = append(logAttrs, slog.Any(string(attr.Key), attr.Value.AsInterface()))
+		}
+		_ = logAttrs
+	}
+}
+
+func TestAttrsToLogAttrsMatchesBoxed(t *testing.T) {
+	logAttrs := attrsToLogAttrs(benchmarkAttrs)
+	defer putLogAttrs(logAttrs)
+
+	for i, attr := range benchmarkAttrs {
+		want := slog.Any(string(attr.Key), attr.Value.AsInterface())
+		if got := (*logAttrs)[i]; !got.Equal(want) {
+			t.Errorf("attr %d = %v, want %v", i, got, want)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package telemetry
+
+import (
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ratioSampler samples root spans by trace ID ratio. The ratio can be changed
+// at runtime with SetTraceSampleRatio.
+type ratioSampler struct {
+	current atomic.Pointer[sdktrace.Sampler]
+}
+
+// traceSampler is the root sampler of the SDK tracer provider
+var traceSampler = newRatioSampler(1)
+
+func newRatioSampler(ratio float64) *ratioSampler {
+	s := &ratioSampler{}
+	s.set(ratio)
+	return s
+}
+
+func (s *ratioSampler) set(ratio float64) {
+	sampler := sdktrace.TraceIDRatioBased(ratio)
+	s.current.Store(&sampler)
+}
+
+func (s *ratioSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	return (*s.current.Load()).ShouldSample(p)
+}
+
+func (s *ratioSampler) Description() string {
+	return (*s.current.Load()).Description()
+}
+
+// SetTraceSampleRatio sets the fraction of new traces that are sampled.
+// Child spans follow their parent's decision regardless of the ratio.
+func SetTraceSampleRatio(ratio float64) {
+	traceSampler.set(ratio)
+}
+
+// errorKeptAttribute marks spans exported only because they ended in error
+var errorKeptAttribute = attribute.Bool("sampling.error_kept", true)
+
+// newTraceSampler returns the tracer provider's sampler. Children follow
+// their parent's decision and new traces use traceSampler's ratio.
+//
+// The outcome of a span is unknown when it starts, so errors cannot be
+// sampled up front. With alwaysSampleErrors, spans that would be dropped are
+// recorded but left unsampled instead, and errorKeepingProcessor exports the
+// ones that end with an Error status (keep + mark). Only the failing spans are
+// kept, not the rest of their trace, and recording every span costs CPU and
+// memory even when few traces are sampled.
+func newTraceSampler(alwaysSampleErrors bool) sdktrace.Sampler {
+	if !alwaysSampleErrors {
+		return sdktrace.ParentBased(traceSampler)
+	}
+	return sdktrace.ParentBased(errorKeepingSampler{traceSampler},
+		sdktrace.WithRemoteParentNotSampled(errorKeepingSampler{sdktrace.NeverSample()}),
+		sdktrace.WithLocalParentNotSampled(errorKeepingSampler{sdktrace.NeverSample()}),
+	)
+}
+
+// errorKeepingSampler defers to sampler, recording the spans it drops
+type errorKeepingSampler struct {
+	sampler sdktrace.Sampler
+}
+
+func (s errorKeepingSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	result := s.sampler.ShouldSample(p)
+	if result.Decision == sdktrace.Drop {
+		result.Decision = sdktrace.RecordOnly
+	}
+	return result
+}
+
+func (s errorKeepingSampler) Description() string {
+	return "ErrorKeeping{" + s.sampler.Description() + "}"
+}
+
+// errorKeepingProcessor passes ended spans to the wrapped processor,
+// presenting unsampled spans that ended in error as sampled so it exports
+// them
+type errorKeepingProcessor struct {
+	sdktrace.SpanProcessor
+}
+
+func (p errorKeepingProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	if !s.SpanContext().IsSampled() && s.Status().Code == codes.Error {
+		s = keptErrorSpan{s}
+	}
+	p.SpanProcessor.OnEnd(s)
+}
+
+// keptErrorSpan is an unsampled span marked sampled and tagged with
+// errorKeptAttribute
+type keptErrorSpan struct {
+	sdktrace.ReadOnlySpan
+}
+
+func (s keptErrorSpan) SpanContext() trace.SpanContext {
+	sc := s.ReadOnlySpan.SpanContext()
+	return sc.WithTraceFlags(sc.TraceFlags().WithSampled(true))
+}
+
+func (s keptErrorSpan) Attributes() []attribute.KeyValue {
+	attrs := s.ReadOnlySpan.Attributes()
+	return append(attrs[:len(attrs):len(attrs)], errorKeptAttribute)
+}
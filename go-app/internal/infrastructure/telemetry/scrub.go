@@ -0,0 +1,105 @@
+package telemetry
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// redactedQueryValue replaces the value of sensitive query parameters
+const redactedQueryValue = "REDACTED"
+
+// sensitiveQueryParams holds the lowercased query parameter names whose
+// values are redacted from span attributes and request logs
+var sensitiveQueryParams atomic.Pointer[map[string]struct{}]
+
+// urlAttributeKeys are the span attributes that may carry a query string,
+// across the old and new HTTP semantic conventions
+var urlAttributeKeys = []attribute.Key{"http.target", "http.url", "url.query", "url.full"}
+
+// SetSensitiveQueryParams sets the query parameter names, matched
+// case-insensitively, whose values are redacted
+func SetSensitiveQueryParams(names ...string) {
+	params := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		params[strings.ToLower(strings.TrimSpace(name))] = struct{}{}
+	}
+	sensitiveQueryParams.Store(&params)
+}
+
+// RedactQuery returns the raw query with the values of sensitive parameters
+// replaced, keeping the order and encoding of every other parameter
+func RedactQuery(rawQuery string) string {
+	params := sensitiveQueryParams.Load()
+	if rawQuery == "" || params == nil || len(*params) == 0 {
+		return rawQuery
+	}
+
+	pairs := strings.Split(rawQuery, "&")
+	for i, pair := range pairs {
+		key, _, hasValue := strings.Cut(pair, "=")
+		name, err := url.QueryUnescape(key)
+		if err != nil {
+			name = key
+		}
+		if _, ok := (*params)[strings.ToLower(name)]; ok && hasValue {
+			pairs[i] = key + "=" + redactedQueryValue
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// RedactURL returns the path and query of u, with sensitive query values redacted
+func RedactURL(u *url.URL) string {
+	if u.RawQuery == "" {
+		return u.Path
+	}
+	return u.Path + "?" + RedactQuery(u.RawQuery)
+}
+
+// redactTarget redacts the query of a request target or full URL
+func redactTarget(target string) string {
+	base, rawQuery, ok := strings.Cut(target, "?")
+	if !ok {
+		return target
+	}
+	fragment := ""
+	if i := strings.IndexByte(rawQuery, '#'); i >= 0 {
+		rawQuery, fragment = rawQuery[:i], rawQuery[i:]
+	}
+	return base + "?" + RedactQuery(rawQuery) + fragment
+}
+
+// queryScrubProcessor redacts sensitive query parameters from the URL
+// attributes of spans as they start. Instrumentation such as otelhttp sets
+// these attributes at span start, so they are scrubbed before any exporter
+// sees the span.
+type queryScrubProcessor struct{}
+
+func (queryScrubProcessor) OnStart(_ context.Context, s sdktrace.ReadWriteSpan) {
+	for _, attr := range s.Attributes() {
+		for _, key := range urlAttributeKeys {
+			if attr.Key != key {
+				continue
+			}
+			value := attr.Value.AsString()
+			var redacted string
+			if key == "url.query" {
+				redacted = RedactQuery(value)
+			} else {
+				redacted = redactTarget(value)
+			}
+			if redacted != value {
+				s.SetAttributes(key.String(redacted))
+			}
+		}
+	}
+}
+
+func (queryScrubProcessor) OnEnd(sdktrace.ReadOnlySpan)      {}
+func (queryScrubProcessor) Shutdown(context.Context) error   { return nil }
+func (queryScrubProcessor) ForceFlush(context.Context) error { return nil }
@@ -5,6 +5,7 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"strings"
@@ -21,8 +22,11 @@ import (
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/log"
 	"go.opentelemetry.io/otel/log/global"
+	lognoop "go.opentelemetry.io/otel/log/noop"
 	"go.opentelemetry.io/otel/metric"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
 	"go.opentelemetry.io/otel/propagation"
 	sdklog "go.opentelemetry.io/otel/sdk/log"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
@@ -30,18 +34,25 @@ import (
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 	"go.opentelemetry.io/otel/trace"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/keepalive"
 )
 
 type Telemetry struct {
-	TracerProvider *sdktrace.TracerProvider
-	MeterProvider  *sdkmetric.MeterProvider
-	LoggerProvider *sdklog.LoggerProvider
-	Tracer         trace.Tracer
-	Meter          metric.Meter
-	UserCounter    metric.Int64Counter
-	LogVerbosity   int
+	TracerProvider   trace.TracerProvider
+	MeterProvider    metric.MeterProvider
+	LoggerProvider   log.LoggerProvider
+	Tracer           trace.Tracer
+	Meter            metric.Meter
+	UserCounter      metric.Int64Counter
+	UserErrors       metric.Int64Counter
+	UserDuration     metric.Float64Histogram
+	ExporterFailures metric.Int64Counter
+	LogVerbosity     int
 }
 
 func Setup(ctx context.Context, cfg config.Config) (*Telemetry, func(context.Context) error, error) {
@@ -74,42 +85,154 @@ func Setup(ctx context.Context, cfg config.Config) (*Telemetry, func(context.Con
 	if protocol == "" {
 		protocol = "http"
 	}
-	slog.Info("Using OTLP protocol", "protocol", protocol, "endpoint", cfg.Otel.Endpoint)
+	tracesEndpoints := otlpEndpoints(cfg.Otel.TracesEndpoints, cfg.Otel.Endpoint)
+	metricsEndpoints := otlpEndpoints(cfg.Otel.MetricsEndpoints, cfg.Otel.Endpoint)
+	logsEndpoints := otlpEndpoints(cfg.Otel.LogsEndpoints, cfg.Otel.Endpoint)
+	slog.Info("Using OTLP protocol", "protocol", protocol,
+		"traces", tracesEndpoints, "metrics", metricsEndpoints, "logs", logsEndpoints)
+
+	// Redact sensitive query parameters from span URLs and request logs
+	SetSensitiveQueryParams(cfg.Otel.QueryParamDenyKeys...)
+
+	// Open the log file before the providers so its shutdown runs last,
+	// after they have flushed
+	var logOutput io.Writer = os.Stdout
+	switch strings.ToLower(cfg.Otel.LogOutput) {
+	case "stderr":
+		logOutput = os.Stderr
+	case "file":
+		logFile, err := newRotatingFile(cfg.Otel.LogFilePath, cfg.Otel.LogFileMaxSizeMB, cfg.Otel.LogFileMaxAgeDays, cfg.Otel.LogFileMaxBackups)
+		if err != nil {
+			return handleErr(err)
+		}
+		shutdowns = append(shutdowns, func(context.Context) error { return logFile.Close() })
+		logOutput = logFile
+	}
 
 	var (
-		spanExporter sdktrace.SpanExporter
-		metricReader sdkmetric.Reader
-		logProcessor sdklog.Processor
+		spanExporters   []sdktrace.SpanExporter
+		metricExporters []sdkmetric.Exporter
+		logExporters    []sdklog.Exporter
 	)
+	failures := &failureRecorder{}
+
+	// The exporters' RetryConfig types share one underlying struct, so this
+	// converts to each of them
+	retry := otlptracegrpc.RetryConfig{
+		Enabled:         cfg.Otel.ExporterRetryEnabled,
+		InitialInterval: time.Duration(cfg.Otel.ExporterRetryInitialIntervalSecs) * time.Second,
+		MaxInterval:     time.Duration(cfg.Otel.ExporterRetryMaxIntervalSecs) * time.Second,
+		MaxElapsedTime:  time.Duration(cfg.Otel.ExporterRetryMaxElapsedSecs) * time.Second,
+	}
+	compress := cfg.Otel.ExporterCompression != "none"
 
 	// --- Exporter setup ---
+	// Exporters are only created for enabled signals, one per endpoint; no
+	// exporters below means the signal gets a no-op provider
 	switch protocol {
 	case "grpc":
-		conn, err := grpc.NewClient(cfg.Otel.Endpoint, grpc.WithTransportCredentials(insecure.NewCredentials()))
-		if err != nil {
-			slog.Error("Failed to connect to OTLP gRPC", "endpoint", cfg.Otel.Endpoint, "err", err)
-			return handleErr(err)
+		dialOpts := []grpc.DialOption{
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			// Keepalive pings stop idle-aggressive proxies from resetting the
+			// long-lived connection; jittered backoff spreads out reconnects
+			grpc.WithKeepaliveParams(keepalive.ClientParameters{
+				Time:    time.Duration(cfg.Otel.GRPCKeepaliveTimeSecs) * time.Second,
+				Timeout: time.Duration(cfg.Otel.GRPCKeepaliveTimeoutSecs) * time.Second,
+			}),
+			grpc.WithConnectParams(grpc.ConnectParams{
+				Backoff: backoff.Config{
+					BaseDelay:  time.Duration(cfg.Otel.GRPCBackoffBaseDelayMs) * time.Millisecond,
+					Multiplier: cfg.Otel.GRPCBackoffMultiplier,
+					Jitter:     cfg.Otel.GRPCBackoffJitter,
+					MaxDelay:   time.Duration(cfg.Otel.GRPCBackoffMaxDelayMs) * time.Millisecond,
+				},
+				MinConnectTimeout: time.Duration(cfg.Otel.GRPCMinConnectTimeoutSecs) * time.Second,
+			}),
 		}
-		spanExporter, err = otlptracegrpc.New(ctx, otlptracegrpc.WithGRPCConn(conn))
-		if err != nil {
-			return handleErr(fmt.Errorf("trace exporter gRPC: %w", err))
+		// The exporters ignore WithCompressor on a caller-supplied connection,
+		// so compression is set on the connection itself
+		if compress {
+			dialOpts = append(dialOpts, grpc.WithDefaultCallOptions(grpc.UseCompressor(gzip.Name)))
 		}
-		metricExp, err := otlpmetricgrpc.New(ctx, otlpmetricgrpc.WithGRPCConn(conn))
-		if err != nil {
-			return handleErr(fmt.Errorf("metric exporter gRPC: %w", err))
+
+		// Signals sending to the same endpoint share its connection. The
+		// exporters don't close a caller-supplied connection, so each is
+		// closed after the providers have flushed
+		conns := make(map[string]*grpc.ClientConn)
+		dial := func(endpoint string) (*grpc.ClientConn, error) {
+			if conn, ok := conns[endpoint]; ok {
+				return conn, nil
+			}
+			conn, err := grpc.NewClient(endpoint, dialOpts...)
+			if err != nil {
+				slog.Error("Failed to connect to OTLP gRPC", "endpoint", endpoint, "err", err)
+				return nil, err
+			}
+			conns[endpoint] = conn
+			shutdowns = append(shutdowns, func(context.Context) error { return conn.Close() })
+			return conn, nil
 		}
-		metricReader = sdkmetric.NewPeriodicReader(metricExp)
 
-		logExp, err := otlploggrpc.New(ctx, otlploggrpc.WithGRPCConn(conn))
-		if err != nil {
-			return handleErr(fmt.Errorf("log exporter gRPC: %w", err))
+		if cfg.Otel.EnableTraces {
+			for _, endpoint := range tracesEndpoints {
+				conn, err := dial(endpoint)
+				if err != nil {
+					return handleErr(err)
+				}
+				traceExp, err := otlptracegrpc.New(ctx, otlptracegrpc.WithGRPCConn(conn), otlptracegrpc.WithRetry(retry))
+				if err != nil {
+					return handleErr(fmt.Errorf("trace exporter gRPC %s: %w", endpoint, err))
+				}
+				spanExporters = append(spanExporters, &countingSpanExporter{SpanExporter: traceExp, failures: failures, endpoint: endpoint})
+			}
+		}
+
+		if cfg.Otel.EnableMetrics {
+			for _, endpoint := range metricsEndpoints {
+				conn, err := dial(endpoint)
+				if err != nil {
+					return handleErr(err)
+				}
+				metricExp, err := otlpmetricgrpc.New(ctx, otlpmetricgrpc.WithGRPCConn(conn), otlpmetricgrpc.WithRetry(otlpmetricgrpc.RetryConfig(retry)))
+				if err != nil {
+					return handleErr(fmt.Errorf("metric exporter gRPC %s: %w", endpoint, err))
+				}
+				metricExporters = append(metricExporters, &countingMetricExporter{Exporter: metricExp, failures: failures, endpoint: endpoint})
+			}
+		}
+
+		if cfg.Otel.EnableLogs {
+			for _, endpoint := range logsEndpoints {
+				conn, err := dial(endpoint)
+				if err != nil {
+					return handleErr(err)
+				}
+				logExp, err := otlploggrpc.New(ctx, otlploggrpc.WithGRPCConn(conn), otlploggrpc.WithRetry(otlploggrpc.RetryConfig(retry)))
+				if err != nil {
+					return handleErr(fmt.Errorf("log exporter gRPC %s: %w", endpoint, err))
+				}
+				logExporters = append(logExporters, &countingLogExporter{Exporter: logExp, failures: failures, endpoint: endpoint})
+			}
 		}
-		logProcessor = newBatchProcessor(logExp, cfg.Otel)
 
 	default: // HTTP
-		traceOpts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.Otel.Endpoint)}
-		metricOpts := []otlpmetrichttp.Option{otlpmetrichttp.WithEndpoint(cfg.Otel.Endpoint)}
-		logOpts := []otlploghttp.Option{otlploghttp.WithEndpoint(cfg.Otel.Endpoint)}
+		// Options shared by every endpoint; the endpoint and URL path are added
+		// per exporter
+		traceOpts := []otlptracehttp.Option{
+			otlptracehttp.WithRetry(otlptracehttp.RetryConfig(retry)),
+		}
+		metricOpts := []otlpmetrichttp.Option{
+			otlpmetrichttp.WithRetry(otlpmetrichttp.RetryConfig(retry)),
+		}
+		logOpts := []otlploghttp.Option{
+			otlploghttp.WithRetry(otlploghttp.RetryConfig(retry)),
+		}
+
+		if compress {
+			traceOpts = append(traceOpts, otlptracehttp.WithCompression(otlptracehttp.GzipCompression))
+			metricOpts = append(metricOpts, otlpmetrichttp.WithCompression(otlpmetrichttp.GzipCompression))
+			logOpts = append(logOpts, otlploghttp.WithCompression(otlploghttp.GzipCompression))
+		}
 
 		// Add basic auth headers if credentials are provided
 		if cfg.Otel.Username != "" && cfg.Otel.Password != "" {
@@ -127,49 +250,112 @@ func Setup(ctx context.Context, cfg config.Config) (*Telemetry, func(context.Con
 			traceOpts = append(traceOpts, otlptracehttp.WithInsecure())
 			metricOpts = append(metricOpts, otlpmetrichttp.WithInsecure())
 			logOpts = append(logOpts, otlploghttp.WithInsecure())
-			slog.Warn("Using insecure HTTP connection", "endpoint", cfg.Otel.Endpoint)
+			slog.Warn("Using insecure HTTP connection", "traces", tracesEndpoints, "metrics", metricsEndpoints, "logs", logsEndpoints)
 		}
 
-		spanExporter, err = otlptracehttp.New(ctx, traceOpts...)
-		if err != nil {
-			slog.Warn("OTLP trace exporter unreachable", "endpoint", cfg.Otel.Endpoint, "err", err)
-			return handleErr(err)
+		if cfg.Otel.EnableTraces {
+			for _, endpoint := range tracesEndpoints {
+				host, path := httpTarget(endpoint, cfg.Otel.ExporterURLPath, tracesURLPath, cfg.Otel.TracesURLPath)
+				traceExp, err := otlptracehttp.New(ctx, append([]otlptracehttp.Option{otlptracehttp.WithEndpoint(host), otlptracehttp.WithURLPath(path)}, traceOpts...)...)
+				if err != nil {
+					slog.Warn("OTLP trace exporter unreachable", "endpoint", endpoint, "err", err)
+					return handleErr(err)
+				}
+				spanExporters = append(spanExporters, &countingSpanExporter{SpanExporter: traceExp, failures: failures, endpoint: endpoint})
+			}
 		}
 
-		metricExp, err := otlpmetrichttp.New(ctx, metricOpts...)
-		if err != nil {
-			slog.Warn("OTLP metric exporter unreachable", "endpoint", cfg.Otel.Endpoint, "err", err)
-			return handleErr(err)
+		if cfg.Otel.EnableMetrics {
+			for _, endpoint := range metricsEndpoints {
+				host, path := httpTarget(endpoint, cfg.Otel.ExporterURLPath, metricsURLPath, cfg.Otel.MetricsURLPath)
+				metricExp, err := otlpmetrichttp.New(ctx, append([]otlpmetrichttp.Option{otlpmetrichttp.WithEndpoint(host), otlpmetrichttp.WithURLPath(path)}, metricOpts...)...)
+				if err != nil {
+					slog.Warn("OTLP metric exporter unreachable", "endpoint", endpoint, "err", err)
+					return handleErr(err)
+				}
+				metricExporters = append(metricExporters, &countingMetricExporter{Exporter: metricExp, failures: failures, endpoint: endpoint})
+			}
 		}
-		metricReader = sdkmetric.NewPeriodicReader(metricExp)
 
-		logExp, err := otlploghttp.New(ctx, logOpts...)
-		if err != nil {
-			slog.Warn("OTLP log exporter unreachable", "endpoint", cfg.Otel.Endpoint, "err", err)
-			return handleErr(err)
+		if cfg.Otel.EnableLogs {
+			for _, endpoint := range logsEndpoints {
+				host, path := httpTarget(endpoint, cfg.Otel.ExporterURLPath, logsURLPath, cfg.Otel.LogsURLPath)
+				logExp, err := otlploghttp.New(ctx, append([]otlploghttp.Option{otlploghttp.WithEndpoint(host), otlploghttp.WithURLPath(path)}, logOpts...)...)
+				if err != nil {
+					slog.Warn("OTLP log exporter unreachable", "endpoint", endpoint, "err", err)
+					return handleErr(err)
+				}
+				logExporters = append(logExporters, &countingLogExporter{Exporter: logExp, failures: failures, endpoint: endpoint})
+			}
 		}
-		logProcessor = newBatchProcessor(logExp, cfg.Otel)
 	}
 
 	// --- Providers ---
-	tracerProvider := sdktrace.NewTracerProvider(
-		sdktrace.WithBatcher(spanExporter,
-			sdktrace.WithMaxQueueSize(cfg.Otel.MaxQueueSize),
-			sdktrace.WithBatchTimeout(time.Duration(cfg.Otel.BatchTimeoutSecs)*time.Second),
-			sdktrace.WithExportTimeout(time.Duration(cfg.Otel.ExportTimeoutSecs)*time.Second)),
-		sdktrace.WithResource(res),
-	)
-	meterProvider := sdkmetric.NewMeterProvider(
-		sdkmetric.WithReader(metricReader),
-		sdkmetric.WithResource(res),
-	)
-	loggerProvider := sdklog.NewLoggerProvider(
-		sdklog.WithProcessor(logProcessor),
-		sdklog.WithResource(res),
-	)
+	// Disabled signals get no-op providers and register no shutdown. Each
+	// exporter gets its own batch processor or reader, with its own queue and
+	// export goroutine, so a slow or failing backend doesn't hold back the
+	// others. The processors are shut down concurrently before their provider
+	// for the same reason; the provider's own shutdown of them is then a no-op.
+	var tracerProvider trace.TracerProvider = tracenoop.NewTracerProvider()
+	if len(spanExporters) > 0 {
+		SetTraceSampleRatio(cfg.Otel.TraceSampleRatio)
+
+		opts := []sdktrace.TracerProviderOption{
+			sdktrace.WithSpanLimits(newSpanLimits(cfg.Otel)),
+			sdktrace.WithSampler(newTraceSampler(cfg.Otel.AlwaysSampleErrors)),
+			sdktrace.WithResource(res),
+			sdktrace.WithSpanProcessor(queryScrubProcessor{}),
+		}
+		var processorShutdowns []func(context.Context) error
+		for _, exp := range spanExporters {
+			processor := sdktrace.NewBatchSpanProcessor(exp,
+				sdktrace.WithMaxQueueSize(cfg.Otel.MaxQueueSize),
+				sdktrace.WithBatchTimeout(time.Duration(cfg.Otel.BatchTimeoutSecs)*time.Second),
+				sdktrace.WithExportTimeout(time.Duration(cfg.Otel.ExportTimeoutSecs)*time.Second))
+			if cfg.Otel.AlwaysSampleErrors {
+				opts = append(opts, sdktrace.WithSpanProcessor(errorKeepingProcessor{processor}))
+			} else {
+				opts = append(opts, sdktrace.WithSpanProcessor(processor))
+			}
+			processorShutdowns = append(processorShutdowns, processor.Shutdown)
+		}
+
+		sdkTracerProvider := sdktrace.NewTracerProvider(opts...)
+		shutdowns = append(shutdowns, sdkTracerProvider.Shutdown, shutdownConcurrently(processorShutdowns))
+		tracerProvider = sdkTracerProvider
+	}
+
+	var meterProvider metric.MeterProvider = metricnoop.NewMeterProvider()
+	if len(metricExporters) > 0 {
+		opts := []sdkmetric.Option{sdkmetric.WithResource(res)}
+		var readerShutdowns []func(context.Context) error
+		for _, exp := range metricExporters {
+			reader := &onceReader{Reader: sdkmetric.NewPeriodicReader(exp,
+				sdkmetric.WithInterval(time.Duration(cfg.Otel.MetricExportIntervalSecs)*time.Second),
+				sdkmetric.WithTimeout(time.Duration(cfg.Otel.ExportTimeoutSecs)*time.Second))}
+			opts = append(opts, sdkmetric.WithReader(reader))
+			readerShutdowns = append(readerShutdowns, reader.Shutdown)
+		}
 
-	// Register shutdowns
-	shutdowns = append(shutdowns, tracerProvider.Shutdown, meterProvider.Shutdown, loggerProvider.Shutdown)
+		sdkMeterProvider := sdkmetric.NewMeterProvider(opts...)
+		shutdowns = append(shutdowns, sdkMeterProvider.Shutdown, shutdownConcurrently(readerShutdowns))
+		meterProvider = sdkMeterProvider
+	}
+
+	var loggerProvider log.LoggerProvider = lognoop.NewLoggerProvider()
+	if len(logExporters) > 0 {
+		opts := []sdklog.LoggerProviderOption{sdklog.WithResource(res)}
+		var processorShutdowns []func(context.Context) error
+		for _, exp := range logExporters {
+			processor := newBatchProcessor(exp, cfg.Otel)
+			opts = append(opts, sdklog.WithProcessor(&severityProcessor{Processor: processor}))
+			processorShutdowns = append(processorShutdowns, processor.Shutdown)
+		}
+
+		sdkLoggerProvider := sdklog.NewLoggerProvider(opts...)
+		shutdowns = append(shutdowns, sdkLoggerProvider.Shutdown, shutdownConcurrently(processorShutdowns))
+		loggerProvider = sdkLoggerProvider
+	}
 
 	// Set globals
 	otel.SetTracerProvider(tracerProvider)
@@ -178,9 +364,10 @@ func Setup(ctx context.Context, cfg config.Config) (*Telemetry, func(context.Con
 	otel.SetTextMapPropagator(propagation.TraceContext{})
 
 	// Configure slog based on configuration
-	setupSlog(cfg.Otel, loggerProvider)
+	setupSlog(cfg.Otel, loggerProvider, logOutput)
 
-	// Create meter and instruments before starting runtime metrics
+	// Create meter and instruments before starting runtime metrics. With
+	// metrics disabled these come from the no-op provider and record nothing
 	meter := meterProvider.Meter(cfg.Otel.MeterName)
 	userCounter, err := meter.Int64Counter("user_operations_total",
 		metric.WithDescription("Counts user operations"),
@@ -188,24 +375,88 @@ func Setup(ctx context.Context, cfg config.Config) (*Telemetry, func(context.Con
 	if err != nil {
 		return handleErr(fmt.Errorf("failed to create user counter: %w", err))
 	}
+	userErrors, err := meter.Int64Counter("user.errors.total",
+		metric.WithDescription("Counts failed user operations by domain error code"),
+		metric.WithUnit("{error}"))
+	if err != nil {
+		return handleErr(fmt.Errorf("failed to create user error counter: %w", err))
+	}
+	// Milliseconds with the SDK default buckets, like otelhttp's
+	// http.server.duration, so the two histograms line up
+	userDuration, err := meter.Float64Histogram("user.operation.duration",
+		metric.WithDescription("Measures the duration of user service operations"),
+		metric.WithUnit("ms"))
+	if err != nil {
+		return handleErr(fmt.Errorf("failed to create user duration histogram: %w", err))
+	}
+	exporterFailures, err := meter.Int64Counter("otel.exporter.failures.total",
+		metric.WithDescription("Counts failed telemetry exports"),
+		metric.WithUnit("{failure}"))
+	if err != nil {
+		return handleErr(fmt.Errorf("failed to create exporter failure counter: %w", err))
+	}
+	failures.setCounter(exporterFailures)
 
 	// Start runtime metrics collection
-	if err := runtime.Start(runtime.WithMeterProvider(meterProvider)); err != nil {
-		slog.Error("Failed to start runtime metrics", "err", err)
-		return handleErr(err)
+	if cfg.Otel.EnableMetrics {
+		if err := runtime.Start(runtime.WithMeterProvider(meterProvider)); err != nil {
+			slog.Error("Failed to start runtime metrics", "err", err)
+			return handleErr(err)
+		}
 	}
 
 	return &Telemetry{
-		TracerProvider: tracerProvider,
-		MeterProvider:  meterProvider,
-		LoggerProvider: loggerProvider,
-		Tracer:         tracerProvider.Tracer(cfg.Otel.TracerName),
-		Meter:          meter,
-		UserCounter:    userCounter,
-		LogVerbosity:   cfg.Otel.LogVerbosity,
+		TracerProvider:   tracerProvider,
+		MeterProvider:    meterProvider,
+		LoggerProvider:   loggerProvider,
+		Tracer:           tracerProvider.Tracer(cfg.Otel.TracerName),
+		Meter:            meter,
+		UserCounter:      userCounter,
+		UserErrors:       userErrors,
+		UserDuration:     userDuration,
+		ExporterFailures: exporterFailures,
+		LogVerbosity:     cfg.Otel.LogVerbosity,
 	}, shutdown, nil
 }
 
+// flusher is implemented by the SDK providers; the no-op providers of
+// disabled signals have nothing to flush
+type flusher interface {
+	ForceFlush(ctx context.Context) error
+}
+
+// ForceFlush exports everything the providers have buffered without waiting
+// for their intervals. Traces and logs are flushed before metrics, so the
+// final metric collection includes export failures from flushing them.
+func (t *Telemetry) ForceFlush(ctx context.Context) error {
+	var err error
+	for _, provider := range []interface{}{t.TracerProvider, t.LoggerProvider, t.MeterProvider} {
+		if f, ok := provider.(flusher); ok {
+			err = errors.Join(err, f.ForceFlush(ctx))
+		}
+	}
+	return err
+}
+
+// otlpEndpoints returns the endpoints configured for a signal, falling back
+// to the shared OTLP endpoint
+func otlpEndpoints(endpoints []string, fallback string) []string {
+	if len(endpoints) == 0 {
+		return []string{fallback}
+	}
+	return endpoints
+}
+
+// newSpanLimits returns the SDK defaults with the configured attribute and
+// event limits applied
+func newSpanLimits(cfg config.OtelConfig) sdktrace.SpanLimits {
+	limits := sdktrace.NewSpanLimits()
+	limits.AttributeCountLimit = cfg.SpanAttributeCountLimit
+	limits.AttributeValueLengthLimit = cfg.SpanAttributeValueLengthLimit
+	limits.EventCountLimit = cfg.SpanEventCountLimit
+	return limits
+}
+
 func newBatchProcessor(exp sdklog.Exporter, cfg config.OtelConfig) sdklog.Processor {
 	return sdklog.NewBatchProcessor(exp,
 		sdklog.WithMaxQueueSize(cfg.MaxQueueSize),
@@ -214,35 +465,66 @@ func newBatchProcessor(exp sdklog.Exporter, cfg config.OtelConfig) sdklog.Proces
 	)
 }
 
-// setupSlog configures slog with stdout/stderr + OTEL output
-func setupSlog(cfg config.OtelConfig, loggerProvider *sdklog.LoggerProvider) {
-	var loggers []*slog.Logger
+// severityProcessor sets the OTEL severity of bridged slog records from the
+// explicit LogLevel mapping instead of relying on the otelslog offset
+type severityProcessor struct {
+	sdklog.Processor
+}
 
-	// Add stdout/stderr logger if not OTEL-only
-	if cfg.LogOutput != "otel" {
-		output := os.Stdout
-		if strings.ToLower(cfg.LogOutput) == "stderr" {
-			output = os.Stderr
+func (p *severityProcessor) OnEmit(ctx context.Context, record *sdklog.Record) error {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(record.SeverityText())); err == nil {
+		if severity, ok := severityForSlogLevel(level); ok {
+			record.SetSeverity(severity)
 		}
+	}
+	return p.Processor.OnEmit(ctx, record)
+}
 
+// Reload applies the settings that can change without a restart: log
+// verbosity, info log sampling and the trace sample ratio. Exporters,
+// endpoints and other settings read by Setup keep their startup values.
+func Reload(cfg config.OtelConfig) {
+	SetLogVerbosity(cfg.LogVerbosity)
+	setLogSampleEvery(cfg.LogSampleEvery)
+	SetTraceSampleRatio(cfg.TraceSampleRatio)
+}
+
+// setupSlog configures slog with stdout/stderr/file + OTEL output
+func setupSlog(cfg config.OtelConfig, loggerProvider log.LoggerProvider, output io.Writer) {
+	var loggers []*slog.Logger
+	setLogSource(cfg.LogSource)
+	setLogSampleEvery(cfg.LogSampleEvery)
+	SetLogVerbosity(cfg.LogVerbosity)
+	opts := &slog.HandlerOptions{Level: &handlerLevel, AddSource: cfg.LogSource}
+
+	// Add stdout/stderr/file logger if not OTEL-only, or if OTEL logs are disabled
+	if cfg.LogOutput != "otel" || !cfg.EnableLogs {
 		var handler slog.Handler
 		if strings.ToLower(cfg.LogFormat) == "json" {
-			handler = slog.NewJSONHandler(output, &slog.HandlerOptions{Level: slog.LevelInfo})
+			handler = slog.NewJSONHandler(output, opts)
 		} else {
-			handler = slog.NewTextHandler(output, &slog.HandlerOptions{Level: slog.LevelInfo})
+			handler = slog.NewTextHandler(output, opts)
 		}
-		loggers = append(loggers, slog.New(handler))
+		loggers = append(loggers, slog.New(&traceContextHandler{Handler: handler}))
 	}
 
 	// Add OTEL logger
-	loggers = append(loggers, otelslog.NewLogger(cfg.ServiceName, otelslog.WithLoggerProvider(loggerProvider)))
+	if cfg.EnableLogs {
+		loggers = append(loggers, otelslog.NewLogger(cfg.ServiceName,
+			otelslog.WithLoggerProvider(loggerProvider),
+			otelslog.WithSource(cfg.LogSource),
+		))
+	}
 
-	// Set default logger
+	// Set default logger, tagging records with the request ID when present
+	var handler slog.Handler
 	if len(loggers) == 1 {
-		slog.SetDefault(loggers[0])
+		handler = loggers[0].Handler()
 	} else {
-		slog.SetDefault(slog.New(&multiHandler{loggers: loggers}))
+		handler = &multiHandler{loggers: loggers}
 	}
+	slog.SetDefault(slog.New(&requestIDHandler{Handler: handler}))
 }
 
 // multiHandler writes to multiple loggers
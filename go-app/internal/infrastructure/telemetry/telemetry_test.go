@@ -0,0 +1,118 @@
+package telemetry
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	otellog "go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"go-app/internal/infrastructure/config"
+)
+
+// memoryLogExporter keeps the log records it exports
+type memoryLogExporter struct {
+	mu      sync.Mutex
+	records []sdklog.Record
+}
+
+func (e *memoryLogExporter) Export(_ context.Context, records []sdklog.Record) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for i := range records {
+		e.records = append(e.records, records[i].Clone())
+	}
+	return nil
+}
+
+func (e *memoryLogExporter) Shutdown(context.Context) error   { return nil }
+func (e *memoryLogExporter) ForceFlush(context.Context) error { return nil }
+
+func TestSeverityProcessor(t *testing.T) {
+	logger, verbosity := slog.Default(), GetLogVerbosity()
+	t.Cleanup(func() {
+		slog.SetDefault(logger)
+		SetLogVerbosity(verbosity)
+	})
+
+	exporter := &memoryLogExporter{}
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(&severityProcessor{Processor: sdklog.NewSimpleProcessor(exporter)}))
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+	setupSlog(config.OtelConfig{ServiceName: "test", LogOutput: "otel", EnableLogs: true, LogVerbosity: 3}, provider, nil)
+
+	ctx := context.Background()
+	slog.DebugContext(ctx, "debug message")
+	slog.InfoContext(ctx, "info message")
+	slog.WarnContext(ctx, "warn message")
+	slog.ErrorContext(ctx, "error message")
+
+	want := []struct {
+		body     string
+		severity otellog.Severity
+		text     string
+	}{
+		{"debug message", otellog.SeverityDebug, "DEBUG"},
+		{"info message", otellog.SeverityInfo, "INFO"},
+		{"warn message", otellog.SeverityWarn, "WARN"},
+		{"error message", otellog.SeverityError, "ERROR"},
+	}
+	if len(exporter.records) != len(want) {
+		t.Fatalf("exported %d records, want %d", len(exporter.records), len(want))
+	}
+	for i, w := range want {
+		record := exporter.records[i]
+		if got := record.Body().AsString(); got != w.body {
+			t.Errorf("record %d body = %q, want %q", i, got, w.body)
+		}
+		if got := record.Severity(); got != w.severity {
+			t.Errorf("%s severity = %v, want %v", w.body, got, w.severity)
+		}
+		if got := record.SeverityText(); got != w.text {
+			t.Errorf("%s severity text = %q, want %q", w.body, got, w.text)
+		}
+	}
+}
+
+func TestSpanLimits(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithSpanLimits(newSpanLimits(config.OtelConfig{
+			SpanAttributeCountLimit:       2,
+			SpanAttributeValueLengthLimit: 8,
+			SpanEventCountLimit:           1,
+		})),
+		sdktrace.WithSyncer(exporter),
+	)
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+
+	_, span := provider.Tracer("test").Start(context.Background(), "test")
+	span.SetAttributes(
+		attribute.String("query", strings.Repeat("x", 100)),
+		attribute.Int("rows", 3),
+		attribute.Bool("cached", false),
+	)
+	span.AddEvent("first")
+	span.AddEvent("second")
+	span.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("exported %d spans, want 1", len(spans))
+	}
+	got := spans[0]
+	if len(got.Attributes) != 2 || got.DroppedAttributes != 1 {
+		t.Fatalf("attributes = %v with %d dropped, want 2 kept and 1 dropped", got.Attributes, got.DroppedAttributes)
+	}
+	if query := got.Attributes[0]; query.Key != "query" || len(query.Value.AsString()) != 8 {
+		t.Errorf("first attribute = %v, want query truncated to 8 characters", query)
+	}
+	if len(got.Events) != 1 || got.DroppedEvents != 1 {
+		t.Errorf("events = %d with %d dropped, want 1 kept and 1 dropped", len(got.Events), got.DroppedEvents)
+	}
+}
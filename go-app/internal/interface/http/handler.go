@@ -2,11 +2,20 @@ package http
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 
 	"go-app/internal/application/service"
+	"go-app/internal/domain/entity"
 	"go-app/internal/infrastructure/config"
+	"go-app/internal/infrastructure/redis"
 	"go-app/internal/infrastructure/telemetry"
 	"go-app/internal/interface/http/middleware"
 	"go-app/internal/interface/http/routes"
@@ -19,6 +28,17 @@ type Handler struct {
 	server      *http.Server
 	telemetry   *telemetry.Telemetry
 	config      config.OtelConfig
+	redis       *redis.Client
+	rateLimit   config.RateLimitConfig
+	cors        *config.CORSConfig
+	auth        middleware.Middleware
+	health      *service.HealthService
+	idempotency middleware.Middleware
+	proxies     []string
+	appConfig   *config.Config
+	pprofAddr   string
+	pprofServer *http.Server
+	inFlight    atomic.Int64
 }
 
 // NewHandler creates a new HTTP handler
@@ -31,25 +51,150 @@ func NewHandler(userService *service.UserService, appService *service.AppService
 	}
 }
 
+// WithRateLimit enables Redis-backed per-client rate limiting
+func (h *Handler) WithRateLimit(client *redis.Client, cfg config.RateLimitConfig) *Handler {
+	h.redis = client
+	h.rateLimit = cfg
+	return h
+}
+
+// WithTrustedProxies honors X-Forwarded-For and X-Real-IP on requests from
+// the given CIDRs or IPs when resolving the client IP
+func (h *Handler) WithTrustedProxies(proxies []string) *Handler {
+	h.proxies = proxies
+	return h
+}
+
+// WithPprof serves the net/http/pprof handlers on a separate admin listener
+// at addr. The admin server bypasses the middleware chain, including auth and
+// rate limiting, so addr must not be reachable from the public network.
+func (h *Handler) WithPprof(addr string) *Handler {
+	h.pprofAddr = addr
+	return h
+}
+
+// WithConfig serves the effective configuration, with secrets masked, to
+// admins at /admin/config. The endpoint is only registered when
+// authentication is enabled.
+func (h *Handler) WithConfig(cfg config.Config) *Handler {
+	h.appConfig = &cfg
+	return h
+}
+
+// WithCORS restricts cross-origin requests to the configured allow-lists.
+// Without it, any origin is allowed.
+func (h *Handler) WithCORS(cfg config.CORSConfig) *Handler {
+	h.cors = &cfg
+	return h
+}
+
+// WithIdempotency enables Redis-backed Idempotency-Key handling for POST /users
+func (h *Handler) WithIdempotency(client *redis.Client, ttl time.Duration) *Handler {
+	if ttl > 0 {
+		h.idempotency = middleware.IdempotencyMiddleware(client, ttl)
+	}
+	return h
+}
+
+// WithHealth reports the dependency results cached by health at /readyz
+func (h *Handler) WithHealth(health *service.HealthService) *Handler {
+	h.health = health
+	return h
+}
+
+// WithAuth requires API-key or JWT authentication on the user routes
+func (h *Handler) WithAuth(cfg config.AuthConfig) (*Handler, error) {
+	switch strings.ToLower(cfg.Mode) {
+	case "", "none":
+		h.auth = nil
+	case "apikey":
+		if cfg.APIKey == "" {
+			return nil, fmt.Errorf("AUTH_API_KEY is required when AUTH_MODE is apikey")
+		}
+		role, err := entity.NewRole(cfg.APIKeyRole)
+		if err != nil {
+			return nil, fmt.Errorf("invalid AUTH_API_KEY_ROLE: %w", err)
+		}
+		h.auth = middleware.AuthMiddleware(middleware.NewAPIKeyAuthenticator(cfg.APIKey, role))
+	case "jwt":
+		if cfg.JWTSecret == "" && cfg.JWKSURL == "" {
+			return nil, fmt.Errorf("AUTH_JWT_SECRET or AUTH_JWKS_URL is required when AUTH_MODE is jwt")
+		}
+		h.auth = middleware.AuthMiddleware(middleware.NewJWTAuthenticator(middleware.JWTOptions{
+			Secret:   cfg.JWTSecret,
+			JWKSURL:  cfg.JWKSURL,
+			Issuer:   cfg.JWTIssuer,
+			Audience: cfg.JWTAudience,
+		}))
+	default:
+		return nil, fmt.Errorf("unsupported AUTH_MODE %q", cfg.Mode)
+	}
+	return h, nil
+}
+
 // SetupRoutes sets up the HTTP routes with middleware
 func (h *Handler) SetupRoutes() http.Handler {
 	// Create a new ServeMux
 	mux := http.NewServeMux()
 
 	// Create router and register routes
-	router := routes.NewRouter(h.userService, h.appService)
+	router := routes.NewRouter(h.userService, h.appService).
+		WithAuth(h.auth).
+		WithHealth(h.health).
+		WithIdempotency(h.idempotency)
+	if h.appConfig != nil {
+		router.WithConfig(*h.appConfig)
+	}
 	router.RegisterRoutes(mux)
 
+	var meter metric.Meter
+	if h.telemetry != nil {
+		meter = h.telemetry.Meter
+	}
+
 	// Create middleware chain with config
-	middlewareChain := middleware.ChainMiddleware(
-		middleware.LoggingMiddlewareWithConfig(h.config.LogBodies),
-		middleware.OtelHttpMiddleware("http.server"), // Replaces both tracing and the old metrics middleware
-		middleware.RecoveryMiddleware,
-		middleware.CORSMiddleware,
-	)
+	// Tracing runs first so the request ID can be attached to the server span
+	middlewares := []middleware.Middleware{
+		middleware.OtelHttpMiddleware("http.server", mux), // Replaces both tracing and the old metrics middleware
+		middleware.TraceIDMiddleware,
+		middleware.RequestIDMiddleware,
+		middleware.ClientIPMiddleware(h.proxies),
+		middleware.LoggingMiddlewareWithConfig(h.config.LogBodies, meter),
+	}
+	if h.telemetry != nil && h.telemetry.Meter != nil {
+		middlewares = append(middlewares,
+			middleware.ActiveRequestsMiddleware(h.telemetry.Meter),
+			middleware.BodySizeMiddleware(h.telemetry.Meter, mux),
+		)
+	}
+	// CORS runs before the rate limiter, so 429s carry CORS headers browsers
+	// can read and preflight requests don't count toward the limit
+	corsMiddleware := middleware.CORSMiddleware
+	if h.cors != nil {
+		corsMiddleware = middleware.CORSMiddlewareWithConfig(h.cors.AllowedOrigins, h.cors.AllowedMethods, h.cors.AllowedHeaders)
+	}
+	middlewares = append(middlewares, corsMiddleware)
+	if h.redis != nil && h.rateLimit.Enabled {
+		middlewares = append(middlewares, middleware.RateLimitMiddleware(
+			h.redis,
+			h.rateLimit.Requests,
+			time.Duration(h.rateLimit.WindowSecs)*time.Second,
+		))
+	}
+	middlewares = append(middlewares, middleware.RecoveryMiddleware)
+	middlewareChain := middleware.ChainMiddleware(middlewares...)
 
 	// Apply middleware to the mux
-	return middlewareChain(mux)
+	return h.trackInFlight(middlewareChain(mux))
+}
+
+// trackInFlight counts requests currently being served so shutdown can report them
+func (h *Handler) trackInFlight(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.inFlight.Add(1)
+		defer h.inFlight.Add(-1)
+		next.ServeHTTP(w, r)
+	})
 }
 
 // StartWithAddr Start starts the HTTP server
@@ -63,13 +208,53 @@ func (h *Handler) StartWithAddr(ctx context.Context, addr string) error {
 		Handler: handler,
 	}
 
+	if h.pprofAddr != "" {
+		h.startPprof(ctx)
+	}
+
 	return h.server.ListenAndServe()
 }
 
-// Stop stops the HTTP server
+// startPprof serves the profiling handlers on the admin listener in the background
+func (h *Handler) startPprof(ctx context.Context) {
+	mux := http.NewServeMux()
+	routes.RegisterPprofRoutes(mux)
+	h.pprofServer = &http.Server{
+		Addr:    h.pprofAddr,
+		Handler: mux,
+	}
+
+	go func() {
+		telemetry.Log(ctx, telemetry.LevelWarn, "Serving pprof on admin listener", nil,
+			attribute.String("server.address", h.pprofAddr),
+		)
+		if err := h.pprofServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			telemetry.Log(ctx, telemetry.LevelError, "Pprof server failed", err,
+				attribute.String("server.address", h.pprofAddr),
+			)
+		}
+	}()
+}
+
+// Stop stops accepting new connections and waits for in-flight requests to
+// finish until ctx expires, after which remaining connections are closed
 func (h *Handler) Stop(ctx context.Context) error {
-	if h.server != nil {
-		return h.server.Shutdown(ctx)
+	if h.pprofServer != nil {
+		// Profiles in progress are not worth waiting for
+		_ = h.pprofServer.Close()
+	}
+	if h.server == nil {
+		return nil
+	}
+
+	err := h.server.Shutdown(ctx)
+	if errors.Is(err, context.DeadlineExceeded) {
+		telemetry.Log(ctx, telemetry.LevelWarn, "Shutdown deadline reached with requests still in flight", err,
+			attribute.Int64("http.server.in_flight", h.inFlight.Load()),
+		)
+		if closeErr := h.server.Close(); closeErr != nil {
+			return closeErr
+		}
 	}
-	return nil
+	return err
 }
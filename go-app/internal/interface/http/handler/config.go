@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"net/http"
+
+	"go-app/internal/infrastructure/config"
+	"go-app/internal/infrastructure/telemetry"
+)
+
+// ConfigHandler serves the effective configuration with secrets masked
+type ConfigHandler struct {
+	config config.Config
+}
+
+// NewConfigHandler creates a new config handler. Secrets are masked once here,
+// so the unmasked config is never reachable from the handler.
+func NewConfigHandler(cfg config.Config) *ConfigHandler {
+	return &ConfigHandler{config: cfg.Masked()}
+}
+
+// Handle returns the configuration the process loaded at startup
+func (h *ConfigHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	if err := encodeJSON(ctx, w, h.config); err != nil {
+		telemetry.Log(ctx, telemetry.LevelError, "Failed to encode config response", err)
+	}
+}
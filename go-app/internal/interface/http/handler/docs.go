@@ -0,0 +1,68 @@
+package handler
+
+import (
+	_ "embed"
+	"net/http"
+
+	"go-app/internal/infrastructure/telemetry"
+)
+
+// openAPISpec is the hand-maintained OpenAPI 3 document for the API. Keep its
+// schemas in sync with the request and response types in the dto package.
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// swaggerUIPage renders the spec served at /openapi.json with Swagger UI
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>go-app API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({ url: "/openapi.json", dom_id: "#swagger-ui" });
+  </script>
+</body>
+</html>
+`
+
+// DocsHandler serves the OpenAPI spec and its Swagger UI
+type DocsHandler struct{}
+
+// NewDocsHandler creates a new docs handler
+func NewDocsHandler() *DocsHandler {
+	return &DocsHandler{}
+}
+
+// Spec serves the OpenAPI document
+func (h *DocsHandler) Spec(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(openAPISpec); err != nil {
+		telemetry.Log(r.Context(), telemetry.LevelError, "Failed to write OpenAPI spec", err)
+	}
+}
+
+// UI serves the Swagger UI page
+func (h *DocsHandler) UI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write([]byte(swaggerUIPage)); err != nil {
+		telemetry.Log(r.Context(), telemetry.LevelError, "Failed to write API docs page", err)
+	}
+}
@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+
+	"go-app/internal/application/dto"
+	domainErrors "go-app/internal/domain/errors"
+)
+
+// openAPIDocument is the part of the spec the sync test reads
+type openAPIDocument struct {
+	Paths      map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas map[string]struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+			Required   []string                   `json:"required"`
+		} `json:"schemas"`
+	} `json:"components"`
+}
+
+// jsonFields returns the JSON names of the fields encoding/json writes for t
+func jsonFields(t reflect.Type) []string {
+	var fields []string
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = field.Name
+		}
+		fields = append(fields, name)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// domainErrorFields returns the JSON keys of a domain error body, which
+// handlers write in place of an ErrorResponse for domain errors
+func domainErrorFields(t *testing.T) []string {
+	t.Helper()
+	err := domainErrors.NewDomainErrorWithCause(domainErrors.ErrCodeRepositoryError, "failed", domainErrors.ErrUserNotFound).
+		WithContext("user_id", 1).
+		WithRetryable(true)
+	data, marshalErr := json.Marshal(err)
+	if marshalErr != nil {
+		t.Fatalf("marshal domain error: %v", marshalErr)
+	}
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(data, &body); err != nil {
+		t.Fatalf("unmarshal domain error: %v", err)
+	}
+	var fields []string
+	for key := range body {
+		fields = append(fields, key)
+	}
+	return fields
+}
+
+func TestOpenAPISchemasMatchDTOs(t *testing.T) {
+	var doc openAPIDocument
+	if err := json.Unmarshal(openAPISpec, &doc); err != nil {
+		t.Fatalf("openapi.json does not parse: %v", err)
+	}
+
+	// Schemas documenting a dto type; the others describe map responses.
+	// ErrorResponse documents both error bodies handlers write.
+	dtos := map[string][]string{
+		"CreateUserRequest":       jsonFields(reflect.TypeFor[dto.CreateUserRequest]()),
+		"UpdateUserRequest":       jsonFields(reflect.TypeFor[dto.UpdateUserRequest]()),
+		"PatchUserRequest":        jsonFields(reflect.TypeFor[dto.PatchUserRequest]()),
+		"UserResponse":            jsonFields(reflect.TypeFor[dto.UserResponse]()),
+		"ListUsersResponse":       jsonFields(reflect.TypeFor[dto.ListUsersResponse]()),
+		"UserEventResponse":       jsonFields(reflect.TypeFor[dto.UserEventResponse]()),
+		"UserEventsResponse":      jsonFields(reflect.TypeFor[dto.UserEventsResponse]()),
+		"BulkCreateUserResult":    jsonFields(reflect.TypeFor[dto.BulkCreateUserResult]()),
+		"BulkCreateUsersResponse": jsonFields(reflect.TypeFor[dto.BulkCreateUsersResponse]()),
+		"ErrorResponse":           append(jsonFields(reflect.TypeFor[dto.ErrorResponse]()), domainErrorFields(t)...),
+		"DeleteUsersResponse":     jsonFields(reflect.TypeFor[dto.DeleteUsersResponse]()),
+		"SuccessResponse":         jsonFields(reflect.TypeFor[dto.SuccessResponse]()),
+	}
+	for name, fields := range dtos {
+		t.Run(name, func(t *testing.T) {
+			schema, ok := doc.Components.Schemas[name]
+			if !ok {
+				t.Fatalf("openapi.json has no %s schema", name)
+			}
+			var documented []string
+			for property := range schema.Properties {
+				documented = append(documented, property)
+			}
+			sort.Strings(documented)
+
+			for _, property := range documented {
+				if !slices.Contains(fields, property) {
+					t.Errorf("documented property %q is not a field of dto.%s", property, name)
+				}
+			}
+			for _, field := range fields {
+				if !slices.Contains(documented, field) {
+					t.Errorf("field %q of dto.%s is not documented", field, name)
+				}
+			}
+			for _, required := range schema.Required {
+				if !slices.Contains(documented, required) {
+					t.Errorf("required property %q is not documented", required)
+				}
+			}
+		})
+	}
+}
+
+func TestOpenAPIReferencesResolve(t *testing.T) {
+	var doc openAPIDocument
+	if err := json.Unmarshal(openAPISpec, &doc); err != nil {
+		t.Fatalf("openapi.json does not parse: %v", err)
+	}
+
+	const prefix = `"#/components/schemas/`
+	spec := string(openAPISpec)
+	for rest := spec; ; {
+		i := strings.Index(rest, prefix)
+		if i < 0 {
+			break
+		}
+		rest = rest[i+len(prefix):]
+		name, _, _ := strings.Cut(rest, `"`)
+		if _, ok := doc.Components.Schemas[name]; !ok {
+			t.Errorf("$ref to undefined schema %q", name)
+		}
+	}
+
+	for _, path := range []string{"/", "/health", "/users", "/users/{id}"} {
+		if _, ok := doc.Paths[path]; !ok {
+			t.Errorf("openapi.json does not document %s", path)
+		}
+	}
+}
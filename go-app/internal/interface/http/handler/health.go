@@ -33,7 +33,6 @@ func (h *HealthHandler) Handle(w http.ResponseWriter, r *http.Request) {
 	// Add attributes to the current span
 	span := trace.SpanFromContext(ctx)
 	span.SetAttributes(
-		attribute.String("http.route", "/health"),
 		attribute.String("handler", "health"),
 	)
 
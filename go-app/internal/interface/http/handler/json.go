@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName names the tracer of the spans started in this package
+const tracerName = "go-app/internal/interface/http/handler"
+
+// decodeJSON decodes the request body into v. When the request span is
+// recording, decoding runs in an http.decode child span with the number of
+// bytes read as payload.size.
+func decodeJSON(r *http.Request, v interface{}) error {
+	ctx := r.Context()
+	parent := trace.SpanFromContext(ctx)
+	if !parent.IsRecording() {
+		return json.NewDecoder(r.Body).Decode(v)
+	}
+
+	_, span := parent.TracerProvider().Tracer(tracerName).Start(ctx, "http.decode")
+	defer span.End()
+
+	body := &countingReader{r: r.Body}
+	err := json.NewDecoder(body).Decode(v)
+	span.SetAttributes(attribute.Int64("payload.size", body.n))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to decode JSON")
+	}
+	return err
+}
+
+// encodeJSON writes v to w as JSON, in an http.encode child span with the
+// number of bytes written as payload.size when the span in ctx is recording
+func encodeJSON(ctx context.Context, w io.Writer, v interface{}) error {
+	parent := trace.SpanFromContext(ctx)
+	if !parent.IsRecording() {
+		return json.NewEncoder(w).Encode(v)
+	}
+
+	_, span := parent.TracerProvider().Tracer(tracerName).Start(ctx, "http.encode")
+	defer span.End()
+
+	out := &countingWriter{w: w}
+	err := json.NewEncoder(out).Encode(v)
+	span.SetAttributes(attribute.Int64("payload.size", out.n))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to encode JSON")
+	}
+	return err
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
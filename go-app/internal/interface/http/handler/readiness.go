@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"go-app/internal/application/service"
+	"go-app/internal/infrastructure/telemetry"
+)
+
+// ReadinessHandler handles the liveness and readiness probe endpoints
+type ReadinessHandler struct {
+	health *service.HealthService
+}
+
+// NewReadinessHandler creates a new readiness handler reporting the cached
+// dependency results of health. A nil health reports ready with no dependencies.
+func NewReadinessHandler(health *service.HealthService) *ReadinessHandler {
+	return &ReadinessHandler{
+		health: health,
+	}
+}
+
+// Livez reports that the process is up and serving requests
+func (h *ReadinessHandler) Livez(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	h.writeJSON(r.Context(), w, map[string]interface{}{"status": "alive"}, http.StatusOK)
+}
+
+// Readyz reports whether the last background check of every dependency
+// passed, as ok or fail per dependency, and returns 503 if any failed or has
+// not been checked yet. Check errors can name hosts and addresses, so they
+// are logged rather than returned.
+func (h *ReadinessHandler) Readyz(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(
+		attribute.String("handler", "readiness"),
+	)
+
+	statuses, ready := map[string]service.DependencyHealth{}, true
+	if h.health != nil {
+		statuses, ready = h.health.Status()
+	}
+
+	checks := make(map[string]string, len(statuses))
+	var failures []attribute.KeyValue
+	for name, result := range statuses {
+		if result.Status == service.HealthStatusHealthy {
+			checks[name] = "ok"
+			continue
+		}
+		checks[name] = "fail"
+		detail := result.Error
+		if detail == "" {
+			detail = result.Status
+		}
+		failures = append(failures, attribute.String("dependency."+name, detail))
+	}
+
+	status, code := "ready", http.StatusOK
+	if !ready {
+		status, code = "not_ready", http.StatusServiceUnavailable
+		telemetry.Log(ctx, telemetry.LevelWarn, "Readiness check failed", nil, failures...)
+	}
+	span.SetAttributes(attribute.Bool("readiness.ready", ready))
+
+	h.writeJSON(ctx, w, map[string]interface{}{
+		"status":       status,
+		"dependencies": checks,
+	}, code)
+}
+
+// writeJSON writes a JSON response
+func (h *ReadinessHandler) writeJSON(ctx context.Context, w http.ResponseWriter, data interface{}, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		telemetry.Log(ctx, telemetry.LevelError, "Failed to encode response", err,
+			attribute.String("handler", "readiness"),
+		)
+	}
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"go-app/internal/application/service"
+	"go-app/internal/infrastructure/telemetry"
+)
+
+// healthCheckFunc adapts a function to service.HealthChecker
+type healthCheckFunc func(ctx context.Context) error
+
+func (f healthCheckFunc) HealthCheck(ctx context.Context) error { return f(ctx) }
+
+func TestReadyzHidesCheckErrors(t *testing.T) {
+	const detail = "dial tcp 10.0.12.7:5432: connect: connection refused"
+	health := service.NewHealthService(map[string]service.HealthChecker{
+		"postgres": healthCheckFunc(func(context.Context) error { return errors.New(detail) }),
+		"redis":    healthCheckFunc(func(context.Context) error { return nil }),
+	}, &telemetry.Telemetry{Tracer: noop.NewTracerProvider().Tracer("test")}, time.Hour, time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	health.Start(ctx)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		statuses, _ := health.Status()
+		if statuses["postgres"].Status != service.HealthStatusUnknown && statuses["redis"].Status != service.HealthStatusUnknown {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("dependencies were not checked")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	w := httptest.NewRecorder()
+	NewReadinessHandler(health).Readyz(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+	if strings.Contains(w.Body.String(), "10.0.12.7") {
+		t.Errorf("body %s exposes the check error", w.Body.String())
+	}
+	var body struct {
+		Status       string            `json:"status"`
+		Dependencies map[string]string `json:"dependencies"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body %s: %v", w.Body.String(), err)
+	}
+	if body.Status != "not_ready" {
+		t.Errorf("status field = %q, want not_ready", body.Status)
+	}
+	if body.Dependencies["postgres"] != "fail" || body.Dependencies["redis"] != "ok" {
+		t.Errorf("dependencies = %v, want postgres fail and redis ok", body.Dependencies)
+	}
+}
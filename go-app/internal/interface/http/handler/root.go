@@ -37,7 +37,6 @@ func (h *RootHandler) Handle(w http.ResponseWriter, r *http.Request) {
 	// Add attributes to the current span
 	span := trace.SpanFromContext(ctx)
 	span.SetAttributes(
-		attribute.String("http.route", "/"),
 		attribute.String("handler", "root"),
 	)
 
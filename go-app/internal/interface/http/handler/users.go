@@ -2,10 +2,10 @@ package handler
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
@@ -13,6 +13,7 @@ import (
 	"go-app/internal/application/dto"
 	"go-app/internal/application/service"
 	domainErrors "go-app/internal/domain/errors"
+	"go-app/internal/domain/pagination"
 	"go-app/internal/infrastructure/telemetry"
 )
 
@@ -28,8 +29,20 @@ func NewUsersHandler(userService *service.UserService) *UsersHandler {
 	}
 }
 
+// Methods supported by the users collection and by a single user, as listed
+// in the Allow header
+const (
+	usersAllow = "GET, POST, DELETE, OPTIONS"
+	userAllow  = "GET, HEAD, PUT, PATCH, DELETE, OPTIONS"
+)
+
 // Handle handles requests to the users endpoint
 func (h *UsersHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	allow := usersAllow
+	if r.PathValue("id") != "" {
+		allow = userAllow
+	}
+
 	switch r.Method {
 	case http.MethodGet:
 		// If there's an ID parameter, get a specific user
@@ -40,13 +53,39 @@ func (h *UsersHandler) Handle(w http.ResponseWriter, r *http.Request) {
 			h.listUsers(w, r)
 		}
 	case http.MethodPost:
-		h.createUser(w, r)
-	case http.MethodPut:
-		h.updateUser(w, r)
+		// Users are created on the collection only
+		if id := r.PathValue("id"); id == "" {
+			h.createUser(w, r)
+		} else {
+			h.writeMethodNotAllowed(w, allow)
+		}
+	case http.MethodPut, http.MethodPatch:
+		// Updates target a single user
+		if id := r.PathValue("id"); id == "" {
+			h.writeMethodNotAllowed(w, allow)
+		} else if r.Method == http.MethodPut {
+			h.updateUser(w, r)
+		} else {
+			h.patchUser(w, r)
+		}
 	case http.MethodDelete:
-		h.deleteUser(w, r)
+		// Bulk deletes are routed to DeleteUsers, behind the admin check
+		if id := r.PathValue("id"); id != "" {
+			h.deleteUser(w, r)
+		} else {
+			h.writeMethodNotAllowed(w, allow)
+		}
+	case http.MethodHead:
+		if id := r.PathValue("id"); id != "" {
+			h.getUserByID(w, r, id)
+		} else {
+			h.writeMethodNotAllowed(w, allow)
+		}
+	case http.MethodOptions:
+		w.Header().Set("Allow", allow)
+		w.WriteHeader(http.StatusNoContent)
 	default:
-		h.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED")
+		h.writeMethodNotAllowed(w, allow)
 	}
 }
 
@@ -57,13 +96,12 @@ func (h *UsersHandler) listUsers(w http.ResponseWriter, r *http.Request) {
 	// Add attributes to the current span
 	span := trace.SpanFromContext(ctx)
 	span.SetAttributes(
-		attribute.String("http.route", "/users"),
 		attribute.String("handler", "users"),
 		attribute.String("operation", "list"),
 	)
 
 	// Parse query parameters for pagination
-	limit := 10 // default
+	limit := pagination.DefaultLimit()
 	offset := 0 // default
 
 	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
@@ -95,17 +133,160 @@ func (h *UsersHandler) listUsers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	h.writeJSONResponse(w, response, http.StatusOK)
+	h.writeJSONResponse(ctx, w, response, http.StatusOK)
+}
+
+// Search handles GET requests to search users by name or email
+func (h *UsersHandler) Search(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.writeMethodNotAllowed(w, http.MethodGet)
+		return
+	}
+
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	// Add attributes to the current span
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(
+		attribute.String("handler", "users"),
+		attribute.String("operation", "search"),
+		attribute.String("search.field", query.Get("field")),
+	)
+
+	// Parse query parameters for pagination
+	limit := pagination.DefaultLimit()
+	offset := 0 // default
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
+			offset = o
+		}
+	}
+
+	// Create request DTO
+	req := dto.SearchUsersRequest{
+		Query:  query.Get("q"),
+		Field:  query.Get("field"),
+		Limit:  limit,
+		Offset: offset,
+	}
+
+	// Search users through user service
+	response, err := h.userService.SearchUsers(ctx, req)
+	if err != nil {
+		telemetry.Log(ctx, telemetry.LevelError, "Failed to search users", err,
+			attribute.String("handler", "users"),
+			attribute.String("path", "/users/search"),
+			attribute.String("search.field", req.Field),
+		)
+		h.writeErrorResponseFromDomainError(w, err)
+		return
+	}
+
+	h.writeJSONResponse(ctx, w, response, http.StatusOK)
+}
+
+// BulkCreate handles POST requests to create several users at once
+func (h *UsersHandler) BulkCreate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.writeMethodNotAllowed(w, http.MethodPost)
+		return
+	}
+
+	ctx := r.Context()
+
+	// Parse request body
+	var reqs []dto.CreateUserRequest
+	if err := decodeJSON(r, &reqs); err != nil {
+		h.writeErrorResponse(w, "Invalid JSON", http.StatusBadRequest, "INVALID_JSON")
+		return
+	}
+
+	// Add attributes to the current span
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(
+		attribute.String("handler", "users"),
+		attribute.String("operation", "bulk_create"),
+		attribute.Int("users.requested", len(reqs)),
+	)
+
+	// Create users through user service
+	mode := r.URL.Query().Get("mode")
+	response, err := h.userService.CreateUsers(ctx, reqs, mode)
+	if err != nil {
+		telemetry.Log(ctx, telemetry.LevelError, "Failed to bulk create users", err,
+			attribute.String("handler", "users"),
+			attribute.String("path", "/users/bulk"),
+			attribute.String("bulk.mode", mode),
+		)
+		h.writeErrorResponseFromDomainError(w, err)
+		return
+	}
+
+	// Report partial success as 207 so clients inspect the per-item results
+	statusCode := http.StatusCreated
+	if response.Failed > 0 {
+		statusCode = http.StatusMultiStatus
+	}
+	h.writeJSONResponse(ctx, w, response, statusCode)
+}
+
+// Events handles GET requests for a user's latest activity
+func (h *UsersHandler) Events(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.writeMethodNotAllowed(w, http.MethodGet)
+		return
+	}
+
+	ctx := r.Context()
+	idStr := r.PathValue("id")
+
+	// Add attributes to the current span
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(
+		attribute.String("handler", "users"),
+		attribute.String("operation", "events"),
+		attribute.String("user.id", idStr),
+	)
+
+	// Parse query parameters for pagination
+	limit := pagination.DefaultLimit()
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	// Get user events from user service
+	response, err := h.userService.GetUserEvents(ctx, idStr, dto.ListUserEventsRequest{Limit: limit})
+	if err != nil {
+		telemetry.Log(ctx, telemetry.LevelError, "Failed to get user events", err,
+			attribute.String("handler", "users"),
+			attribute.String("path", "/users/{id}/events"),
+			attribute.String("user.id", idStr),
+		)
+		h.writeErrorResponseFromDomainError(w, err)
+		return
+	}
+
+	h.writeJSONResponse(ctx, w, response, http.StatusOK)
 }
 
-// getUserByID handles GET requests to get a specific user by ID
+// getUserByID handles GET and HEAD requests to get a specific user by ID.
+// HEAD responses carry the same headers, including the ETag, without a body.
 func (h *UsersHandler) getUserByID(w http.ResponseWriter, r *http.Request, idStr string) {
 	ctx := r.Context()
 
 	// Add attributes to the current span
 	span := trace.SpanFromContext(ctx)
 	span.SetAttributes(
-		attribute.String("http.route", "/users/{id}"),
 		attribute.String("handler", "users"),
 		attribute.String("operation", "get"),
 		attribute.String("user.id", idStr),
@@ -123,7 +304,20 @@ func (h *UsersHandler) getUserByID(w http.ResponseWriter, r *http.Request, idStr
 		return
 	}
 
-	h.writeJSONResponse(w, user, http.StatusOK)
+	// Skip the body when the client's copy is current
+	w.Header().Set("ETag", user.ETag)
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" && dto.ETagMatches(ifNoneMatch, user.ETag) {
+		span.SetAttributes(attribute.Bool("http.not_modified", true))
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	if r.Method == http.MethodHead {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	h.writeJSONResponse(ctx, w, user, http.StatusOK)
 }
 
 // createUser handles POST requests to create a new user
@@ -132,7 +326,7 @@ func (h *UsersHandler) createUser(w http.ResponseWriter, r *http.Request) {
 
 	// Parse request body
 	var req dto.CreateUserRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := decodeJSON(r, &req); err != nil {
 		h.writeErrorResponse(w, "Invalid JSON", http.StatusBadRequest, "INVALID_JSON")
 		return
 	}
@@ -140,7 +334,6 @@ func (h *UsersHandler) createUser(w http.ResponseWriter, r *http.Request) {
 	// Add attributes to the current span
 	span := trace.SpanFromContext(ctx)
 	span.SetAttributes(
-		attribute.String("http.route", "/users"),
 		attribute.String("handler", "users"),
 		attribute.String("operation", "create"),
 		attribute.String("user.email", req.Email),
@@ -166,7 +359,7 @@ func (h *UsersHandler) createUser(w http.ResponseWriter, r *http.Request) {
 		Data:    user,
 	}
 
-	h.writeJSONResponse(w, response, http.StatusCreated)
+	h.writeJSONResponse(ctx, w, response, http.StatusCreated)
 }
 
 // updateUser handles PUT requests to update an existing user
@@ -182,15 +375,15 @@ func (h *UsersHandler) updateUser(w http.ResponseWriter, r *http.Request) {
 
 	// Parse request body
 	var req dto.UpdateUserRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := decodeJSON(r, &req); err != nil {
 		h.writeErrorResponse(w, "Invalid JSON", http.StatusBadRequest, "INVALID_JSON")
 		return
 	}
+	req.IfMatch = r.Header.Get("If-Match")
 
 	// Add attributes to the current span
 	span := trace.SpanFromContext(ctx)
 	span.SetAttributes(
-		attribute.String("http.route", "/users/{id}"),
 		attribute.String("handler", "users"),
 		attribute.String("operation", "update"),
 		attribute.String("user.id", idStr),
@@ -217,8 +410,58 @@ func (h *UsersHandler) updateUser(w http.ResponseWriter, r *http.Request) {
 		Message: "User updated successfully",
 		Data:    user,
 	}
+	w.Header().Set("ETag", user.ETag)
 
-	h.writeJSONResponse(w, response, http.StatusOK)
+	h.writeJSONResponse(ctx, w, response, http.StatusOK)
+}
+
+// patchUser handles PATCH requests to partially update an existing user
+func (h *UsersHandler) patchUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Parse user ID from path
+	idStr := r.PathValue("id")
+	if idStr == "" {
+		h.writeErrorResponse(w, "User ID is required", http.StatusBadRequest, "MISSING_USER_ID")
+		return
+	}
+
+	// Parse request body
+	var req dto.PatchUserRequest
+	if err := decodeJSON(r, &req); err != nil {
+		h.writeErrorResponse(w, "Invalid JSON", http.StatusBadRequest, "INVALID_JSON")
+		return
+	}
+	req.IfMatch = r.Header.Get("If-Match")
+
+	// Add attributes to the current span
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(
+		attribute.String("handler", "users"),
+		attribute.String("operation", "patch"),
+		attribute.String("user.id", idStr),
+	)
+
+	// Patch user through user service
+	user, err := h.userService.PatchUser(ctx, idStr, req)
+	if err != nil {
+		telemetry.Log(ctx, telemetry.LevelError, "Failed to patch user", err,
+			attribute.String("handler", "users"),
+			attribute.String("path", "/users/"+idStr),
+			attribute.String("user.id", idStr),
+		)
+		h.writeErrorResponseFromDomainError(w, err)
+		return
+	}
+
+	// Create success response
+	response := dto.SuccessResponse{
+		Message: "User updated successfully",
+		Data:    user,
+	}
+	w.Header().Set("ETag", user.ETag)
+
+	h.writeJSONResponse(ctx, w, response, http.StatusOK)
 }
 
 // deleteUser handles DELETE requests to remove a user
@@ -235,7 +478,6 @@ func (h *UsersHandler) deleteUser(w http.ResponseWriter, r *http.Request) {
 	// Add attributes to the current span
 	span := trace.SpanFromContext(ctx)
 	span.SetAttributes(
-		attribute.String("http.route", "/users/{id}"),
 		attribute.String("handler", "users"),
 		attribute.String("operation", "delete"),
 		attribute.String("user.id", idStr),
@@ -258,16 +500,61 @@ func (h *UsersHandler) deleteUser(w http.ResponseWriter, r *http.Request) {
 		Message: "User deleted successfully",
 	}
 
-	h.writeJSONResponse(w, response, http.StatusOK)
+	h.writeJSONResponse(ctx, w, response, http.StatusOK)
+}
+
+// ConfirmDeleteHeader must be "true" on a bulk delete, so a mistyped or
+// unfiltered request cannot remove users by accident
+const ConfirmDeleteHeader = "X-Confirm-Delete"
+
+// DeleteUsers handles DELETE /users, removing the users matched by the ids
+// and email_domain query filters. ids may be repeated or comma-separated.
+// Register it behind an admin role check.
+func (h *UsersHandler) DeleteUsers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(
+		attribute.String("handler", "users"),
+		attribute.String("operation", "bulk_delete"),
+	)
+
+	if !strings.EqualFold(r.Header.Get(ConfirmDeleteHeader), "true") {
+		h.writeErrorResponse(w, ConfirmDeleteHeader+": true header is required for bulk deletes",
+			http.StatusPreconditionRequired, "CONFIRMATION_REQUIRED")
+		return
+	}
+
+	query := r.URL.Query()
+	req := dto.DeleteUsersRequest{EmailDomain: query.Get("email_domain")}
+	for _, value := range query["ids"] {
+		for _, id := range strings.Split(value, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				req.IDs = append(req.IDs, id)
+			}
+		}
+	}
+
+	response, err := h.userService.DeleteUsers(ctx, req)
+	if err != nil {
+		telemetry.Log(ctx, telemetry.LevelError, "Failed to delete users", err,
+			attribute.String("handler", "users"),
+			attribute.String("path", "/users"),
+		)
+		h.writeErrorResponseFromDomainError(w, err)
+		return
+	}
+
+	h.writeJSONResponse(ctx, w, response, http.StatusOK)
 }
 
 // writeJSONResponse writes a JSON response
-func (h *UsersHandler) writeJSONResponse(w http.ResponseWriter, data interface{}, statusCode int) {
+func (h *UsersHandler) writeJSONResponse(ctx context.Context, w http.ResponseWriter, data interface{}, statusCode int) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
-	if err := json.NewEncoder(w).Encode(data); err != nil {
+	if err := encodeJSON(ctx, w, data); err != nil {
 		// Log error but don't change response since headers are already written
-		telemetry.Log(context.Background(), telemetry.LevelError, "Failed to encode JSON response", err)
+		telemetry.Log(ctx, telemetry.LevelError, "Failed to encode JSON response", err)
 	}
 }
 
@@ -278,46 +565,51 @@ func (h *UsersHandler) writeErrorResponse(w http.ResponseWriter, message string,
 		Code:    code,
 		Message: message,
 	}
-	h.writeJSONResponse(w, errorResp, statusCode)
+	// Error bodies are small, so their encoding is not spanned
+	h.writeJSONResponse(context.Background(), w, errorResp, statusCode)
+}
+
+// writeMethodNotAllowed writes a 405 response listing the allowed methods
+func (h *UsersHandler) writeMethodNotAllowed(w http.ResponseWriter, allow string) {
+	w.Header().Set("Allow", allow)
+	h.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED")
 }
 
 // writeErrorResponseFromDomainError writes an error response from a domain error
 func (h *UsersHandler) writeErrorResponseFromDomainError(w http.ResponseWriter, err error) {
+	h.writeJSONResponse(context.Background(), w, dto.ErrorBody(err), statusCodeForError(err))
+}
+
+// statusClientClosedRequest is the non-standard status, from nginx, recorded
+// for requests whose client disconnected before the response was written
+const statusClientClosedRequest = 499
+
+// statusCodeForError maps domain error codes to HTTP status codes
+func statusCodeForError(err error) int {
 	var domainErr *domainErrors.DomainError
-	var statusCode int
-	var errorResp dto.ErrorResponse
-
-	if errors.As(err, &domainErr) {
-		// Map domain error codes to HTTP status codes
-		switch domainErr.Code {
-		case domainErrors.ErrCodeUserNotFound:
-			statusCode = http.StatusNotFound
-		case domainErrors.ErrCodeUserAlreadyExists:
-			statusCode = http.StatusConflict
-		case domainErrors.ErrCodeValidationFailed, domainErrors.ErrCodeInvalidUserData,
-			domainErrors.ErrCodeInvalidEmail, domainErrors.ErrCodeInvalidName, domainErrors.ErrCodeInvalidID:
-			statusCode = http.StatusBadRequest
-		case domainErrors.ErrCodeRepositoryError, domainErrors.ErrCodeDatabaseError:
-			statusCode = http.StatusInternalServerError
-		default:
-			statusCode = http.StatusInternalServerError
-		}
+	if !errors.As(err, &domainErr) {
+		return http.StatusInternalServerError
+	}
 
-		errorResp = dto.ErrorResponse{
-			Error:   domainErr.Error(),
-			Code:    string(domainErr.Code),
-			Message: domainErr.Message,
-			Context: domainErr.Context,
-		}
-	} else {
-		// Generic error
-		statusCode = http.StatusInternalServerError
-		errorResp = dto.ErrorResponse{
-			Error:   err.Error(),
-			Code:    "INTERNAL_ERROR",
-			Message: "An internal error occurred",
+	switch domainErr.Code {
+	case domainErrors.ErrCodeUserNotFound, domainErrors.ErrCodeFeatureDisabled:
+		return http.StatusNotFound
+	case domainErrors.ErrCodeUserAlreadyExists:
+		return http.StatusConflict
+	case domainErrors.ErrCodePreconditionFailed:
+		return http.StatusPreconditionFailed
+	case domainErrors.ErrCodeValidationFailed, domainErrors.ErrCodeInvalidUserData,
+		domainErrors.ErrCodeInvalidEmail, domainErrors.ErrCodeInvalidName, domainErrors.ErrCodeInvalidID,
+		domainErrors.ErrCodeInvalidPhone, domainErrors.ErrCodeInvalidRole:
+		return http.StatusBadRequest
+	case domainErrors.ErrCodeRequestCancelled:
+		if errors.Is(err, context.DeadlineExceeded) {
+			return http.StatusGatewayTimeout
 		}
+		return statusClientClosedRequest
+	case domainErrors.ErrCodeRepositoryError, domainErrors.ErrCodeDatabaseError:
+		return http.StatusInternalServerError
+	default:
+		return http.StatusInternalServerError
 	}
-
-	h.writeJSONResponse(w, errorResp, statusCode)
 }
@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"go-app/internal/application/dto"
+	"go-app/internal/application/service"
+	domainErrors "go-app/internal/domain/errors"
+	"go-app/internal/infrastructure/repository/memory"
+	"go-app/internal/infrastructure/telemetry"
+)
+
+// newTestUsersHandler creates a users handler over an in-memory repository
+// holding a single user, and returns that user
+func newTestUsersHandler(t *testing.T) (*UsersHandler, *dto.UserResponse) {
+	t.Helper()
+	svc := service.NewUserService(memory.NewUserRepository(), &telemetry.Telemetry{Tracer: noop.NewTracerProvider().Tracer("test")})
+	user, err := svc.CreateUser(context.Background(), dto.CreateUserRequest{Name: "Jane Doe", Email: "jane@example.com"})
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	return NewUsersHandler(svc), user
+}
+
+// serveUser sends a request for the user with the given id to h
+func serveUser(h *UsersHandler, method, id, body string, header http.Header) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, "/users/"+id, strings.NewReader(body))
+	r.SetPathValue("id", id)
+	for key, values := range header {
+		r.Header[key] = values
+	}
+	w := httptest.NewRecorder()
+	h.Handle(w, r)
+	return w
+}
+
+func TestStatusCodeForError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{domainErrors.ErrUserNotFound, http.StatusNotFound},
+		{domainErrors.ErrUserAlreadyExists.WithContext("email", "jane@example.com"), http.StatusConflict},
+		{domainErrors.ErrPreconditionFailed, http.StatusPreconditionFailed},
+		{domainErrors.ErrInvalidPhone, http.StatusBadRequest},
+		{domainErrors.ErrInvalidRole.WithContext("role", "owner"), http.StatusBadRequest},
+		{domainErrors.ErrRepositoryError, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			if got := statusCodeForError(tt.err); got != tt.want {
+				t.Errorf("statusCodeForError() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetUserIfNoneMatch(t *testing.T) {
+	h, user := newTestUsersHandler(t)
+	id := strconv.Itoa(user.ID)
+
+	tests := []struct {
+		name        string
+		method      string
+		ifNoneMatch string
+		want        int
+		wantBody    bool
+	}{
+		{"no header", http.MethodGet, "", http.StatusOK, true},
+		{"current etag", http.MethodGet, user.ETag, http.StatusNotModified, false},
+		{"weak current etag", http.MethodGet, "W/" + user.ETag, http.StatusNotModified, false},
+		{"one of several", http.MethodGet, `"stale", ` + user.ETag, http.StatusNotModified, false},
+		{"wildcard", http.MethodGet, "*", http.StatusNotModified, false},
+		{"stale etag", http.MethodGet, `"stale"`, http.StatusOK, true},
+		{"head current etag", http.MethodHead, user.ETag, http.StatusNotModified, false},
+		{"head stale etag", http.MethodHead, `"stale"`, http.StatusOK, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.ifNoneMatch != "" {
+				header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+			w := serveUser(h, tt.method, id, "", header)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d", w.Code, tt.want)
+			}
+			if got := w.Header().Get("ETag"); got != user.ETag {
+				t.Errorf("ETag = %q, want %q", got, user.ETag)
+			}
+			if hasBody := w.Body.Len() > 0; hasBody != tt.wantBody {
+				t.Errorf("body = %q, want body %v", w.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestUpdateUserIfMatch(t *testing.T) {
+	for _, method := range []string{http.MethodPut, http.MethodPatch} {
+		t.Run(method, func(t *testing.T) {
+			h, user := newTestUsersHandler(t)
+			id := strconv.Itoa(user.ID)
+			body := `{"name":"Jane Smith","email":"jane@example.com"}`
+
+			// A stale ETag is rejected and leaves the user unchanged
+			w := serveUser(h, method, id, body, http.Header{"If-Match": {`"stale"`}})
+			if w.Code != http.StatusPreconditionFailed {
+				t.Fatalf("stale If-Match status = %d, want %d", w.Code, http.StatusPreconditionFailed)
+			}
+			if !strings.Contains(w.Body.String(), string(domainErrors.ErrCodePreconditionFailed)) {
+				t.Errorf("stale If-Match body = %s, want code %s", w.Body.String(), domainErrors.ErrCodePreconditionFailed)
+			}
+			if got := serveUser(h, http.MethodGet, id, "", http.Header{"If-None-Match": {user.ETag}}); got.Code != http.StatusNotModified {
+				t.Fatalf("user changed after a failed precondition")
+			}
+
+			// The current ETag applies the update and returns the new one
+			w = serveUser(h, method, id, body, http.Header{"If-Match": {user.ETag}})
+			if w.Code != http.StatusOK {
+				t.Fatalf("current If-Match status = %d, want %d; body %s", w.Code, http.StatusOK, w.Body.String())
+			}
+			etag := w.Header().Get("ETag")
+			if etag == "" || etag == user.ETag {
+				t.Fatalf("ETag after update = %q, want a new one", etag)
+			}
+
+			// The ETag the update replaced is now stale
+			if w := serveUser(h, method, id, body, http.Header{"If-Match": {user.ETag}}); w.Code != http.StatusPreconditionFailed {
+				t.Errorf("replaced If-Match status = %d, want %d", w.Code, http.StatusPreconditionFailed)
+			}
+			if w := serveUser(h, method, id, body, http.Header{"If-Match": {"*"}}); w.Code != http.StatusOK {
+				t.Errorf("wildcard If-Match status = %d, want %d", w.Code, http.StatusOK)
+			}
+		})
+	}
+}
+
+func TestHandleHeadAndOptions(t *testing.T) {
+	h, user := newTestUsersHandler(t)
+	id := strconv.Itoa(user.ID)
+
+	tests := []struct {
+		name      string
+		method    string
+		id        string
+		want      int
+		wantAllow string
+	}{
+		{"head user", http.MethodHead, id, http.StatusOK, ""},
+		{"head missing user", http.MethodHead, "999", http.StatusNotFound, ""},
+		{"head collection", http.MethodHead, "", http.StatusMethodNotAllowed, usersAllow},
+		{"options user", http.MethodOptions, id, http.StatusNoContent, userAllow},
+		{"options collection", http.MethodOptions, "", http.StatusNoContent, usersAllow},
+		{"unsupported on user", http.MethodPost, id, http.StatusMethodNotAllowed, userAllow},
+		{"unsupported on collection", http.MethodPut, "", http.StatusMethodNotAllowed, usersAllow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serveUser(h, tt.method, tt.id, "", nil)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d", w.Code, tt.want)
+			}
+			if got := w.Header().Get("Allow"); got != tt.wantAllow {
+				t.Errorf("Allow = %q, want %q", got, tt.wantAllow)
+			}
+		})
+	}
+
+	// HEAD carries the GET headers without the body
+	get := serveUser(h, http.MethodGet, id, "", nil)
+	head := serveUser(h, http.MethodHead, id, "", nil)
+	if head.Body.Len() != 0 {
+		t.Errorf("HEAD body = %q, want none", head.Body.String())
+	}
+	for _, key := range []string{"ETag", "Content-Type"} {
+		if got, want := head.Header().Get(key), get.Header().Get(key); got != want || got == "" {
+			t.Errorf("HEAD %s = %q, want GET's %q", key, got, want)
+		}
+	}
+}
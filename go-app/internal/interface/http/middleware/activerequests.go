@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"go-app/internal/infrastructure/telemetry"
+)
+
+// ActiveRequestsMiddleware tracks requests currently being served on the
+// http.server.active_requests up/down counter, by request method
+func ActiveRequestsMiddleware(meter metric.Meter) Middleware {
+	activeRequests, err := meter.Int64UpDownCounter("http.server.active_requests",
+		metric.WithDescription("Number of HTTP requests currently being served"),
+		metric.WithUnit("{request}"))
+	if err != nil {
+		telemetry.Log(context.Background(), telemetry.LevelWarn, "Failed to create active requests counter", err)
+		return func(next http.Handler) http.Handler { return next }
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attrs := metric.WithAttributes(attribute.String("http.request.method", r.Method))
+			activeRequests.Add(r.Context(), 1, attrs)
+			// Decrement with a fresh context so a cancelled request still counts down
+			defer activeRequests.Add(context.Background(), -1, attrs)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
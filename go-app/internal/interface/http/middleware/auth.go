@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"go-app/internal/application/dto"
+	"go-app/internal/domain/entity"
+	"go-app/internal/infrastructure/telemetry"
+)
+
+// ErrUnauthenticated is returned when a request carries no valid credentials
+var ErrUnauthenticated = errors.New("invalid or missing credentials")
+
+// Claims describes the authenticated caller
+type Claims struct {
+	Subject string
+	Issuer  string
+	Role    entity.Role
+	// Raw holds every claim of a JWT; it is empty for API keys
+	Raw map[string]interface{}
+}
+
+// HasRole reports whether the caller has the given role
+func (c *Claims) HasRole(role entity.Role) bool {
+	return c.Role == role
+}
+
+// Authenticator validates a bearer token and returns the caller's claims
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (*Claims, error)
+}
+
+// claimsKey is the context key holding the authenticated claims
+type claimsKey struct{}
+
+// ClaimsFromContext returns the claims stored by AuthMiddleware, if any
+func ClaimsFromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(*Claims)
+	return claims, ok
+}
+
+// AuthMiddleware rejects requests without a valid bearer token with 401. On
+// success the claims are stored in the request context and the subject is
+// added to the current span. Apply it per route to keep public routes open.
+func AuthMiddleware(authenticator Authenticator) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			span := trace.SpanFromContext(ctx)
+
+			token, ok := bearerToken(r)
+			if !ok {
+				writeUnauthorized(w, "Missing bearer token")
+				return
+			}
+
+			claims, err := authenticator.Authenticate(ctx, token)
+			if err != nil {
+				telemetry.Log(ctx, telemetry.LevelWarn, "Authentication failed", err,
+					attribute.String("path", r.URL.Path),
+				)
+				writeUnauthorized(w, "Invalid credentials")
+				return
+			}
+
+			span.SetAttributes(attribute.String("auth.subject", claims.Subject))
+			next.ServeHTTP(w, r.WithContext(context.WithValue(ctx, claimsKey{}, claims)))
+		})
+	}
+}
+
+// RequireRole rejects requests whose authenticated caller lacks role with
+// 403. It must run after AuthMiddleware; requests without claims, as when
+// authentication is disabled, are always rejected.
+func RequireRole(role entity.Role) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := ClaimsFromContext(r.Context())
+			if !ok || !claims.HasRole(role) {
+				writeForbidden(w, "The "+role.String()+" role is required")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// bearerToken extracts the token from an "Authorization: Bearer <token>" header
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, found := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// writeUnauthorized writes a 401 ErrorResponse
+func writeUnauthorized(w http.ResponseWriter, message string) {
+	w.Header().Set("WWW-Authenticate", "Bearer")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnauthorized)
+	_ = json.NewEncoder(w).Encode(dto.ErrorResponse{
+		Error:   message,
+		Code:    "UNAUTHORIZED",
+		Message: message,
+	})
+}
+
+// writeForbidden writes a 403 ErrorResponse
+func writeForbidden(w http.ResponseWriter, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusForbidden)
+	_ = json.NewEncoder(w).Encode(dto.ErrorResponse{
+		Error:   message,
+		Code:    "FORBIDDEN",
+		Message: message,
+	})
+}
+
+// APIKeyAuthenticator accepts a single static API key
+type APIKeyAuthenticator struct {
+	key  []byte
+	role entity.Role
+}
+
+// NewAPIKeyAuthenticator creates an authenticator comparing tokens against
+// key and granting callers role
+func NewAPIKeyAuthenticator(key string, role entity.Role) *APIKeyAuthenticator {
+	return &APIKeyAuthenticator{key: []byte(key), role: role}
+}
+
+// Authenticate compares the token with the configured key in constant time
+func (a *APIKeyAuthenticator) Authenticate(_ context.Context, token string) (*Claims, error) {
+	if len(a.key) == 0 || subtle.ConstantTimeCompare([]byte(token), a.key) != 1 {
+		return nil, ErrUnauthenticated
+	}
+	return &Claims{Subject: "api-key", Role: a.role}, nil
+}
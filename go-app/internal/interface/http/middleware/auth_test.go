@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-app/internal/domain/entity"
+)
+
+func TestRequireRoleAdmin(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	authenticated := func(role entity.Role) http.Handler {
+		return AuthMiddleware(NewAPIKeyAuthenticator("key", role))(RequireRole(entity.RoleAdmin)(next))
+	}
+
+	tests := []struct {
+		name    string
+		handler http.Handler
+		token   string
+		want    int
+	}{
+		{"admin", authenticated(entity.RoleAdmin), "key", http.StatusNoContent},
+		{"user", authenticated(entity.RoleUser), "key", http.StatusForbidden},
+		{"bad key", authenticated(entity.RoleAdmin), "other", http.StatusUnauthorized},
+		{"auth disabled", RequireRole(entity.RoleAdmin)(next), "", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodDelete, "/users?email_domain=example.com", nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			tt.handler.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"go-app/internal/infrastructure/telemetry"
+)
+
+// BodySizeMiddleware records request and response body sizes on the
+// http.request.body.size and http.response.body.size histograms, by route and
+// method. The route is the pattern routes resolves, such as /users/{id},
+// never the raw path, so the attribute's cardinality stays bounded. Request
+// sizes come from Content-Length, or from the bytes read when it is unknown.
+func BodySizeMiddleware(meter metric.Meter, routes RouteMatcher) Middleware {
+	requestSize, err := meter.Int64Histogram("http.request.body.size",
+		metric.WithDescription("Size of HTTP request bodies"),
+		metric.WithUnit("By"))
+	if err != nil {
+		telemetry.Log(context.Background(), telemetry.LevelWarn, "Failed to create request body size histogram", err)
+		return func(next http.Handler) http.Handler { return next }
+	}
+	responseSize, err := meter.Int64Histogram("http.response.body.size",
+		metric.WithDescription("Size of HTTP response bodies"),
+		metric.WithUnit("By"))
+	if err != nil {
+		telemetry.Log(context.Background(), telemetry.LevelWarn, "Failed to create response body size histogram", err)
+		return func(next http.Handler) http.Handler { return next }
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := matchedRoute(routes, r)
+			if route == "" {
+				route = unmatchedRoute
+			}
+			method := r.Method
+			if !isKnownMethod(method) {
+				method = otherMethod
+			}
+
+			var body *countingReader
+			if r.Body != nil && r.Body != http.NoBody && r.ContentLength < 0 {
+				body = &countingReader{ReadCloser: r.Body}
+				r.Body = body
+			}
+			rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK, skipBody: true}
+
+			next.ServeHTTP(rec, r)
+
+			received := max(r.ContentLength, 0)
+			if body != nil {
+				received = body.read
+			}
+			attrs := metric.WithAttributes(
+				attribute.String("http.route", route),
+				attribute.String("http.request.method", method),
+			)
+			requestSize.Record(r.Context(), received, attrs)
+			responseSize.Record(r.Context(), rec.written, attrs)
+		})
+	}
+}
+
+// countingReader counts the bytes read from a request body
+type countingReader struct {
+	io.ReadCloser
+	read int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.read += int64(n)
+	return n, err
+}
@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// histogramSums collects the histogram name from reader and returns the sum
+// recorded for each route and method
+func histogramSums(t *testing.T, reader *sdkmetric.ManualReader, name string) map[[2]string]int64 {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	sums := make(map[[2]string]int64)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != name {
+				continue
+			}
+			hist, ok := m.Data.(metricdata.Histogram[int64])
+			if !ok {
+				t.Fatalf("%s is a %T, want an int64 histogram", name, m.Data)
+			}
+			for _, dp := range hist.DataPoints {
+				route, _ := dp.Attributes.Value(attribute.Key("http.route"))
+				method, _ := dp.Attributes.Value(attribute.Key("http.request.method"))
+				sums[[2]string{route.AsString(), method.AsString()}] += dp.Sum
+			}
+		}
+	}
+	return sums
+}
+
+func TestBodySizeMiddleware(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	meter := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("test")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		_, _ = io.WriteString(w, `{"id":42}`)
+	})
+	handler := BodySizeMiddleware(meter, mux)(mux)
+
+	serve := func(method, target string, body io.Reader) {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, target, body))
+	}
+	serve(http.MethodPut, "/users/42", strings.NewReader(`{"name":"Jane Doe"}`))
+	serve(http.MethodPut, "/users/7", strings.NewReader(`{"name":"John"}`))
+	// Without a Content-Length, the bytes read are counted
+	serve(http.MethodPatch, "/users/42", io.MultiReader(strings.NewReader(`{"name":`), strings.NewReader(`"Jane"}`)))
+	serve(http.MethodGet, "/users/42", nil)
+	serve(http.MethodGet, "/unknown/path", nil)
+	serve("PURGE", "/users/42", nil)
+
+	tests := []struct {
+		route, method string
+		request       int64
+		response      int64
+	}{
+		{"/users/{id}", http.MethodPut, int64(len(`{"name":"Jane Doe"}`) + len(`{"name":"John"}`)), 2 * int64(len(`{"id":42}`))},
+		{"/users/{id}", http.MethodPatch, int64(len(`{"name":"Jane"}`)), int64(len(`{"id":42}`))},
+		{"/users/{id}", http.MethodGet, 0, int64(len(`{"id":42}`))},
+		{unmatchedRoute, http.MethodGet, 0, int64(len("404 page not found\n"))},
+		{"/users/{id}", otherMethod, 0, int64(len(`{"id":42}`))},
+	}
+
+	requests := histogramSums(t, reader, "http.request.body.size")
+	responses := histogramSums(t, reader, "http.response.body.size")
+	if len(requests) != len(tests) || len(responses) != len(tests) {
+		t.Errorf("recorded %d request and %d response series, want %d each: %v, %v",
+			len(requests), len(responses), len(tests), requests, responses)
+	}
+	for _, tt := range tests {
+		key := [2]string{tt.route, tt.method}
+		if got := requests[key]; got != tt.request {
+			t.Errorf("request bytes for %s %s = %d, want %d", tt.method, tt.route, got, tt.request)
+		}
+		if got := responses[key]; got != tt.response {
+			t.Errorf("response bytes for %s %s = %d, want %d", tt.method, tt.route, got, tt.response)
+		}
+	}
+}
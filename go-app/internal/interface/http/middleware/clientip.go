@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"go-app/internal/infrastructure/telemetry"
+)
+
+// clientIPKey is the context key of the resolved client IP
+type clientIPKey struct{}
+
+// ClientIPMiddleware resolves the client IP once per request, stores it in the
+// request context for clientIP and records it on the current span as
+// client.address. X-Forwarded-For and X-Real-IP are only honored when the
+// request comes from one of the trusted proxies, given as CIDRs or single IPs.
+func ClientIPMiddleware(trustedProxies []string) Middleware {
+	trusted := parseTrustedProxies(trustedProxies)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := resolveClientIP(r, trusted)
+
+			ctx := context.WithValue(r.Context(), clientIPKey{}, ip)
+			trace.SpanFromContext(ctx).SetAttributes(attribute.String("client.address", ip))
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// clientIP returns the IP resolved by ClientIPMiddleware, or the host part of
+// the remote address when the middleware is not installed
+func clientIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(clientIPKey{}).(string); ok {
+		return ip
+	}
+	return remoteHost(r)
+}
+
+// resolveClientIP walks X-Forwarded-For from the nearest hop outwards while
+// the hops are trusted proxies, returning the first untrusted address
+func resolveClientIP(r *http.Request, trusted []*net.IPNet) string {
+	remote := remoteHost(r)
+	if !isTrusted(remote, trusted) {
+		return remote
+	}
+
+	if forwarded := r.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+		hops := strings.Split(strings.Join(forwarded, ","), ",")
+		client := remote
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if net.ParseIP(hop) == nil {
+				break
+			}
+			client = hop
+			if !isTrusted(hop, trusted) {
+				break
+			}
+		}
+		return client
+	}
+
+	if realIP := strings.TrimSpace(r.Header.Get("X-Real-IP")); net.ParseIP(realIP) != nil {
+		return realIP
+	}
+	return remote
+}
+
+// remoteHost returns the host part of the request's remote address
+func remoteHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func isTrusted(ip string, trusted []*net.IPNet) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, network := range trusted {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTrustedProxies parses CIDRs and single IPs, skipping invalid entries
+func parseTrustedProxies(entries []string) []*net.IPNet {
+	var networks []*net.IPNet
+	for _, entry := range entries {
+		if !strings.Contains(entry, "/") {
+			if ip := net.ParseIP(entry); ip != nil {
+				bits := 8 * len(ip.To16())
+				if ip.To4() != nil {
+					ip, bits = ip.To4(), 32
+				}
+				networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+				continue
+			}
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			telemetry.Log(context.Background(), telemetry.LevelWarn, "Ignoring invalid trusted proxy", err,
+				attribute.String("proxy", entry),
+			)
+			continue
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
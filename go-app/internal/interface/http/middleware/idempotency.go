@@ -0,0 +1,208 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"go-app/internal/application/dto"
+	"go-app/internal/infrastructure/redis"
+	"go-app/internal/infrastructure/telemetry"
+)
+
+const (
+	// IdempotencyKeyHeader is the request header identifying a retryable request
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader marks responses served from a stored result
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	// idempotencyLockTTL bounds how long an in-flight key stays locked if the
+	// holder dies before releasing it
+	idempotencyLockTTL = 30 * time.Second
+	// maxIdempotencyKeyLength bounds client-supplied keys
+	maxIdempotencyKeyLength = 255
+)
+
+// idempotencyStoreKey returns the Redis key for an Idempotency-Key, scoped to
+// the request path and the authenticated subject, if any. The subject is
+// escaped so it cannot contain the separator.
+func idempotencyStoreKey(r *http.Request, key string) string {
+	subject := ""
+	if claims, ok := ClaimsFromContext(r.Context()); ok {
+		subject = url.QueryEscape(claims.Subject)
+	}
+	return "idempotency:" + r.URL.Path + ":" + subject + ":" + key
+}
+
+// storedResponse is the response recorded for an idempotency key
+type storedResponse struct {
+	BodyHash    string `json:"body_hash"`
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type"`
+	Body        []byte `json:"body"`
+}
+
+// IdempotencyMiddleware replays the stored response for POST requests that
+// repeat an Idempotency-Key. The key is bound to a hash of the request body,
+// so reusing it with a different body is rejected with 422, and to the
+// authenticated caller, so keys of different callers never collide. A request
+// whose key is still being processed is rejected with 409. Only non-5xx
+// responses are stored, so failed requests can be retried. Requests are
+// passed through when Redis is unavailable.
+func IdempotencyMiddleware(client *redis.Client, ttl time.Duration) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if r.Method != http.MethodPost || key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				writeIdempotencyError(w, http.StatusBadRequest, "INVALID_IDEMPOTENCY_KEY", "Idempotency-Key is too long")
+				return
+			}
+
+			ctx := r.Context()
+			span := trace.SpanFromContext(ctx)
+			span.SetAttributes(attribute.String("idempotency.key", key))
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				writeIdempotencyError(w, http.StatusBadRequest, "INVALID_BODY", "Failed to read request body")
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			sum := sha256.Sum256(body)
+			bodyHash := hex.EncodeToString(sum[:])
+			storeKey := idempotencyStoreKey(r, key)
+
+			// Replay a stored response for the same key and body
+			stored, found, err := loadStoredResponse(ctx, client, storeKey)
+			if err != nil {
+				telemetry.Log(ctx, telemetry.LevelWarn, "Idempotency lookup failed, processing request", err,
+					attribute.String("idempotency.key", key),
+				)
+				next.ServeHTTP(w, r)
+				return
+			}
+			if found {
+				replayStoredResponse(w, span, stored, bodyHash)
+				return
+			}
+
+			// Only one request per key may execute at a time
+			release, ok, err := client.AcquireLock(ctx, storeKey+":lock", idempotencyLockTTL)
+			if err != nil {
+				telemetry.Log(ctx, telemetry.LevelWarn, "Idempotency lock failed, processing request", err,
+					attribute.String("idempotency.key", key),
+				)
+				next.ServeHTTP(w, r)
+				return
+			}
+			if !ok {
+				writeIdempotencyError(w, http.StatusConflict, "IDEMPOTENCY_KEY_IN_USE",
+					"A request with this Idempotency-Key is already being processed")
+				return
+			}
+			defer release()
+
+			// The previous holder may have stored its response before we got the lock
+			if stored, found, err := loadStoredResponse(ctx, client, storeKey); err == nil && found {
+				replayStoredResponse(w, span, stored, bodyHash)
+				return
+			}
+
+			rec := &captureWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rec, r)
+
+			if rec.status >= http.StatusInternalServerError {
+				return
+			}
+			stored = storedResponse{
+				BodyHash:    bodyHash,
+				Status:      rec.status,
+				ContentType: rec.Header().Get("Content-Type"),
+				Body:        rec.body.Bytes(),
+			}
+			data, err := json.Marshal(stored)
+			if err == nil {
+				err = client.SetWithTracing(ctx, storeKey, data, ttl)
+			}
+			if err != nil {
+				telemetry.Log(ctx, telemetry.LevelWarn, "Failed to store idempotent response", err,
+					attribute.String("idempotency.key", key),
+				)
+			}
+		})
+	}
+}
+
+// loadStoredResponse reads the response stored under key, if any
+func loadStoredResponse(ctx context.Context, client *redis.Client, key string) (storedResponse, bool, error) {
+	var stored storedResponse
+	data, err := client.GetWithTracing(ctx, key)
+	if errors.Is(err, goredis.Nil) {
+		return stored, false, nil
+	}
+	if err != nil {
+		return stored, false, err
+	}
+	if err := json.Unmarshal([]byte(data), &stored); err != nil {
+		return stored, false, err
+	}
+	return stored, true, nil
+}
+
+// replayStoredResponse writes a stored response, or 422 when the key was
+// stored for a different request body
+func replayStoredResponse(w http.ResponseWriter, span trace.Span, stored storedResponse, bodyHash string) {
+	if stored.BodyHash != bodyHash {
+		writeIdempotencyError(w, http.StatusUnprocessableEntity, "IDEMPOTENCY_KEY_REUSED",
+			"Idempotency-Key was already used with a different request body")
+		return
+	}
+	span.SetAttributes(attribute.Bool("idempotency.replayed", true))
+	w.Header().Set("Content-Type", stored.ContentType)
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(stored.Status)
+	_, _ = w.Write(stored.Body)
+}
+
+// writeIdempotencyError writes an ErrorResponse with the given status
+func writeIdempotencyError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(dto.ErrorResponse{
+		Error:   message,
+		Code:    code,
+		Message: message,
+	})
+}
+
+// captureWriter records the status and full body of a response while writing it
+type captureWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (c *captureWriter) WriteHeader(code int) {
+	c.status = code
+	c.ResponseWriter.WriteHeader(code)
+}
+
+func (c *captureWriter) Write(b []byte) (int, error) {
+	c.body.Write(b)
+	return c.ResponseWriter.Write(b)
+}
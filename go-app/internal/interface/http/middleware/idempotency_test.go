@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIdempotencyStoreKeyScopedToCaller(t *testing.T) {
+	request := func(subject string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/users", nil)
+		if subject != "" {
+			r = r.WithContext(context.WithValue(r.Context(), claimsKey{}, &Claims{Subject: subject}))
+		}
+		return r
+	}
+
+	alice := idempotencyStoreKey(request("alice"), "key-1")
+	if bob := idempotencyStoreKey(request("bob"), "key-1"); bob == alice {
+		t.Errorf("callers share the store key %q", alice)
+	}
+	if anonymous := idempotencyStoreKey(request(""), "key-1"); anonymous == alice {
+		t.Errorf("anonymous and authenticated callers share the store key %q", alice)
+	}
+	// A separator in the subject must not let it impersonate another key
+	if forged := idempotencyStoreKey(request("alice:key"), "1"); forged == idempotencyStoreKey(request("alice"), "key:1") {
+		t.Errorf("subject %q collides with another caller's key", "alice:key")
+	}
+}
@@ -0,0 +1,297 @@
+package middleware
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+
+	"go-app/internal/domain/entity"
+)
+
+const (
+	// jwtLeeway tolerates clock skew when checking exp and nbf
+	jwtLeeway = 30 * time.Second
+	// jwksCacheTTL is how long fetched signing keys are reused
+	jwksCacheTTL = 10 * time.Minute
+	// jwksMinRefresh limits refetches triggered by unknown key IDs
+	jwksMinRefresh = time.Minute
+)
+
+// JWTOptions configures JWT validation. Set Secret for HS256 or JWKSURL for
+// RS256; Issuer and Audience are checked when non-empty.
+type JWTOptions struct {
+	Secret   string
+	JWKSURL  string
+	Issuer   string
+	Audience string
+}
+
+// JWTAuthenticator validates HS256 and RS256 signed JWTs
+type JWTAuthenticator struct {
+	opts JWTOptions
+	jwks *jwksCache
+}
+
+// NewJWTAuthenticator creates a JWT authenticator
+func NewJWTAuthenticator(opts JWTOptions) *JWTAuthenticator {
+	a := &JWTAuthenticator{opts: opts}
+	if opts.JWKSURL != "" {
+		a.jwks = &jwksCache{
+			url:    opts.JWKSURL,
+			client: &http.Client{Timeout: 5 * time.Second, Transport: otelhttp.NewTransport(http.DefaultTransport)},
+		}
+	}
+	return a
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// Authenticate verifies the token signature and standard claims
+func (a *JWTAuthenticator) Authenticate(ctx context.Context, token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("invalid token header: %w", err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid token signature: %w", err)
+	}
+	if err := a.verify(ctx, header, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	var raw map[string]interface{}
+	if err := decodeSegment(parts[1], &raw); err != nil {
+		return nil, fmt.Errorf("invalid token claims: %w", err)
+	}
+	return a.validateClaims(raw)
+}
+
+// verify checks the signature with the key type matching the configured
+// algorithm, so an HS256 token can never be verified with an RSA public key
+func (a *JWTAuthenticator) verify(ctx context.Context, header jwtHeader, signingInput string, signature []byte) error {
+	switch {
+	case header.Alg == "HS256" && a.opts.Secret != "":
+		mac := hmac.New(sha256.New, []byte(a.opts.Secret))
+		mac.Write([]byte(signingInput))
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return errors.New("invalid token signature")
+		}
+		return nil
+	case header.Alg == "RS256" && a.jwks != nil:
+		key, err := a.jwks.key(ctx, header.Kid)
+		if err != nil {
+			return err
+		}
+		digest := sha256.Sum256([]byte(signingInput))
+		if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+			return errors.New("invalid token signature")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported token algorithm %q", header.Alg)
+	}
+}
+
+// validateClaims checks exp, nbf, iss and aud. Tokens without exp are
+// rejected, since they would otherwise never expire.
+func (a *JWTAuthenticator) validateClaims(raw map[string]interface{}) (*Claims, error) {
+	now := time.Now()
+	exp, ok := raw["exp"].(float64)
+	if !ok {
+		return nil, errors.New("token has no expiry")
+	}
+	if now.After(time.Unix(int64(exp), 0).Add(jwtLeeway)) {
+		return nil, errors.New("token expired")
+	}
+	if nbf, ok := raw["nbf"].(float64); ok && now.Add(jwtLeeway).Before(time.Unix(int64(nbf), 0)) {
+		return nil, errors.New("token not yet valid")
+	}
+
+	claims := &Claims{Raw: raw}
+	claims.Subject, _ = raw["sub"].(string)
+	claims.Issuer, _ = raw["iss"].(string)
+	// Roles this API doesn't know grant no more than a regular user
+	claims.Role = entity.RoleUser
+	if name, ok := raw["role"].(string); ok {
+		if role, err := entity.NewRole(name); err == nil {
+			claims.Role = role
+		}
+	}
+
+	if a.opts.Issuer != "" && claims.Issuer != a.opts.Issuer {
+		return nil, errors.New("unexpected token issuer")
+	}
+	if a.opts.Audience != "" && !hasAudience(raw["aud"], a.opts.Audience) {
+		return nil, errors.New("unexpected token audience")
+	}
+	return claims, nil
+}
+
+// hasAudience reports whether the aud claim, a string or list, contains want
+func hasAudience(aud interface{}, want string) bool {
+	switch v := aud.(type) {
+	case string:
+		return v == want
+	case []interface{}:
+		for _, a := range v {
+			if s, ok := a.(string); ok && s == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// decodeSegment decodes a base64url JSON token segment into v
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// jwksCache fetches and caches RSA signing keys from a JWKS endpoint
+type jwksCache struct {
+	url    string
+	client *http.Client
+
+	mu          sync.Mutex
+	keys        map[string]*rsa.PublicKey
+	fetchedAt   time.Time
+	attemptedAt time.Time
+	// refreshing is closed when the refresh in flight finishes; nil when idle
+	refreshing chan struct{}
+	// refreshErr is the error of the last refresh, nil if it succeeded
+	refreshErr error
+}
+
+// key returns the signing key with the given ID, refreshing the set when it
+// is stale or the ID is unknown. Refreshes are attempted at most once per
+// jwksMinRefresh so bad tokens can't hammer the endpoint. The fetch runs
+// without holding the lock, so a stale but known key is served while it is
+// in flight and only callers needing an unknown key wait for it.
+func (c *jwksCache) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	c.mu.Lock()
+	key, ok := c.keys[kid]
+	stale := time.Since(c.fetchedAt) >= jwksCacheTTL
+	done := c.refreshing
+	if (!ok || stale) && done == nil && time.Since(c.attemptedAt) >= jwksMinRefresh {
+		c.attemptedAt = time.Now()
+		done = make(chan struct{})
+		c.refreshing = done
+		// Detached from the request so a disconnecting client doesn't
+		// cancel a refresh other requests are waiting on
+		go c.refresh(context.WithoutCancel(ctx), done)
+	}
+	c.mu.Unlock()
+
+	if ok {
+		return key, nil
+	}
+	if done == nil {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if key, ok = c.keys[kid]; ok {
+		return key, nil
+	}
+	if c.refreshErr != nil {
+		return nil, c.refreshErr
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// refresh replaces the cached keys with the current JWKS contents and closes
+// done. A failed refresh keeps the keys that are already known.
+func (c *jwksCache) refresh(ctx context.Context, done chan struct{}) {
+	keys, err := c.fetch(ctx)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err == nil {
+		c.keys = keys
+		c.fetchedAt = time.Now()
+	}
+	c.refreshErr = err
+	c.refreshing = nil
+	close(done)
+}
+
+// fetch downloads the JWKS and decodes its RSA keys
+func (c *jwksCache) fetch(ctx context.Context) (map[string]*rsa.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build JWKS request: %w", err)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch JWKS: status %d", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("failed to decode JWKS: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			continue
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			continue
+		}
+		keys[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	return keys, nil
+}
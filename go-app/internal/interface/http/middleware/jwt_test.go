@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// signHS256 builds an HS256 token carrying claims
+func signHS256(t *testing.T, secret string, claims map[string]interface{}) string {
+	t.Helper()
+	header, _ := json.Marshal(jwtHeader{Alg: "HS256"})
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatalf("marshal claims: %v", err)
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(signingInput))
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestJWTAuthenticatorExpiry(t *testing.T) {
+	const secret = "test-secret"
+	auth := NewJWTAuthenticator(JWTOptions{Secret: secret})
+
+	tests := []struct {
+		name    string
+		claims  map[string]interface{}
+		wantErr bool
+	}{
+		{"valid", map[string]interface{}{"sub": "jane", "exp": time.Now().Add(time.Hour).Unix()}, false},
+		{"expired", map[string]interface{}{"sub": "jane", "exp": time.Now().Add(-time.Hour).Unix()}, true},
+		{"no exp", map[string]interface{}{"sub": "jane"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := auth.Authenticate(context.Background(), signHS256(t, secret, tt.claims))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Authenticate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && claims.Subject != "jane" {
+				t.Errorf("subject = %q, want jane", claims.Subject)
+			}
+		})
+	}
+}
@@ -2,114 +2,274 @@ package middleware
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
+	"runtime"
+	"strings"
+	"sync"
 	"time"
 
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/trace"
+
+	"go-app/internal/application/dto"
+	domainErrors "go-app/internal/domain/errors"
+	"go-app/internal/infrastructure/telemetry"
 )
 
 // Middleware represents a middleware function
 type Middleware func(http.Handler) http.Handler
 
+// maxLoggedBodySize is the size from which request and response bodies are
+// left out of the logs
+const maxLoggedBodySize = 1024
+
 // loggingMiddleware holds the configuration for the logging middleware
 type loggingMiddleware struct {
 	logBodies bool
+	// requestTruncated and responseTruncated count bodies left out of the
+	// logs for their size; nil without a meter
+	requestTruncated  metric.Int64Counter
+	responseTruncated metric.Int64Counter
 }
 
-// LoggingMiddlewareWithConfig creates a logging middleware with the specified config
-func LoggingMiddlewareWithConfig(logBodies bool) Middleware {
+// LoggingMiddlewareWithConfig creates a logging middleware with the specified
+// config. Bodies too large to log are recorded as request.body.truncated and
+// response.body.truncated span events and, when meter is not nil, counted on
+// http.request.body.truncated.total and http.response.body.truncated.total.
+func LoggingMiddlewareWithConfig(logBodies bool, meter metric.Meter) Middleware {
+	lm := &loggingMiddleware{
+		logBodies: logBodies,
+	}
+	if meter != nil {
+		lm.requestTruncated = newTruncatedCounter(meter, "http.request.body.truncated.total", "Request bodies left out of the logs for their size")
+		lm.responseTruncated = newTruncatedCounter(meter, "http.response.body.truncated.total", "Response bodies left out of the logs for their size")
+	}
 	return func(next http.Handler) http.Handler {
-		lm := &loggingMiddleware{
-			logBodies: logBodies,
-		}
 		return lm.middleware(next)
 	}
 }
 
+// newTruncatedCounter creates a truncated body counter, or returns nil when
+// it cannot be created
+func newTruncatedCounter(meter metric.Meter, name, description string) metric.Int64Counter {
+	counter, err := meter.Int64Counter(name,
+		metric.WithDescription(description),
+		metric.WithUnit("{request}"))
+	if err != nil {
+		telemetry.Log(context.Background(), telemetry.LevelWarn, "Failed to create truncated body counter", err,
+			attribute.String("metric", name))
+		return nil
+	}
+	return counter
+}
+
+// recordTruncated notes on the request's span, and on counter when set, that
+// a body of size bytes was left out of the logs
+func recordTruncated(r *http.Request, counter metric.Int64Counter, eventName, sizeKey string, size int64) {
+	trace.SpanFromContext(r.Context()).AddEvent(eventName, trace.WithAttributes(
+		attribute.Int64(sizeKey, size),
+		attribute.Int("http.body.log_limit", maxLoggedBodySize),
+	))
+	if counter != nil {
+		counter.Add(r.Context(), 1, metric.WithAttributes(attribute.String("http.request.method", r.Method)))
+	}
+}
+
+// maxLoggedHeaders caps how many request headers are copied into the log
+const maxLoggedHeaders = 32
+
+// hopByHopHeaders are connection-level headers that are not worth logging
+var hopByHopHeaders = map[string]bool{
+	"Connection":          true,
+	"Keep-Alive":          true,
+	"Proxy-Authenticate":  true,
+	"Proxy-Authorization": true,
+	"Te":                  true,
+	"Trailer":             true,
+	"Transfer-Encoding":   true,
+	"Upgrade":             true,
+}
+
+// redactedHeaderValue replaces the value of sensitive headers in the log
+const redactedHeaderValue = "REDACTED"
+
+// sensitiveHeaders carry credentials or replayable tokens; they are logged
+// with their value redacted
+var sensitiveHeaders = map[string]bool{
+	"Authorization":   true,
+	"Cookie":          true,
+	"Set-Cookie":      true,
+	"Idempotency-Key": true,
+	"X-Api-Key":       true,
+	"X-Auth-Token":    true,
+	"X-Csrf-Token":    true,
+}
+
 func (lm *loggingMiddleware) middleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Without body logging and below debug verbosity, skip the header copy
+		// and body buffering entirely and log a single summary line
+		if !lm.logBodies && telemetry.GetLogVerbosity() < 3 {
+			lm.serveMinimal(next, w, r)
+			return
+		}
+
 		start := time.Now()
 
 		// Log request headers
-		headers := make(map[string]string)
+		headers := make(map[string]string, min(len(r.Header), maxLoggedHeaders))
 		for name, values := range r.Header {
+			if len(headers) >= maxLoggedHeaders {
+				break
+			}
 			// Only log the first value for each header
-			if len(values) > 0 {
+			if len(values) == 0 || hopByHopHeaders[name] {
+				continue
+			}
+			if sensitiveHeaders[name] {
+				headers[name] = redactedHeaderValue
+			} else {
 				headers[name] = values[0]
 			}
 		}
 
 		// Only log request body for non-GET requests and when content length is reasonable
 		var reqBody []byte
-		if lm.logBodies && r.Body != nil && r.ContentLength > 0 && r.ContentLength < 1024 && r.Method != http.MethodGet {
-			reqBody, _ = io.ReadAll(r.Body)
-			r.Body = io.NopCloser(bytes.NewBuffer(reqBody))
+		if lm.logBodies && r.Body != nil && r.Method != http.MethodGet {
+			if r.ContentLength > 0 && r.ContentLength < maxLoggedBodySize {
+				reqBody, _ = io.ReadAll(r.Body)
+				r.Body = io.NopCloser(bytes.NewBuffer(reqBody))
+			} else if r.ContentLength >= maxLoggedBodySize {
+				recordTruncated(r, lm.requestTruncated, "request.body.truncated", "http.request.body.size", r.ContentLength)
+			}
 		}
 
 		// Skip body buffering for large responses or when body logging is disabled
 		rec := &responseRecorder{
 			ResponseWriter: w,
 			status:         http.StatusOK,
-			skipBody:       !lm.logBodies || r.ContentLength > 1024,
+			skipBody:       !lm.logBodies || r.ContentLength > maxLoggedBodySize,
+		}
+		if !rec.skipBody {
+			rec.body = getBuffer()
+			// Deferred so the buffer is only reused after the completion log below
+			defer putBuffer(rec.body)
 		}
 
 		// Log request with conditional body logging
 		if len(reqBody) > 0 {
-			slog.Info("Incoming request",
+			slog.InfoContext(r.Context(), "Incoming request",
 				"method", r.Method,
-				"path", r.URL.Path,
+				"path", telemetry.RedactURL(r.URL),
 				"remote_addr", r.RemoteAddr,
+				"client_ip", clientIP(r),
 				"headers", headers,
 				"content_length", r.ContentLength,
 				"body", string(reqBody),
 			)
 		} else {
-			slog.Info("Incoming request",
+			slog.InfoContext(r.Context(), "Incoming request",
 				"method", r.Method,
-				"path", r.URL.Path,
+				"path", telemetry.RedactURL(r.URL),
 				"remote_addr", r.RemoteAddr,
+				"client_ip", clientIP(r),
 				"headers", headers,
 				"content_length", r.ContentLength,
 			)
 		}
 
 		next.ServeHTTP(rec, r)
+		if rec.bodyTruncated {
+			recordTruncated(r, lm.responseTruncated, "response.body.truncated", "http.response.body.size", rec.written)
+		}
 
 		// Only log response body when it's reasonably small and body logging is enabled
 		duration := time.Since(start)
-		if lm.logBodies && !rec.skipBody && rec.body.Len() > 0 {
-			slog.Info("Request completed",
+		if lm.logBodies && !rec.skipBody && rec.bodyLen() > 0 {
+			slog.InfoContext(r.Context(), "Request completed",
 				"method", r.Method,
-				"path", r.URL.Path,
+				"path", telemetry.RedactURL(r.URL),
 				"duration", duration,
 				"status", rec.status,
-				"response_size", rec.body.Len(),
+				"response_size", rec.bodyLen(),
 				"response_body", rec.body.String(),
 			)
 		} else {
-			slog.Info("Request completed",
+			slog.InfoContext(r.Context(), "Request completed",
 				"method", r.Method,
-				"path", r.URL.Path,
+				"path", telemetry.RedactURL(r.URL),
 				"duration", duration,
 				"status", rec.status,
-				"response_size", rec.body.Len(),
+				"response_size", rec.bodyLen(),
 			)
 		}
 	})
 }
 
+// serveMinimal serves the request logging only method, path, status and duration
+func (lm *loggingMiddleware) serveMinimal(next http.Handler, w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK, skipBody: true}
+
+	next.ServeHTTP(rec, r)
+
+	slog.InfoContext(r.Context(), "Request completed",
+		"method", r.Method,
+		"path", telemetry.RedactURL(r.URL),
+		"duration", time.Since(start),
+		"status", rec.status,
+	)
+}
+
+// bufferPool reuses response body buffers across requests
+var bufferPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// maxPooledBufferSize keeps buffers grown by unusually large bodies out of the pool
+const maxPooledBufferSize = 64 * 1024
+
+func getBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBufferSize {
+		return
+	}
+	buf.Reset()
+	bufferPool.Put(buf)
+}
+
 // responseRecorder captures response status and body.
 // This is re-added to fix a compilation error in LoggingMiddleware.
 type responseRecorder struct {
 	http.ResponseWriter
 	status int
-	body   bytes.Buffer
+	// body is a pooled buffer, nil when skipBody is set
+	body *bytes.Buffer
 	// skipBody controls whether we buffer the response body
 	skipBody bool
+	// written counts the response body bytes written, buffered or not
+	written int64
+	// bodyTruncated is set when a write was too large to buffer
+	bodyTruncated bool
+}
+
+// bodyLen returns the number of buffered response bytes
+func (r *responseRecorder) bodyLen() int {
+	if r.body == nil {
+		return 0
+	}
+	return r.body.Len()
 }
 
 func (r *responseRecorder) WriteHeader(code int) {
@@ -119,74 +279,196 @@ func (r *responseRecorder) WriteHeader(code int) {
 
 func (r *responseRecorder) Write(b []byte) (int, error) {
 	// Only buffer response body if it's reasonably small and body logging is enabled
-	if !r.skipBody && len(b) < 1024 {
-		r.body.Write(b)
+	if r.body != nil {
+		if len(b) < maxLoggedBodySize {
+			r.body.Write(b)
+		} else {
+			r.bodyTruncated = true
+		}
 	}
-	return r.ResponseWriter.Write(b)
+	n, err := r.ResponseWriter.Write(b)
+	r.written += int64(n)
+	return n, err
 }
 
 // OtelHttpMiddleware adds OpenTelemetry tracing and metrics to requests.
 // It uses the standard otelhttp handler, which automatically records
 // HTTP server metrics (e.g., duration, request/response size) and creates spans for traces.
-func OtelHttpMiddleware(operation string) Middleware {
+// Spans are named "{method} {route}" after the pattern routes matches, and
+// http.route is set on spans and metrics, so traces group per route without
+// handlers tagging themselves. Unmatched requests, or all of them when routes
+// is nil, get operation as the span name.
+func OtelHttpMiddleware(operation string, routes RouteMatcher) Middleware {
 	return func(next http.Handler) http.Handler {
-		return otelhttp.NewHandler(
-			next,
-			operation, // This becomes the span name for the server request
-			otelhttp.WithMessageEvents(otelhttp.ReadEvents, otelhttp.WriteEvents),
-		)
+		opts := []otelhttp.Option{otelhttp.WithMessageEvents(otelhttp.ReadEvents, otelhttp.WriteEvents)}
+		if routes != nil {
+			next = routeTag(routes, next)
+			opts = append(opts, otelhttp.WithSpanNameFormatter(routeSpanName(routes)))
+		}
+		return otelhttp.NewHandler(next, operation, opts...)
 	}
 }
 
-// RecoveryMiddleware recovers from panics and logs them
+// TraceIDHeader is the response header exposing the request's trace ID
+const TraceIDHeader = "X-Trace-Id"
+
+// TraceIDMiddleware writes the trace ID of the current span to the response
+// headers so clients can quote it in bug reports. It must run after
+// OtelHttpMiddleware has started the server span.
+func TraceIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if sc := trace.SpanFromContext(r.Context()).SpanContext(); sc.IsValid() {
+			w.Header().Set(TraceIDHeader, sc.TraceID().String())
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// maxPanicStackSize caps the stack trace captured for a recovered panic
+const maxPanicStackSize = 64 << 10
+
+// RecoveryMiddleware recovers from panics, records the panic value and stack
+// trace on the span and in the log, and responds with a 500 ErrorResponse
+// unless the handler already started the response
 func RecoveryMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tw := &headerTracker{ResponseWriter: w}
 		defer func() {
-			if err := recover(); err != nil {
-				// Create a context with the current request
-				ctx := r.Context()
-
-				// Log the panic
-				slog.ErrorContext(ctx, "Panic recovered",
-					"error", err,
-					"method", r.Method,
-					"path", r.URL.Path,
-				)
-
-				// Add error to the current span
-				span := trace.SpanFromContext(ctx)
-				if span.IsRecording() {
-					span.SetStatus(500, "Internal Server Error")
-					span.RecordError(err.(error), trace.WithAttributes(
-						attribute.String("panic", "recovered"),
-					))
-				}
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			// net/http uses this sentinel to abort a response silently
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
 
-				// Send error response
-				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			ctx := r.Context()
+			err, ok := recovered.(error)
+			if !ok {
+				err = fmt.Errorf("%v", recovered)
 			}
+			stack := make([]byte, maxPanicStackSize)
+			stack = stack[:runtime.Stack(stack, false)]
+
+			slog.ErrorContext(ctx, "Panic recovered",
+				"error", err,
+				"panic.type", fmt.Sprintf("%T", recovered),
+				"stacktrace", string(stack),
+				"method", r.Method,
+				"path", telemetry.RedactURL(r.URL),
+			)
+
+			span := trace.SpanFromContext(ctx)
+			if span.IsRecording() {
+				span.SetStatus(codes.Error, "panic recovered")
+				span.RecordError(err, trace.WithAttributes(
+					attribute.String("panic", "recovered"),
+					attribute.String("exception.type", fmt.Sprintf("%T", recovered)),
+					attribute.String("exception.stacktrace", string(stack)),
+				))
+			}
+
+			// Writing now would append to a partial response
+			if tw.wroteHeader {
+				return
+			}
+			writeInternalError(w, span.SpanContext())
 		}()
 
-		next.ServeHTTP(w, r)
+		next.ServeHTTP(tw, r)
 	})
 }
 
-// CORSMiddleware adds CORS headers
+// writeInternalError writes a 500 ErrorResponse carrying the trace ID, so
+// clients can report it without seeing the panic message
+func writeInternalError(w http.ResponseWriter, sc trace.SpanContext) {
+	resp := dto.ErrorResponse{
+		Error:   "internal server error",
+		Code:    string(domainErrors.ErrCodeInternalError),
+		Message: "An internal error occurred",
+	}
+	if sc.HasTraceID() {
+		resp.Context = map[string]interface{}{"trace_id": sc.TraceID().String()}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusInternalServerError)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// headerTracker records whether the response has been started
+type headerTracker struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (t *headerTracker) WriteHeader(code int) {
+	t.wroteHeader = true
+	t.ResponseWriter.WriteHeader(code)
+}
+
+func (t *headerTracker) Write(b []byte) (int, error) {
+	t.wroteHeader = true
+	return t.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (t *headerTracker) Unwrap() http.ResponseWriter {
+	return t.ResponseWriter
+}
+
+// CORSMiddleware adds permissive CORS headers allowing any origin
 func CORSMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Add CORS headers
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
-		// Handle preflight requests
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
-			return
+	return CORSMiddlewareWithConfig(
+		[]string{"*"},
+		[]string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		[]string{"Content-Type", "Authorization", "X-Request-ID", "Idempotency-Key", "If-Match", "If-None-Match"},
+	)(next)
+}
+
+// CORSMiddlewareWithConfig adds CORS headers for origins in the allow-list.
+// The request Origin is echoed back only when allowed; "*" allows any origin.
+func CORSMiddlewareWithConfig(allowedOrigins, allowedMethods, allowedHeaders []string) Middleware {
+	allowAny := false
+	origins := make(map[string]struct{}, len(allowedOrigins))
+	for _, origin := range allowedOrigins {
+		if origin == "*" {
+			allowAny = true
 		}
+		origins[origin] = struct{}{}
+	}
+	methods := strings.Join(allowedMethods, ", ")
+	headers := strings.Join(allowedHeaders, ", ")
 
-		next.ServeHTTP(w, r)
-	})
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Add CORS headers
+			if allowAny {
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+			} else {
+				// The response depends on the Origin header, so caches must key on it
+				w.Header().Add("Vary", "Origin")
+				if origin := r.Header.Get("Origin"); origin != "" {
+					if _, ok := origins[origin]; ok {
+						w.Header().Set("Access-Control-Allow-Origin", origin)
+					}
+				}
+			}
+			w.Header().Set("Access-Control-Allow-Methods", methods)
+			w.Header().Set("Access-Control-Allow-Headers", headers)
+			w.Header().Set("Access-Control-Expose-Headers", "ETag, Retry-After, X-Request-ID, X-Trace-Id, Idempotent-Replayed")
+
+			// Answer preflight requests; other OPTIONS requests reach the
+			// handler, which reports the methods it allows
+			if r.Method == "OPTIONS" && r.Header.Get("Access-Control-Request-Method") != "" {
+				w.WriteHeader(http.StatusOK)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
 }
 
 // ChainMiddleware chains multiple middleware functions
@@ -0,0 +1,369 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"go-app/internal/application/dto"
+	domainErrors "go-app/internal/domain/errors"
+	"go-app/internal/infrastructure/telemetry"
+)
+
+func TestRecoveryMiddleware(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+	}{
+		{"string", "something went wrong"},
+		{"error", errors.New("something went wrong")},
+		{"nil", nil},
+		{"other", 42},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := RecoveryMiddleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+				panic(tt.value)
+			}))
+
+			w := httptest.NewRecorder()
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						t.Fatalf("panic escaped the middleware: %v", r)
+					}
+				}()
+				handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users", nil))
+			}()
+
+			if w.Code != http.StatusInternalServerError {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusInternalServerError)
+			}
+		})
+	}
+}
+
+func TestRecoveryMiddlewareAfterHeadersSent(t *testing.T) {
+	handler := RecoveryMiddleware(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte("partial"))
+		panic("late failure")
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users", nil))
+
+	if w.Code != http.StatusAccepted {
+		t.Errorf("status = %d, want the status already sent", w.Code)
+	}
+	if got := w.Body.String(); got != "partial" {
+		t.Errorf("body = %q, want nothing appended to the partial response", got)
+	}
+}
+
+func TestRecoveryMiddlewareRepanicsAbortHandler(t *testing.T) {
+	handler := RecoveryMiddleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+
+	defer func() {
+		if r := recover(); r != http.ErrAbortHandler {
+			t.Errorf("recovered %v, want http.ErrAbortHandler", r)
+		}
+	}()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users", nil))
+}
+
+func TestRecoveryMiddlewareJSONResponse(t *testing.T) {
+	const secret = "db password is hunter2"
+	handler := RecoveryMiddleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic(secret)
+	}))
+
+	traceID := trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36}
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceFlags: trace.FlagsSampled,
+	})
+	r := httptest.NewRequest(http.MethodGet, "/users", nil)
+	r = r.WithContext(trace.ContextWithSpanContext(r.Context(), sc))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+	if strings.Contains(w.Body.String(), secret) {
+		t.Errorf("body %s leaks the panic message", w.Body.String())
+	}
+
+	var resp dto.ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("body %q is not an ErrorResponse: %v", w.Body.String(), err)
+	}
+	if resp.Code != string(domainErrors.ErrCodeInternalError) {
+		t.Errorf("code = %q, want %q", resp.Code, domainErrors.ErrCodeInternalError)
+	}
+	if resp.Error == "" || resp.Message == "" {
+		t.Errorf("response = %+v, want error and message set", resp)
+	}
+	if got := resp.Context["trace_id"]; got != traceID.String() {
+		t.Errorf("context.trace_id = %v, want %s", got, traceID)
+	}
+}
+
+// captureLogs sends slog output to a JSON handler writing to the returned
+// buffer until the test ends. Read the buffer once requests are done.
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	logger := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(logger) })
+
+	var buf bytes.Buffer
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&lockedWriter{w: &buf}, nil)))
+	return &buf
+}
+
+// logRecords decodes the JSON records written to buf
+func logRecords(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var records []map[string]any
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		var record map[string]any
+		if err := dec.Decode(&record); err != nil {
+			t.Fatalf("decode log record: %v", err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestLoggingMiddlewareBodyBuffer(t *testing.T) {
+	buf := captureLogs(t)
+
+	var pooled, skipped bool
+	handler := LoggingMiddlewareWithConfig(true, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := w.(*responseRecorder)
+		if r.URL.Path == "/large" {
+			skipped = rec.body == nil
+		} else {
+			pooled = rec.body != nil
+		}
+		_, _ = io.WriteString(w, `{"id":1}`)
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/1", nil))
+	large := httptest.NewRequest(http.MethodPost, "/large", strings.NewReader(strings.Repeat("x", maxLoggedBodySize+1)))
+	handler.ServeHTTP(httptest.NewRecorder(), large)
+
+	if !pooled {
+		t.Error("response body was not buffered with body logging on")
+	}
+	if !skipped {
+		t.Error("a buffer was acquired for a request whose body is skipped")
+	}
+
+	var completed []map[string]any
+	for _, record := range logRecords(t, buf) {
+		if record["msg"] == "Request completed" {
+			completed = append(completed, record)
+		}
+	}
+	if len(completed) != 2 {
+		t.Fatalf("logged %d completions, want 2", len(completed))
+	}
+	if got := completed[0]["response_body"]; got != `{"id":1}` {
+		t.Errorf("response_body = %v, want the buffered body", got)
+	}
+	if _, ok := completed[1]["response_body"]; ok {
+		t.Errorf("skipped request logged a response body: %v", completed[1])
+	}
+}
+
+func TestLoggingMiddlewareBufferReuse(t *testing.T) {
+	buf := captureLogs(t)
+
+	// Each response body names its request; a buffer reused before the
+	// completion log would show another request's body
+	handler := LoggingMiddlewareWithConfig(true, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, strings.TrimPrefix(r.URL.Path, "/users/"))
+	}))
+
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n := strings.Repeat("x", i+1)
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/"+n, nil))
+		}()
+	}
+	wg.Wait()
+
+	for _, record := range logRecords(t, buf) {
+		if record["msg"] != "Request completed" {
+			continue
+		}
+		path, _ := record["path"].(string)
+		if want := strings.TrimPrefix(path, "/users/"); record["response_body"] != want {
+			t.Errorf("request %s logged response_body %v", path, record["response_body"])
+		}
+	}
+}
+
+// lockedWriter serializes writes from concurrent requests
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
+
+// responseBody is a typical small JSON response
+var responseBody = []byte(`{"id":42,"name":"Jane Doe","email":"jane@example.com","phone":"+14155550100","role":"user"}`)
+
+// bufferSink keeps benchmark buffers escaping to the heap, as they do when
+// stored on a responseRecorder
+var bufferSink *bytes.Buffer
+
+// BenchmarkResponseBuffer compares pooled response buffers with allocating a
+// new one per request
+func BenchmarkResponseBuffer(b *testing.B) {
+	b.Run("pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			buf := getBuffer()
+			buf.Write(responseBody)
+			bufferSink = buf
+			putBuffer(buf)
+		}
+	})
+	b.Run("unpooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			buf := new(bytes.Buffer)
+			buf.Write(responseBody)
+			bufferSink = buf
+		}
+	})
+}
+
+// setLogVerbosity sets the telemetry log verbosity until the test ends
+func setLogVerbosity(tb testing.TB, verbosity int) {
+	previous := telemetry.GetLogVerbosity()
+	tb.Cleanup(func() { telemetry.SetLogVerbosity(previous) })
+	telemetry.SetLogVerbosity(verbosity)
+}
+
+func TestLoggingMiddlewareModes(t *testing.T) {
+	newRequest := func() *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{"name":"Jane Doe"}`))
+		r.Header.Set("Content-Type", "application/json")
+		r.Header.Set("Connection", "keep-alive")
+		r.Header.Set("Authorization", "Bearer secret")
+		for i := range maxLoggedHeaders + 8 {
+			r.Header.Set("X-Extra-"+strconv.Itoa(i), "value")
+		}
+		return r
+	}
+	next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		_, _ = io.WriteString(w, `{"id":1}`)
+	})
+
+	t.Run("minimal", func(t *testing.T) {
+		buf := captureLogs(t)
+		setLogVerbosity(t, 2)
+		LoggingMiddlewareWithConfig(false, nil)(next).ServeHTTP(httptest.NewRecorder(), newRequest())
+
+		records := logRecords(t, buf)
+		if len(records) != 1 {
+			t.Fatalf("logged %d records, want only the completion", len(records))
+		}
+		for _, key := range []string{"method", "path", "status", "duration"} {
+			if _, ok := records[0][key]; !ok {
+				t.Errorf("completion record lacks %s: %v", key, records[0])
+			}
+		}
+		for _, key := range []string{"headers", "body", "response_body"} {
+			if _, ok := records[0][key]; ok {
+				t.Errorf("minimal record has %s: %v", key, records[0])
+			}
+		}
+	})
+
+	t.Run("bodies", func(t *testing.T) {
+		buf := captureLogs(t)
+		LoggingMiddlewareWithConfig(true, nil)(next).ServeHTTP(httptest.NewRecorder(), newRequest())
+
+		records := logRecords(t, buf)
+		if len(records) != 2 {
+			t.Fatalf("logged %d records, want the request and its completion", len(records))
+		}
+		headers, _ := records[0]["headers"].(map[string]any)
+		if len(headers) > maxLoggedHeaders {
+			t.Errorf("logged %d headers, want at most %d", len(headers), maxLoggedHeaders)
+		}
+		if _, ok := headers["Connection"]; ok {
+			t.Error("hop-by-hop header Connection was logged")
+		}
+		if got, ok := headers["Authorization"]; ok && got != redactedHeaderValue {
+			t.Errorf("Authorization logged as %v, want it redacted", got)
+		}
+		if got := records[0]["body"]; got != `{"name":"Jane Doe"}` {
+			t.Errorf("body = %v, want the request body", got)
+		}
+		if got := records[1]["response_body"]; got != `{"id":1}` {
+			t.Errorf("response_body = %v, want the response body", got)
+		}
+	})
+}
+
+// BenchmarkLoggingMiddleware compares the minimal summary logged without body
+// logging with full header and body logging
+func BenchmarkLoggingMiddleware(b *testing.B) {
+	logger := slog.Default()
+	b.Cleanup(func() { slog.SetDefault(logger) })
+	slog.SetDefault(slog.New(slog.NewJSONHandler(io.Discard, nil)))
+	setLogVerbosity(b, 2)
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(responseBody)
+	})
+	for _, logBodies := range []bool{false, true} {
+		name := "minimal"
+		if logBodies {
+			name = "bodies"
+		}
+		b.Run(name, func(b *testing.B) {
+			handler := LoggingMiddlewareWithConfig(logBodies, nil)(next)
+			b.ReportAllocs()
+			for b.Loop() {
+				r := httptest.NewRequest(http.MethodPost, "/users", bytes.NewReader(responseBody))
+				r.Header.Set("Content-Type", "application/json")
+				r.Header.Set("Authorization", "Bearer token")
+				handler.ServeHTTP(httptest.NewRecorder(), r)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"go-app/internal/application/dto"
+	"go-app/internal/infrastructure/redis"
+	"go-app/internal/infrastructure/telemetry"
+)
+
+// RateLimitMiddleware enforces a fixed-window request limit per client IP using
+// Redis INCR+EXPIRE. Requests are allowed through when Redis is unavailable.
+// Rejected requests get a 429 ErrorResponse with a Retry-After header.
+func RateLimitMiddleware(client *redis.Client, limit int, window time.Duration) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			span := trace.SpanFromContext(ctx)
+
+			now := time.Now()
+			windowStart := now.Truncate(window)
+			key := fmt.Sprintf("ratelimit:%s:%d", clientIP(r), windowStart.Unix())
+
+			count, err := client.IncrWithExpireWithTracing(ctx, key, window)
+			if err != nil {
+				telemetry.Log(ctx, telemetry.LevelWarn, "Rate limit check failed, allowing request", err,
+					attribute.String("ratelimit.key", key),
+					attribute.String("error", err.Error()),
+				)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if count > int64(limit) {
+				retryAfter := int(windowStart.Add(window).Sub(now).Seconds()) + 1
+				span.SetAttributes(
+					attribute.Bool("ratelimit.exceeded", true),
+					attribute.Int("ratelimit.limit", limit),
+				)
+				writeTooManyRequests(w, retryAfter)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// writeTooManyRequests writes a 429 ErrorResponse telling the client to retry
+// after retryAfter seconds
+func writeTooManyRequests(w http.ResponseWriter, retryAfter int) {
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusTooManyRequests)
+	_ = json.NewEncoder(w).Encode(dto.ErrorResponse{
+		Error:   "too many requests",
+		Code:    "RATE_LIMITED",
+		Message: "Rate limit exceeded, retry after " + strconv.Itoa(retryAfter) + "s",
+	})
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-app/internal/application/dto"
+)
+
+func TestTooManyRequestsBehindCORS(t *testing.T) {
+	// A limiter that rejects every request it sees, behind CORS as in the
+	// handler's middleware chain
+	var limited int
+	limiter := func(http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			limited++
+			writeTooManyRequests(w, 7)
+		})
+	}
+	cors := CORSMiddlewareWithConfig([]string{"https://app.example.com"}, []string{"GET"}, []string{"Content-Type"})
+	handler := ChainMiddleware(cors, limiter)(http.NotFoundHandler())
+
+	r := httptest.NewRequest(http.MethodGet, "/users", nil)
+	r.Header.Set("Origin", "https://app.example.com")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "https://app.example.com" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want the request origin", got)
+	}
+	if got := w.Header().Get("Retry-After"); got != "7" {
+		t.Errorf("Retry-After = %q, want 7", got)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+	var resp dto.ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("body %q is not an ErrorResponse: %v", w.Body.String(), err)
+	}
+	if resp.Code != "RATE_LIMITED" || resp.Message == "" {
+		t.Errorf("response = %+v, want code RATE_LIMITED with a message", resp)
+	}
+
+	// Preflight requests are answered before the limiter
+	preflight := httptest.NewRequest(http.MethodOptions, "/users", nil)
+	preflight.Header.Set("Origin", "https://app.example.com")
+	preflight.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, preflight)
+	if w.Code != http.StatusOK || limited != 1 {
+		t.Errorf("preflight status = %d after %d limited requests, want 200 without reaching the limiter", w.Code, limited)
+	}
+}
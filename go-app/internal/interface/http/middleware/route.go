@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// RouteMatcher resolves the route pattern a request is served by, as
+// *http.ServeMux does
+type RouteMatcher interface {
+	Handler(r *http.Request) (h http.Handler, pattern string)
+}
+
+// unmatchedRoute labels requests no registered pattern matched
+const unmatchedRoute = "unmatched"
+
+// otherMethod labels request methods outside the standard set, following the
+// HTTP semantic conventions
+const otherMethod = "_OTHER"
+
+// knownMethods are the request methods recorded as themselves
+var knownMethods = map[string]struct{}{
+	http.MethodGet: {}, http.MethodHead: {}, http.MethodPost: {}, http.MethodPut: {},
+	http.MethodPatch: {}, http.MethodDelete: {}, http.MethodConnect: {},
+	http.MethodOptions: {}, http.MethodTrace: {},
+}
+
+// isKnownMethod reports whether method is a standard HTTP method
+func isKnownMethod(method string) bool {
+	_, ok := knownMethods[method]
+	return ok
+}
+
+// matchedRoute returns the path of the pattern routes serves r with, such as
+// /users/{id}, without any method or host prefix. It is empty when no
+// pattern matches.
+func matchedRoute(routes RouteMatcher, r *http.Request) string {
+	_, pattern := routes.Handler(r)
+	if _, path, ok := strings.Cut(pattern, " "); ok {
+		pattern = path
+	}
+	if i := strings.IndexByte(pattern, '/'); i > 0 {
+		pattern = pattern[i:]
+	}
+	return pattern
+}
+
+// routeSpanName names server spans "{method} {route}", as the HTTP semantic
+// conventions recommend, falling back to operation for unmatched requests
+func routeSpanName(routes RouteMatcher) func(operation string, r *http.Request) string {
+	return func(operation string, r *http.Request) string {
+		route := matchedRoute(routes, r)
+		if route == "" {
+			return operation
+		}
+		method := r.Method
+		if !isKnownMethod(method) {
+			method = "HTTP"
+		}
+		return method + " " + route
+	}
+}
+
+// routeTag sets http.route to the matched pattern on the server span and on
+// otelhttp's request metrics. It must run inside the otelhttp handler.
+func routeTag(routes RouteMatcher, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := matchedRoute(routes, r)
+		if route == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		otelhttp.WithRouteTag(route, next).ServeHTTP(w, r)
+	})
+}
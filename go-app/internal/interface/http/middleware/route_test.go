@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+func TestOtelHttpMiddlewareSpanNames(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	// The patterns registered by routes.Router
+	mux := http.NewServeMux()
+	noContent := func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusNoContent) }
+	for _, pattern := range []string{
+		"/", "/health", "/livez", "/readyz", "/openapi.json", "/docs",
+		"/users", "DELETE /users", "/users/", "/users/search", "/users/bulk",
+		"/users/{id}", "/users/{id}/events", "/admin/config",
+	} {
+		mux.HandleFunc(pattern, noContent)
+	}
+	handler := OtelHttpMiddleware("http.server", mux)(mux)
+
+	tests := []struct {
+		method, target string
+		want           string
+		wantRoute      string
+	}{
+		{http.MethodGet, "/", "GET /", "/"},
+		{http.MethodGet, "/health", "GET /health", "/health"},
+		{http.MethodGet, "/livez", "GET /livez", "/livez"},
+		{http.MethodGet, "/readyz", "GET /readyz", "/readyz"},
+		{http.MethodGet, "/openapi.json", "GET /openapi.json", "/openapi.json"},
+		{http.MethodGet, "/docs", "GET /docs", "/docs"},
+		{http.MethodGet, "/users?limit=5", "GET /users", "/users"},
+		{http.MethodPost, "/users", "POST /users", "/users"},
+		{http.MethodDelete, "/users", "DELETE /users", "/users"},
+		{http.MethodGet, "/users/search?q=jane", "GET /users/search", "/users/search"},
+		{http.MethodPost, "/users/bulk", "POST /users/bulk", "/users/bulk"},
+		{http.MethodGet, "/users/42", "GET /users/{id}", "/users/{id}"},
+		{http.MethodPatch, "/users/42", "PATCH /users/{id}", "/users/{id}"},
+		{http.MethodGet, "/users/42/events", "GET /users/{id}/events", "/users/{id}/events"},
+		{http.MethodGet, "/users/42/other", "GET /users/", "/users/"},
+		{http.MethodGet, "/admin/config", "GET /admin/config", "/admin/config"},
+		{"PURGE", "/users/42", "HTTP /users/{id}", "/users/{id}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.target, func(t *testing.T) {
+			before := len(recorder.Ended())
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(tt.method, tt.target, nil))
+
+			spans := recorder.Ended()[before:]
+			if len(spans) != 1 {
+				t.Fatalf("ended %d spans, want 1", len(spans))
+			}
+			if got := spans[0].Name(); got != tt.want {
+				t.Errorf("span name = %q, want %q", got, tt.want)
+			}
+			var route attribute.Value
+			for _, attr := range spans[0].Attributes() {
+				if attr.Key == semconv.HTTPRouteKey {
+					route = attr.Value
+				}
+			}
+			if got := route.AsString(); got != tt.wantRoute {
+				t.Errorf("http.route = %q, want %q", got, tt.wantRoute)
+			}
+		})
+	}
+}
+
+func TestOtelHttpMiddlewareWithoutRoutes(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	handler := OtelHttpMiddleware("http.server", nil)(http.NotFoundHandler())
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/42", nil))
+
+	if spans := recorder.Ended(); len(spans) != 1 || spans[0].Name() != "http.server" {
+		t.Errorf("spans = %v, want one named http.server", spans)
+	}
+}
@@ -2,15 +2,23 @@ package routes
 
 import (
 	"net/http"
+	"net/http/pprof"
 
 	"go-app/internal/application/service"
+	"go-app/internal/domain/entity"
+	"go-app/internal/infrastructure/config"
 	"go-app/internal/interface/http/handler"
+	"go-app/internal/interface/http/middleware"
 )
 
 // Router holds the router dependencies
 type Router struct {
 	userService *service.UserService
 	appService  *service.AppService
+	auth        middleware.Middleware
+	health      *service.HealthService
+	idempotency middleware.Middleware
+	config      *config.Config
 }
 
 // NewRouter creates a new router
@@ -21,17 +29,91 @@ func NewRouter(userService *service.UserService, appService *service.AppService)
 	}
 }
 
+// WithAuth requires authentication on protected routes
+func (r *Router) WithAuth(auth middleware.Middleware) *Router {
+	r.auth = auth
+	return r
+}
+
+// WithHealth sets the health service whose cached results /readyz reports
+func (r *Router) WithHealth(health *service.HealthService) *Router {
+	r.health = health
+	return r
+}
+
+// WithIdempotency enables Idempotency-Key handling for user creation
+func (r *Router) WithIdempotency(idempotency middleware.Middleware) *Router {
+	r.idempotency = idempotency
+	return r
+}
+
+// WithConfig exposes the effective configuration, masked, to admins at /admin/config
+func (r *Router) WithConfig(cfg config.Config) *Router {
+	r.config = &cfg
+	return r
+}
+
+// protected wraps a handler with the auth middleware, if configured
+func (r *Router) protected(h http.HandlerFunc) http.Handler {
+	if r.auth == nil {
+		return h
+	}
+	return r.auth(h)
+}
+
+// admin wraps a handler with the auth middleware and requires the admin role.
+// Without authentication there is no principal to check, so every request
+// is rejected with 403.
+func (r *Router) admin(h http.HandlerFunc) http.Handler {
+	return r.protected(middleware.RequireRole(entity.RoleAdmin)(h).ServeHTTP)
+}
+
+// idempotent wraps a handler with the idempotency middleware, if configured
+func (r *Router) idempotent(h http.HandlerFunc) http.HandlerFunc {
+	if r.idempotency == nil {
+		return h
+	}
+	return r.idempotency(h).ServeHTTP
+}
+
 // RegisterRoutes registers all routes
 func (r *Router) RegisterRoutes(mux *http.ServeMux) {
 	// Create handlers
 	rootHandler := handler.NewRootHandler(r.appService)
 	usersHandler := handler.NewUsersHandler(r.userService)
 	healthHandler := handler.NewHealthHandler()
+	readinessHandler := handler.NewReadinessHandler(r.health)
+	docsHandler := handler.NewDocsHandler()
 
 	// Register routes
 	mux.HandleFunc("/", rootHandler.Handle)
 	mux.HandleFunc("/health", healthHandler.Handle)
-	mux.HandleFunc("/users", usersHandler.Handle)
-	mux.HandleFunc("/users/", usersHandler.Handle)
-	mux.HandleFunc("/users/{id}", usersHandler.Handle)
+	mux.HandleFunc("/livez", readinessHandler.Livez)
+	mux.HandleFunc("/readyz", readinessHandler.Readyz)
+	mux.HandleFunc("/openapi.json", docsHandler.Spec)
+	mux.HandleFunc("/docs", docsHandler.UI)
+	mux.Handle("/users", r.protected(r.idempotent(usersHandler.Handle)))
+	mux.Handle("DELETE /users", r.admin(usersHandler.DeleteUsers))
+	mux.Handle("/users/", r.protected(usersHandler.Handle))
+	mux.Handle("/users/search", r.protected(usersHandler.Search))
+	mux.Handle("/users/bulk", r.protected(usersHandler.BulkCreate))
+	mux.Handle("/users/{id}", r.protected(usersHandler.Handle))
+	mux.Handle("/users/{id}/events", r.protected(usersHandler.Events))
+
+	// Even masked, the config reveals hosts and topology, so it is only served
+	// to admins when authentication is enabled
+	if r.config != nil && r.auth != nil {
+		mux.Handle("/admin/config", r.admin(handler.NewConfigHandler(*r.config).Handle))
+	}
+}
+
+// RegisterPprofRoutes registers the net/http/pprof handlers under /debug/pprof/.
+// They are unauthenticated, so mount them only on the admin mux, never on the
+// public one.
+func RegisterPprofRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
 }
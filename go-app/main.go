@@ -13,11 +13,17 @@ import (
 
 	"go-app/internal/application/service"
 	"go-app/internal/application/worker"
+	domainErrors "go-app/internal/domain/errors"
+	"go-app/internal/domain/pagination"
 	"go-app/internal/infrastructure/config"
 	"go-app/internal/infrastructure/kafka"
+	"go-app/internal/infrastructure/lifecycle"
 	"go-app/internal/infrastructure/postgres"
 	"go-app/internal/infrastructure/redis"
+	cacherepo "go-app/internal/infrastructure/repository/cache"
 	postgresrepo "go-app/internal/infrastructure/repository/postgres"
+	redisrepo "go-app/internal/infrastructure/repository/redis"
+	"go-app/internal/infrastructure/retry"
 	"go-app/internal/infrastructure/telemetry"
 	h "go-app/internal/interface/http"
 )
@@ -28,75 +34,159 @@ func main() {
 	defer stop()
 
 	// Load configuration
-	cfg := config.LoadConfig()
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Invalid configuration:\n%v", err)
+	}
 
 	// Initialize telemetry
 	tel, shutdown, err := telemetry.Setup(ctx, cfg)
 	if err != nil {
 		log.Fatalf("Failed to initialize telemetry: %v", err)
 	}
-	
+
 	// Set log verbosity from config
 	telemetry.SetLogVerbosity(cfg.Otel.LogVerbosity)
-	defer func() {
-		// Create a separate context for shutdown with a timeout
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	shutdownTimeout := time.Duration(cfg.Otel.ShutdownTimeoutSecs) * time.Second
+
+	// Components are stopped in dependency order on shutdown. Every component
+	// depends on telemetry, so the providers flush and close strictly last and
+	// spans and logs emitted while closing the others are still exported.
+	components := lifecycle.NewManager()
+	components.Register("telemetry", func(ctx context.Context) error {
+		// A separate deadline, so a slow drain of the other components cannot
+		// leave none for the final export
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shutdownTimeout)
 		defer cancel()
+		return shutdown(ctx)
+	})
 
-		if err := shutdown(shutdownCtx); err != nil {
-			telemetry.Log(context.Background(), telemetry.LevelError, "Error during telemetry shutdown", err)
-		}
-	}()
+	// Apply log verbosity and sampling changes from the config file without a restart
+	config.Watch(cfg, func(cfg config.Config) {
+		telemetry.Reload(cfg.Otel)
+	})
+
+	// Keep sensitive context keys out of serialized errors
+	domainErrors.SetSensitiveContextKeys(cfg.Errors.ContextDenyKeys...)
+
+	// Apply the configured list page sizes
+	pagination.SetLimits(cfg.HTTP.DefaultPageSize, cfg.HTTP.MaxPageSize)
 
 	// Create postgres client
 	pgDB, err := postgres.NewClient(ctx, cfg.Postgres, tel)
 	if err != nil {
 		log.Fatalf("Failed to initialize postgres: %v", err)
 	}
-	defer func() {
-		if err := pgDB.Close(); err != nil {
-			telemetry.Log(context.Background(), telemetry.LevelError, "Error during postgres shutdown", err)
+	components.Register("postgres", func(context.Context) error { return pgDB.Close() }, "telemetry")
+
+	// Apply schema migrations when enabled
+	if cfg.Postgres.AutoMigrate {
+		if err := pgDB.Migrate(ctx); err != nil {
+			log.Fatalf("Failed to migrate postgres: %v", err)
 		}
-	}()
+	}
 
 	// Create redis client
 	rdb, err := redis.NewClient(ctx, cfg.Redis, tel)
 	if err != nil {
 		log.Fatalf("Failed to initialize redis: %v", err)
 	}
-	defer func() {
-		if err := rdb.Close(); err != nil {
-			telemetry.Log(context.Background(), telemetry.LevelError, "Error during redis shutdown", err)
-		}
-	}()
+	components.Register("redis", func(context.Context) error { return rdb.Close() }, "telemetry")
+	if cfg.Redis.MonitorInterval > 0 {
+		rdb.StartMonitor(ctx, time.Duration(cfg.Redis.MonitorInterval)*time.Second)
+	}
 
 	// Create kafka producer
 	kproducer, err := kafka.NewProducer(cfg.Kafka, tel)
 	if err != nil {
 		log.Fatalf("Failed to initialize kafka producer: %v", err)
 	}
-	defer kproducer.Close()
+	components.Register("kafka.producer", func(context.Context) error {
+		kproducer.Close()
+		return nil
+	}, "telemetry")
 
 	// Create kafka consumer
 	kconsumer, err := kafka.NewConsumer(cfg.Kafka, cfg.Kafka.ConsumerGroup, tel)
 	if err != nil {
 		log.Fatalf("Failed to initialize kafka consumer: %v", err)
 	}
-	defer kconsumer.Close()
 
-	// Create and start Kafka worker
-	kafkaWorker := worker.NewKafkaWorker(kconsumer, tel)
+	// Create and start Kafka worker; it owns the consumer and closes it in Stop
+	kafkaWorker := worker.NewKafkaWorker(kconsumer, kproducer, cfg.Kafka, tel)
 	kafkaWorker.Start(ctx)
+	// Draining the worker lets its processing spans be flushed
+	components.Register("kafka.worker", kafkaWorker.Stop, "kafka.producer", "telemetry.flush")
 
 	// Create repositories
-	userRepo := postgresrepo.NewPostgresUserRepository(pgDB.DB)
+	userRepo := postgresrepo.NewPostgresUserRepository(pgDB.DB, cfg.Postgres.SoftDelete, retry.Policy{
+		MaxAttempts: cfg.Postgres.RetryMaxAttempts,
+		BaseDelay:   time.Duration(cfg.Postgres.RetryBaseDelayMs) * time.Millisecond,
+		MaxDelay:    time.Duration(cfg.Postgres.RetryMaxDelayMs) * time.Millisecond,
+		Jitter:      cfg.Postgres.RetryJitter,
+	})
+	if cfg.Redis.CacheEnabled {
+		userRepo = cacherepo.NewUserRepository(userRepo, rdb, time.Duration(cfg.Redis.CacheTTL)*time.Second)
+	}
 
 	// Create services
-	userService := service.NewUserService(userRepo, tel)
+	txManager := postgres.NewTxManager(pgDB)
+	userService := service.NewUserService(userRepo, tel).
+		WithTxManager(txManager).
+		WithListSingleflight(cfg.Postgres.ListSingleflight).
+		WithEventPublisher(kafka.NewEventPublisher(kproducer, cfg.Kafka.Topic))
+
+	// Store events in the outbox within the creating transaction and relay them
+	// to Kafka in the background, so a crash after commit cannot lose them
+	if cfg.Kafka.OutboxEnabled {
+		outbox := postgresrepo.NewOutboxRepository(pgDB.DB)
+		userService.WithOutbox(outbox)
+		outboxRelay := worker.NewOutboxRelay(outbox, txManager, kproducer, cfg.Kafka, tel)
+		outboxRelay.Start(ctx)
+		components.Register("outbox.relay", outboxRelay.Stop, "postgres", "kafka.producer", "telemetry.flush")
+	}
+	if cfg.Redis.UserEventsEnabled {
+		userService.WithActivity(redisrepo.NewUserActivityRepository(rdb, cfg.Redis.UserEventsMaxLen))
+	}
 	appService := service.NewAppService(tel)
 
+	// Check dependencies in the background so /readyz serves cached results
+	healthService := service.NewHealthService(map[string]service.HealthChecker{
+		"postgres": pgDB,
+		"redis":    rdb,
+		"kafka":    kproducer,
+	}, tel,
+		time.Duration(cfg.HTTP.HealthCheckIntervalMs)*time.Millisecond,
+		time.Duration(cfg.HTTP.HealthCheckTimeoutMs)*time.Millisecond,
+	)
+	healthService.Start(ctx)
+
 	// Create HTTP handler
-	handler := h.NewHandler(userService, appService, tel, cfg.Otel)
+	handler, err := h.NewHandler(userService, appService, tel, cfg.Otel).
+		WithTrustedProxies(cfg.HTTP.TrustedProxies).
+		WithConfig(cfg).
+		WithRateLimit(rdb, cfg.RateLimit).
+		WithCORS(cfg.CORS).
+		WithIdempotency(rdb, time.Duration(cfg.Redis.IdempotencyTTL)*time.Second).
+		WithHealth(healthService).
+		WithAuth(cfg.Auth)
+	if err != nil {
+		log.Fatalf("Failed to configure authentication: %v", err)
+	}
+	if cfg.HTTP.PprofEnabled {
+		handler.WithPprof(cfg.HTTP.PprofAddr)
+	}
+	// Stopping the server lets in-flight requests finish within the grace period
+	components.Register("http.server", handler.Stop, "postgres", "redis", "kafka.producer", "telemetry.flush")
+
+	// Flush buffered telemetry once the server and workers have stopped, but
+	// before the clients close, so the final metric collection can still
+	// observe gauges backed by postgres. The telemetry shutdown then has little
+	// left to export.
+	components.Register("telemetry.flush", tel.ForceFlush, "postgres", "redis", "kafka.producer", "telemetry")
 
 	// Start server in a goroutine
 	serverCtx, serverCancel := context.WithCancel(ctx)
@@ -123,10 +213,10 @@ func main() {
 
 	fmt.Println("\nShutting down application gracefully...")
 	telemetry.Log(serverCtx, telemetry.LevelInfo, "Shutting down application gracefully", nil)
-	// Shutdown HTTP server
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
 	defer cancel()
-	if err := handler.Stop(shutdownCtx); err != nil {
-		telemetry.Log(shutdownCtx, telemetry.LevelError, "Error during server shutdown", err)
+	if err := components.Shutdown(shutdownCtx); err != nil {
+		// Telemetry is closed by now, so report to stderr
+		log.Printf("Shutdown completed with errors: %v", err)
 	}
 }
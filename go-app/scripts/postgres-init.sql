@@ -4,14 +4,30 @@
 CREATE TABLE IF NOT EXISTS users (
     id SERIAL PRIMARY KEY,
     name VARCHAR(100) NOT NULL,
-    email VARCHAR(100) NOT NULL UNIQUE,
+    email VARCHAR(100) NOT NULL,
+    phone VARCHAR(16),
+    role VARCHAR(20) NOT NULL DEFAULT 'user' CHECK (role IN ('admin', 'user')),
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
 );
 
+-- Soft delete marker for databases created before it was introduced
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+
+-- Optional E.164 phone number for databases created before it was introduced
+ALTER TABLE users ADD COLUMN IF NOT EXISTS phone VARCHAR(16);
+
+-- Authorization role for databases created before it was introduced
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'user';
+
 -- Add indexes for better performance
-CREATE INDEX IF NOT EXISTS idx_users_email ON users(email);
+-- Emails are unique case-insensitively among users that are not soft-deleted;
+-- lookups compare lower(email)
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_lower ON users(lower(email)) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_users_email ON users(lower(email));
 CREATE INDEX IF NOT EXISTS idx_users_created_at ON users(created_at);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users(deleted_at);
 
 -- Add a trigger to automatically update the updated_at column
 CREATE OR REPLACE FUNCTION update_updated_at_column()
@@ -27,12 +43,27 @@ CREATE TRIGGER update_users_updated_at
     FOR EACH ROW 
     EXECUTE FUNCTION update_updated_at_column();
 
+-- Transactional outbox of events awaiting relay to Kafka
+CREATE TABLE IF NOT EXISTS outbox (
+    id BIGSERIAL PRIMARY KEY,
+    event_type VARCHAR(100) NOT NULL,
+    event_key VARCHAR(255) NOT NULL,
+    payload JSONB NOT NULL,
+    trace_context JSONB NOT NULL DEFAULT '{}',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    published_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_unpublished ON outbox(id) WHERE published_at IS NULL;
+
 -- Insert some sample data for testing
 INSERT INTO users (name, email) VALUES 
     ('John Doe', 'john.doe@example.com'),
     ('Jane Smith', 'jane.smith@example.com'),
     ('Bob Johnson', 'bob.johnson@example.com')
-ON CONFLICT (email) DO NOTHING;
+ON CONFLICT ((lower(email))) WHERE deleted_at IS NULL DO NOTHING;
 
 -- Create additional tables for more complex examples (optional)
 
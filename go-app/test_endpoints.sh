@@ -26,3 +26,6 @@ curl -i -X PUT "$BASE_URL/users/1" \
 
 echo -e "\n\n== Delete user =="
 curl -i -X DELETE "$BASE_URL/users/1"
+
+echo -e "\n\n== Get user events =="
+curl -i "$BASE_URL/users/1/events"